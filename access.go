@@ -0,0 +1,154 @@
+package syncer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Access is a requested permission together with the grants that satisfy it
+type Access struct {
+	Requested Requested `json:"requested"`
+	Granted   []Granted `json:"granted"`
+}
+
+// AccessReport lists who can access one container target: every grant on it
+// that is active and every requested permission these grants satisfy
+type AccessReport struct {
+	ContainerName string    `json:"container_name"`
+	Target        string    `json:"target"`
+	Time          time.Time `json:"time"`
+	Granted       []Granted `json:"granted"`
+	Access        []Access  `json:"access"`
+}
+
+// AccessReport builds the access matrix of a container target with the same
+// rules as FindRequested. Use AsOf to build the report of a point in time.
+func (dm *DbManager) AccessReport(containerName, target string, opts ...QueryOption) (AccessReport, error) {
+	now, grantedFrom, err := dm.resolveQuery("granted", opts)
+	if err != nil {
+		return AccessReport{}, err
+	}
+	_, requestedFrom, err := dm.resolveQuery("requested", opts)
+	if err != nil {
+		return AccessReport{}, err
+	}
+
+	containerName = normalizeContainerName(containerName)
+	report := AccessReport{ContainerName: containerName, Target: target, Time: now, Granted: []Granted{}, Access: []Access{}}
+
+	args := append(append([]any{}, grantedFrom.args...), containerName, target)
+	rows, err := dm.db.Query(fmt.Sprintf(`SELECT %s FROM %s WHERE container_name = ? AND target = ? ORDER BY locator`,
+		grantedColumns, grantedFrom.from), args...)
+	if err != nil {
+		return AccessReport{}, fmt.Errorf("failed to query granted records: %w", err)
+	}
+	granted, err := scanGranted(rows)
+	if err != nil {
+		return AccessReport{}, err
+	}
+	report.Granted = append(report.Granted, activeGranted(granted, now)...)
+
+	index := map[string]int{}
+	for _, g := range report.Granted {
+		requested, err := findRequestedIn(dm.db, requestedFrom, now, []Granted{g})
+		if err != nil {
+			return AccessReport{}, err
+		}
+		for _, r := range requested {
+			locator := requestedLocator(r)
+			i, ok := index[locator]
+			if !ok {
+				i = len(report.Access)
+				index[locator] = i
+				report.Access = append(report.Access, Access{Requested: r})
+			}
+			report.Access[i].Granted = append(report.Access[i].Granted, g)
+		}
+	}
+	sort.SliceStable(report.Access, func(i, j int) bool {
+		return requestedLocator(report.Access[i].Requested) < requestedLocator(report.Access[j].Requested)
+	})
+
+	return report, nil
+}
+
+// accessColumns are the columns of the CSV export of an AccessReport
+var accessColumns = []string{
+	"container_name", "target",
+	"requested_host", "requested_scheme", "requested_action",
+	"requested_source_organization", "requested_source_repository",
+	"requested_umbrella_organization", "requested_umbrella_repository",
+	"destination_path", "requested_description",
+	"granted_host", "granted_scheme", "granted_action",
+	"granted_source_organization", "granted_source_repository",
+	"granted_umbrella_organization", "granted_umbrella_repository",
+	"expose_path", "not_before", "expires_at", "granted_description",
+}
+
+// WriteCSV writes a row for every requested permission and grant that
+// satisfies it. Grants that satisfy no requested permission get a row with
+// empty requested columns.
+func (r AccessReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(accessColumns); err != nil {
+		return fmt.Errorf("failed to write access report: %w", err)
+	}
+
+	used := map[string]bool{}
+	for _, access := range r.Access {
+		for _, g := range access.Granted {
+			used[grantedLocator(g)] = true
+			if err := writer.Write(r.csvRow(&access.Requested, g)); err != nil {
+				return fmt.Errorf("failed to write access report: %w", err)
+			}
+		}
+	}
+	for _, g := range r.Granted {
+		if used[grantedLocator(g)] {
+			continue
+		}
+		if err := writer.Write(r.csvRow(nil, g)); err != nil {
+			return fmt.Errorf("failed to write access report: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write access report: %w", err)
+	}
+
+	return nil
+}
+
+func (r AccessReport) csvRow(req *Requested, g Granted) []string {
+	row := []string{r.ContainerName, r.Target}
+	if req != nil {
+		row = append(row,
+			req.Host, req.RequestScheme, req.RequestAction,
+			req.RequestSourceOrganization, req.RequestSourceRepository,
+			req.RequestUmbrellaOrganization, req.RequestUmbrellaRepository,
+			req.DestinationPath, req.Description,
+		)
+	} else {
+		row = append(row, make([]string, 9)...)
+	}
+
+	return append(row,
+		g.Host, g.GrandScheme, g.GrandAction,
+		g.GrandSourceOrganization, g.GrandSourceRepository,
+		g.GrandUmbrellaOrganization, g.GrandUmbrellaRepository,
+		g.ExposePath, csvTime(g.NotBefore), csvTime(g.ExpiresAt), g.Description,
+	)
+}
+
+// csvTime formats a time as RFC 3339, the zero time as an empty cell
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,116 @@
+package syncer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+)
+
+func mockAccess(dbManager *DbManager, now time.Time) (image, anyScheme, unused Granted, pull, hive Requested) {
+	image = Granted{ContainerName: "image/container", Target: "cmd", GrandScheme: "image", GrandAction: "*"}
+	anyScheme = Granted{Host: "other", ContainerName: "image/container", Target: "cmd", GrandScheme: "*", GrandAction: "pull", ExposePath: "/exports"}
+	unused = Granted{ContainerName: "image/container", Target: "cmd", GrandScheme: "video", GrandAction: "*", ExpiresAt: now.Add(time.Hour)}
+	expired := Granted{ContainerName: "image/container", Target: "cmd", GrandScheme: "hive", GrandAction: "*", ExpiresAt: now.Add(-time.Hour)}
+	otherTarget := Granted{ContainerName: "image/container", Target: "web", GrandScheme: "*", GrandAction: "*"}
+	for _, g := range []Granted{image, anyScheme, unused, expired, otherTarget} {
+		mockGranted(dbManager, g)
+	}
+
+	pull = Requested{Host: "a", ContainerName: "image/container", Target: "cmd", RequestScheme: "image", RequestAction: "pull", DestinationPath: "/exports/images"}
+	hive = Requested{Host: "b", ContainerName: "image/container", Target: "cmd", RequestScheme: "hive", RequestAction: "pull"}
+	otherContainer := Requested{Host: "c", ContainerName: "image/other", Target: "cmd", RequestScheme: "image", RequestAction: "pull"}
+	for _, r := range []Requested{pull, hive, otherContainer} {
+		mockRequested(dbManager, r)
+	}
+
+	return image, anyScheme, unused, pull, hive
+}
+
+func TestAccessReport(t *testing.T) {
+	// Given
+	is, dbManager, clock := setupTestDBWithClock(t)
+	image, anyScheme, unused, pull, hive := mockAccess(dbManager, clock.now)
+
+	// When
+	report, err := dbManager.AccessReport("/image/container/", "cmd")
+
+	// Then
+	is.NoErr(err)
+	is.Equal(report.ContainerName, "image/container")
+	is.Equal(report.Time, clock.now)
+	is.Equal(len(report.Granted), 3) // The expired grant and the grant on another target are left out
+	for _, g := range report.Granted {
+		is.True(g == image || g == anyScheme || g == unused)
+	}
+
+	is.Equal(len(report.Access), 2)
+	for _, access := range report.Access {
+		switch access.Requested {
+		case pull:
+			is.Equal(len(access.Granted), 2) // Satisfied by the image and the pull grant
+		case hive:
+			is.Equal(access.Granted, []Granted{anyScheme})
+		default:
+			t.Fatalf("unexpected access of %+v", access.Requested)
+		}
+	}
+}
+
+func TestAccessReport_unknown_resource(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+
+	// When
+	report, err := dbManager.AccessReport("image/unknown", "cmd")
+
+	// Then
+	is.NoErr(err)
+	is.Equal(report.Granted, []Granted{})
+	is.Equal(report.Access, []Access{})
+}
+
+func TestAccessReport_as_of(t *testing.T) {
+	// Given
+	is, dbManager, clock := setupTestDBWithClock(t)
+	before := clock.now
+	clock.Advance(time.Minute)
+	mockAccess(dbManager, clock.now)
+
+	// When
+	report, err := dbManager.AccessReport("image/container", "cmd", AsOf(before))
+
+	// Then
+	is.NoErr(err)
+	is.Equal(report.Time, before)
+	is.Equal(len(report.Granted), 0)
+	is.Equal(len(report.Access), 0)
+}
+
+func TestAccessReport_WriteCSV(t *testing.T) {
+	// Given
+	is, dbManager, clock := setupTestDBWithClock(t)
+	mockAccess(dbManager, clock.now)
+	report, err := dbManager.AccessReport("image/container", "cmd")
+	is.NoErr(err)
+	var buf bytes.Buffer
+
+	// When
+	err = report.WriteCSV(&buf)
+
+	// Then
+	is.NoErr(err)
+	records, err := csv.NewReader(&buf).ReadAll()
+	is.NoErr(err)
+	is.Equal(records[0], accessColumns)
+	is.Equal(len(records), 5) // The header, three pairs of a request and a grant and the unused grant
+	for _, record := range records[1:] {
+		is.Equal(len(record), len(accessColumns))
+		is.Equal(record[0], "image/container")
+		is.Equal(record[1], "cmd")
+	}
+	last := records[4]
+	is.Equal(last[2], "")       // No requested permission
+	is.Equal(last[12], "video") // The scheme of the unused grant
+	is.Equal(last[20], clock.now.Add(time.Hour).Format(time.RFC3339))
+}
@@ -0,0 +1,304 @@
+package syncer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrActionCycle is returned when an implication would make an action imply itself
+var ErrActionCycle = errors.New("implication would make the action imply itself")
+
+// ActionImplication states that a grant of Action also grants Implies to
+// requests of Scheme, e.g. push implies pull for the image scheme
+type ActionImplication struct {
+	Scheme  string `json:"scheme"`
+	Action  string `json:"action"`
+	Implies string `json:"implies"`
+}
+
+// AddImpliedAction registers that action implies another action within a
+// scheme. Implications are transitive, so admin > push > pull takes two
+// calls. Schemes differ in their verbs, so every scheme has its own
+// hierarchy; wildcards are not accepted.
+func (dm *DbManager) AddImpliedAction(scheme, action, implied string) error {
+	for _, value := range []string{scheme, action, implied} {
+		if value == "" || value == "*" {
+			return fmt.Errorf("failed to add implied action: scheme, action and implied action are required and cannot be a wildcard, got %q", value)
+		}
+	}
+
+	tx, err := dm.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	actions, err := loadActionLattice(tx)
+	if err != nil {
+		return err
+	}
+	if action == implied || actions.implies(scheme, implied, action) {
+		return fmt.Errorf("failed to add %s > %s for scheme %s: %w", action, implied, scheme, ErrActionCycle)
+	}
+
+	result, err := tx.Exec(`INSERT OR IGNORE INTO action_implications (`+actionImplicationColumns+`) VALUES (?, ?, ?)`, scheme, action, implied)
+	if err != nil {
+		return fmt.Errorf("failed to save implied action: %w", err)
+	}
+	added, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to save implied action: %w", err)
+	}
+	if added == 0 {
+		// Adding an implication that is already stored does nothing
+		return nil
+	}
+	if err := dm.recordImpliedAction(tx, scheme, action, implied); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveImpliedAction removes an implication added with AddImpliedAction
+func (dm *DbManager) RemoveImpliedAction(scheme, action, implied string) error {
+	tx, err := dm.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM action_implications WHERE scheme = ? AND action = ? AND implied = ?`, scheme, action, implied)
+	if err != nil {
+		return fmt.Errorf("failed to delete implied action: %w", err)
+	}
+	if err := dm.recordImpliedAction(tx, scheme, action, implied); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// recordImpliedAction records the change of an implication in its history
+func (dm *DbManager) recordImpliedAction(q querier, scheme, action, implied string) error {
+	return dm.recordVersion(q, "action_implications", actionImplicationColumns,
+		"scheme = ? AND action = ? AND implied = ?", scheme, action, implied)
+}
+
+// ListImpliedActions returns the registered implications ordered by scheme, action and implied action
+func (dm *DbManager) ListImpliedActions() ([]ActionImplication, error) {
+	actions, err := loadActionLattice(dm.db)
+	if err != nil {
+		return nil, err
+	}
+
+	implications := []ActionImplication{}
+	for _, scheme := range actions.schemes() {
+		for action, implied := range actions[scheme] {
+			for _, i := range implied {
+				implications = append(implications, ActionImplication{Scheme: scheme, Action: action, Implies: i})
+			}
+		}
+	}
+	sort.Slice(implications, func(i, j int) bool {
+		a, b := implications[i], implications[j]
+		if a.Scheme != b.Scheme {
+			return a.Scheme < b.Scheme
+		}
+		if a.Action != b.Action {
+			return a.Action < b.Action
+		}
+		return a.Implies < b.Implies
+	})
+
+	return implications, nil
+}
+
+// ImpliedActions returns the actions that a grant of action also grants
+// within the scheme, directly or through other actions, ordered by name
+func (dm *DbManager) ImpliedActions(scheme, action string) ([]string, error) {
+	actions, err := loadActionLattice(dm.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return actions.implied(scheme, action), nil
+}
+
+// actionLattice maps a scheme to its actions and the actions they directly imply
+type actionLattice map[string]map[string][]string
+
+// actionImplicationColumns are the columns of action_implications and its history
+const actionImplicationColumns = "scheme, action, implied"
+
+func loadActionLattice(q querier) (actionLattice, error) {
+	return loadActionLatticeIn(q, currentSnapshot("action_implications"))
+}
+
+func loadActionLatticeIn(q querier, from snapshot) (actionLattice, error) {
+	rows, err := q.Query(fmt.Sprintf(`SELECT %s FROM %s`, actionImplicationColumns, from.from), from.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query implied actions: %w", err)
+	}
+	defer rows.Close()
+
+	actions := actionLattice{}
+	for rows.Next() {
+		var scheme, action, implied string
+		if err := rows.Scan(&scheme, &action, &implied); err != nil {
+			return nil, fmt.Errorf("failed to scan implied action: %w", err)
+		}
+		if actions[scheme] == nil {
+			actions[scheme] = map[string][]string{}
+		}
+		actions[scheme][action] = append(actions[scheme][action], implied)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read implied actions: %w", err)
+	}
+
+	return actions, nil
+}
+
+// schemes returns the schemes with implications, ordered by name
+func (l actionLattice) schemes() []string {
+	schemes := make([]string, 0, len(l))
+	for scheme := range l {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+
+	return schemes
+}
+
+// implied returns the actions that action implies within the scheme, ordered by name
+func (l actionLattice) implied(scheme, action string) []string {
+	seen := map[string]bool{}
+	pending := []string{action}
+	for len(pending) > 0 {
+		next := pending[0]
+		pending = pending[1:]
+		for _, i := range l[scheme][next] {
+			if !seen[i] {
+				seen[i] = true
+				pending = append(pending, i)
+			}
+		}
+	}
+
+	return sortedKeys(seen)
+}
+
+// implying returns the actions that imply action within the scheme, ordered by name
+func (l actionLattice) implying(scheme, action string) []string {
+	var implying []string
+	for a := range l[scheme] {
+		if l.implies(scheme, a, action) {
+			implying = append(implying, a)
+		}
+	}
+	sort.Strings(implying)
+
+	return implying
+}
+
+// implies reports whether a grant of granted also grants requested within the scheme
+func (l actionLattice) implies(scheme, granted, requested string) bool {
+	for _, i := range l.implied(scheme, granted) {
+		if i == requested {
+			return true
+		}
+	}
+
+	return false
+}
+
+// grantedActionCondition is the condition on the action of the grants that
+// satisfy a requested action: the same action, a wildcard or an action that
+// implies it. The hierarchy is the one of the requested scheme, or the one
+// of the granted scheme when the request has a wildcard scheme.
+func (l actionLattice) grantedActionCondition(scheme, action string) (string, []any) {
+	if action == "*" {
+		return "1=1", nil
+	}
+
+	conditions := []string{"grand_action = ?", "grand_action = '*'"}
+	args := []any{action}
+	for _, s := range l.schemes() {
+		if scheme != "*" && s != scheme {
+			continue
+		}
+		implying := l.implying(s, action)
+		if len(implying) == 0 {
+			continue
+		}
+		if scheme == "*" {
+			conditions = append(conditions, fmt.Sprintf("(grand_scheme = ? AND grand_action IN (%s))", placeholders(len(implying))))
+			args = append(args, s)
+		} else {
+			conditions = append(conditions, fmt.Sprintf("grand_action IN (%s)", placeholders(len(implying))))
+		}
+		for _, a := range implying {
+			args = append(args, a)
+		}
+	}
+
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// requestedActionCondition is the inverse of grantedActionCondition, the
+// condition on the action of the requests that a granted action satisfies
+func (l actionLattice) requestedActionCondition(scheme, action string) (string, []any) {
+	if action == "*" {
+		return "1=1", nil
+	}
+
+	conditions := []string{"request_action = ?", "request_action = '*'"}
+	args := []any{action}
+	for _, s := range l.schemes() {
+		if scheme != "*" && s != scheme {
+			continue
+		}
+		implied := l.implied(s, action)
+		if len(implied) == 0 {
+			continue
+		}
+		if scheme == "*" {
+			conditions = append(conditions, fmt.Sprintf("(request_scheme = ? AND request_action IN (%s))", placeholders(len(implied))))
+			args = append(args, s)
+		} else {
+			conditions = append(conditions, fmt.Sprintf("request_action IN (%s)", placeholders(len(implied))))
+		}
+		for _, a := range implied {
+			args = append(args, a)
+		}
+	}
+
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// placeholders returns n comma separated query placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// sortedKeys returns the keys of a set ordered by name
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
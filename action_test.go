@@ -0,0 +1,124 @@
+package syncer
+
+import (
+	"errors"
+	"testing"
+)
+
+// imageHierarchy registers admin > push > pull for the image scheme
+func imageHierarchy(dm *DbManager) error {
+	if err := dm.AddImpliedAction("image", "admin", "push"); err != nil {
+		return err
+	}
+
+	return dm.AddImpliedAction("image", "push", "pull")
+}
+
+func TestAddImpliedAction(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	is.NoErr(imageHierarchy(dbManager))
+	is.NoErr(dbManager.AddImpliedAction("hive", "write", "read"))
+
+	// When
+	cycleErr := dbManager.AddImpliedAction("image", "pull", "admin")
+	selfErr := dbManager.AddImpliedAction("image", "pull", "pull")
+	wildcardErr := dbManager.AddImpliedAction("*", "write", "read")
+	implied, err := dbManager.ImpliedActions("image", "admin")
+	is.NoErr(err)
+
+	// Then
+	is.True(errors.Is(cycleErr, ErrActionCycle))
+	is.True(errors.Is(selfErr, ErrActionCycle))
+	is.True(wildcardErr != nil)
+	is.Equal(implied, []string{"pull", "push"})
+	implications, err := dbManager.ListImpliedActions()
+	is.NoErr(err)
+	is.Equal(implications, []ActionImplication{
+		{Scheme: "hive", Action: "write", Implies: "read"},
+		{Scheme: "image", Action: "admin", Implies: "push"},
+		{Scheme: "image", Action: "push", Implies: "pull"},
+	})
+}
+
+func TestFindGranted_implied_action(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	push := Granted{ContainerName: "image/container", GrandScheme: "image", GrandAction: "push"}
+	hive := Granted{ContainerName: "image/container", GrandScheme: "hive", GrandAction: "push"}
+	mockGranted(dbManager, push)
+	mockGranted(dbManager, hive)
+	pull := Requested{ContainerName: "image/container", RequestScheme: "image", RequestAction: "pull"}
+	admin := Requested{ContainerName: "image/container", RequestScheme: "image", RequestAction: "admin"}
+	before, err := dbManager.FindGranted([]Requested{pull})
+	is.NoErr(err)
+	is.NoErr(imageHierarchy(dbManager))
+
+	// When
+	granted, err := dbManager.FindGranted([]Requested{pull})
+	is.NoErr(err)
+	higher, err := dbManager.FindGranted([]Requested{admin})
+	is.NoErr(err)
+
+	// Then
+	is.Equal(len(before), 0)
+	is.Equal(granted, []Granted{push}) // The hierarchy of image does not apply to hive
+	is.Equal(len(higher), 0)           // Implication only goes down
+}
+
+func TestFindGranted_implied_action_wildcard_scheme(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	is.NoErr(imageHierarchy(dbManager))
+	is.NoErr(dbManager.AddImpliedAction("hive", "write", "read"))
+	admin := Granted{ContainerName: "image/container", GrandScheme: "image", GrandAction: "admin"}
+	write := Granted{ContainerName: "image/container", GrandScheme: "*", GrandAction: "write"}
+	mockGranted(dbManager, admin)
+	mockGranted(dbManager, write)
+
+	// When
+	anyScheme, err := dbManager.FindGranted([]Requested{{ContainerName: "image/container", RequestScheme: "*", RequestAction: "pull"}})
+	is.NoErr(err)
+	hive, err := dbManager.FindGranted([]Requested{{ContainerName: "image/container", RequestScheme: "hive", RequestAction: "read"}})
+	is.NoErr(err)
+
+	// Then
+	is.Equal(anyScheme, []Granted{admin})
+	is.Equal(hive, []Granted{write})
+}
+
+func TestFindRequested_implied_action(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	is.NoErr(imageHierarchy(dbManager))
+	pull := Requested{ContainerName: "image/container", RequestScheme: "image", RequestAction: "pull"}
+	admin := Requested{ContainerName: "image/container", RequestScheme: "image", RequestAction: "admin"}
+	mockRequested(dbManager, pull)
+	mockRequested(dbManager, admin)
+
+	// When
+	requested, err := dbManager.FindRequested([]Granted{{ContainerName: "image/container", GrandScheme: "image", GrandAction: "push"}})
+	is.NoErr(err)
+	anyScheme, err := dbManager.FindRequested([]Granted{{ContainerName: "image/container", GrandScheme: "*", GrandAction: "push"}})
+	is.NoErr(err)
+
+	// Then
+	is.Equal(requested, []Requested{pull})
+	is.Equal(anyScheme, []Requested{pull})
+}
+
+func TestExplain_implied_action(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	is.NoErr(imageHierarchy(dbManager))
+	requested := Requested{ContainerName: "image/container", RequestScheme: "image", RequestAction: "pull"}
+	granted := Granted{ContainerName: "image/container", GrandScheme: "image", GrandAction: "admin"}
+
+	// When
+	dimensions, err := dbManager.Explain(requested, granted)
+	is.NoErr(err)
+
+	// Then
+	is.Equal(dimensions[1], DimensionMatch{Dimension: DimensionAction, Requested: "pull", Granted: "admin", Matched: true, Reason: ReasonImpliedAction})
+	is.True(!Matches(requested, granted)) // Without a DbManager actions are compared as they are
+}
@@ -0,0 +1,261 @@
+package syncer
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RecordKind tells whether a record is a requested or a granted permission
+type RecordKind string
+
+const (
+	KindRequested RecordKind = "requested"
+	KindGranted   RecordKind = "granted"
+)
+
+// AuditEntry is one mutation in the audit log. Depending on Kind either the
+// Requested or the Granted values are set. The before value is nil when the
+// record was created and the after value is nil when the record was deleted.
+type AuditEntry struct {
+	ID              int64           `json:"id"`
+	Actor           string          `json:"actor"`
+	Timestamp       time.Time       `json:"timestamp"`
+	Operation       ChangeOperation `json:"operation"`
+	Kind            RecordKind      `json:"kind"`
+	Locator         string          `json:"locator"`
+	BeforeRequested *Requested      `json:"before_requested,omitempty"`
+	AfterRequested  *Requested      `json:"after_requested,omitempty"`
+	BeforeGranted   *Granted        `json:"before_granted,omitempty"`
+	AfterGranted    *Granted        `json:"after_granted,omitempty"`
+}
+
+// AuditFilter selects audit entries, empty fields match everything
+type AuditFilter struct {
+	// Locator selects the records of a container, e.g. //host/image/container?target=cmd.
+	// The host and target are only compared when the locator contains them.
+	Locator string
+	// Organization selects the records with this source or umbrella organization
+	Organization string
+	// Since and Until limit the time range, Since is inclusive and Until is exclusive
+	Since time.Time
+	Until time.Time
+}
+
+// AsActor returns a DbManager that shares the database with dm and records
+// actor as the author of its saves and deletes in the audit log and of its
+// checks in the decision log
+func (dm *DbManager) AsActor(actor string) *DbManager {
+	scoped := *dm
+	scoped.actor = actor
+
+	return &scoped
+}
+
+// auditRecord holds the columns of a record that the audit log can be filtered on
+type auditRecord struct {
+	host                 string
+	containerName        string
+	target               string
+	sourceOrganization   string
+	umbrellaOrganization string
+}
+
+func requestedAuditRecord(r Requested) auditRecord {
+	return auditRecord{r.Host, r.ContainerName, r.Target, r.SourceOrganization, r.UmbrellaOrganization}
+}
+
+func grantedAuditRecord(g Granted) auditRecord {
+	return auditRecord{g.Host, g.ContainerName, g.Target, g.SourceOrganization, g.UmbrellaOrganization}
+}
+
+// auditRequested writes the mutation of a requested record, before or after is nil when the record did not exist
+func (dm *DbManager) auditRequested(q querier, operation ChangeOperation, locator string, before, after *Requested) error {
+	record := auditRecord{}
+	if after != nil {
+		record = requestedAuditRecord(*after)
+	} else if before != nil {
+		record = requestedAuditRecord(*before)
+	}
+
+	return dm.audit(q, operation, KindRequested, locator, record, auditValue(before), auditValue(after))
+}
+
+// auditGranted writes the mutation of a granted record, before or after is nil when the record did not exist
+func (dm *DbManager) auditGranted(q querier, operation ChangeOperation, locator string, before, after *Granted) error {
+	record := auditRecord{}
+	if after != nil {
+		record = grantedAuditRecord(*after)
+	} else if before != nil {
+		record = grantedAuditRecord(*before)
+	}
+
+	return dm.audit(q, operation, KindGranted, locator, record, auditValue(before), auditValue(after))
+}
+
+func (dm *DbManager) audit(q querier, operation ChangeOperation, kind RecordKind, locator string, record auditRecord, before, after auditJSON) error {
+	beforeJSON, err := before()
+	if err != nil {
+		return err
+	}
+	afterJSON, err := after()
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(`
+	INSERT INTO audit_log (
+		actor,
+		timestamp,
+		operation,
+		kind,
+		locator,
+		host,
+		container_name,
+		target,
+		source_organization,
+		umbrella_organization,
+		before_value,
+		after_value
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		dm.actor,
+		dm.now().UnixNano(),
+		operation,
+		kind,
+		locator,
+		strings.ToLower(record.host),
+		record.containerName,
+		record.target,
+		strings.ToLower(record.sourceOrganization),
+		strings.ToLower(record.umbrellaOrganization),
+		beforeJSON,
+		afterJSON,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
+
+// auditJSON encodes a before or after value for the audit log
+type auditJSON func() (any, error)
+
+// auditValue encodes a record as JSON, a nil record is stored as NULL
+func auditValue[T Requested | Granted](value *T) auditJSON {
+	return func() (any, error) {
+		if value == nil {
+			return nil, nil
+		}
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode audit value: %w", err)
+		}
+
+		return string(data), nil
+	}
+}
+
+// AuditLog returns the audit entries that match the filter, oldest first
+func (dm *DbManager) AuditLog(filter AuditFilter) ([]AuditEntry, error) {
+	var conditions []string
+	var args []any
+
+	if filter.Locator != "" {
+		u, err := parseLocator(filter.Locator)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, "container_name = ?")
+		args = append(args, strings.TrimPrefix(u.Path, "/"))
+		if u.Host != "" {
+			conditions = append(conditions, "host = ?")
+			args = append(args, u.Host)
+		}
+		if target := u.Query().Get("target"); target != "" {
+			conditions = append(conditions, "target = ?")
+			args = append(args, target)
+		}
+	}
+	if filter.Organization != "" {
+		conditions = append(conditions, "(source_organization = ? OR umbrella_organization = ?)")
+		organization := strings.ToLower(filter.Organization)
+		args = append(args, organization, organization)
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "timestamp >= ?")
+		args = append(args, filter.Since.UnixNano())
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "timestamp < ?")
+		args = append(args, filter.Until.UnixNano())
+	}
+
+	query := `SELECT id, actor, timestamp, operation, kind, locator, before_value, after_value FROM audit_log`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id"
+
+	rows, err := dm.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []AuditEntry
+	for rows.Next() {
+		var entry AuditEntry
+		var timestamp int64
+		var before, after sql.NullString
+		err := rows.Scan(&entry.ID, &entry.Actor, &timestamp, &entry.Operation, &entry.Kind, &entry.Locator, &before, &after)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entry.Timestamp = time.Unix(0, timestamp).UTC()
+		if err := entry.decodeValues(before, after); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// decodeValues decodes the before and after values stored by auditValue
+func (entry *AuditEntry) decodeValues(before, after sql.NullString) error {
+	var err error
+	switch entry.Kind {
+	case KindRequested:
+		if entry.BeforeRequested, err = decodeAuditValue[Requested](before); err != nil {
+			return err
+		}
+		entry.AfterRequested, err = decodeAuditValue[Requested](after)
+	case KindGranted:
+		if entry.BeforeGranted, err = decodeAuditValue[Granted](before); err != nil {
+			return err
+		}
+		entry.AfterGranted, err = decodeAuditValue[Granted](after)
+	default:
+		err = fmt.Errorf("unknown record kind %q in audit log", entry.Kind)
+	}
+
+	return err
+}
+
+// decodeAuditValue is the inverse of auditValue
+func decodeAuditValue[T Requested | Granted](value sql.NullString) (*T, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+
+	var decoded T
+	if err := json.Unmarshal([]byte(value.String), &decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode audit value: %w", err)
+	}
+
+	return &decoded, nil
+}
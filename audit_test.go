@@ -0,0 +1,140 @@
+package syncer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAudit_records_before_and_after_of_granted(t *testing.T) {
+	// Given
+	is, dbManager, clock := setupTestDBWithClock(t)
+	admin := dbManager.AsActor("admin")
+	granted := Granted{ContainerName: "image/container", GrandScheme: "image"}
+
+	// When
+	is.NoErr(admin.SaveGranted(granted))
+	clock.Advance(time.Minute)
+	updated := granted
+	updated.ExpiresAt = clock.Now().Add(time.Hour)
+	is.NoErr(admin.SaveGranted(updated))
+	clock.Advance(time.Minute)
+	is.NoErr(dbManager.DeleteGranted(updated))
+
+	// Then
+	entries, err := dbManager.AuditLog(AuditFilter{})
+	is.NoErr(err)
+	is.Equal(len(entries), 3)
+
+	is.Equal(entries[0].Actor, "admin")
+	is.Equal(entries[0].Operation, ChangeSaved)
+	is.Equal(entries[0].Kind, KindGranted)
+	is.Equal(entries[0].Locator, grantedLocator(granted))
+	is.Equal(entries[0].BeforeGranted, nil) // Created
+	is.Equal(*entries[0].AfterGranted, granted)
+
+	is.Equal(entries[1].Timestamp, clock.Now().Add(-time.Minute))
+	is.Equal(*entries[1].BeforeGranted, granted)
+	is.Equal(*entries[1].AfterGranted, updated)
+
+	is.Equal(entries[2].Actor, "") // The original manager has no actor
+	is.Equal(entries[2].Operation, ChangeDeleted)
+	is.Equal(*entries[2].BeforeGranted, updated)
+	is.Equal(entries[2].AfterGranted, nil)
+}
+
+func TestAudit_records_requested(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	requested := Requested{ContainerName: "image/container", RequestScheme: "image"}
+
+	// When
+	is.NoErr(dbManager.AsActor("ci").SaveRequested([]Requested{requested}))
+	is.NoErr(dbManager.DeleteRequested([]Requested{requested, {RequestScheme: "missing"}}))
+
+	// Then
+	entries, err := dbManager.AuditLog(AuditFilter{})
+	is.NoErr(err)
+	is.Equal(len(entries), 2) // Deleting a missing record is not audited
+	is.Equal(entries[0].Actor, "ci")
+	is.Equal(entries[0].Kind, KindRequested)
+	is.Equal(entries[0].BeforeRequested, nil)
+	is.Equal(*entries[0].AfterRequested, requested)
+	is.Equal(entries[0].BeforeGranted, nil)
+	is.Equal(*entries[1].BeforeRequested, requested)
+	is.Equal(entries[1].AfterRequested, nil)
+}
+
+func TestAudit_records_purged_grants(t *testing.T) {
+	// Given
+	is, dbManager, clock := setupTestDBWithClock(t)
+	mockGranted(dbManager, Granted{GrandScheme: "image", ExpiresAt: clock.Now().Add(time.Hour)})
+	clock.Advance(2 * time.Hour)
+
+	// When
+	purged, err := dbManager.AsActor("janitor").PurgeExpired()
+
+	// Then
+	is.NoErr(err)
+	is.Equal(purged, 1)
+	entries, err := dbManager.AuditLog(AuditFilter{})
+	is.NoErr(err)
+	is.Equal(len(entries), 2)
+	is.Equal(entries[1].Actor, "janitor")
+	is.Equal(entries[1].Operation, ChangeDeleted)
+	is.Equal(entries[1].AfterGranted, nil)
+}
+
+func TestAudit_filter(t *testing.T) {
+	// Given
+	is, dbManager, clock := setupTestDBWithClock(t)
+	start := clock.Now()
+	mockGranted(dbManager, Granted{Host: "Host", ContainerName: "image/container", Target: "cmd", SourceOrganization: "Confetti-CMS", GrandScheme: "image"})
+	clock.Advance(time.Hour)
+	mockGranted(dbManager, Granted{ContainerName: "image/other", UmbrellaOrganization: "confetti-cms", GrandScheme: "image"})
+	clock.Advance(time.Hour)
+	mockRequested(dbManager, Requested{ContainerName: "image/container", Target: "run", SourceOrganization: "other", RequestScheme: "image"})
+
+	tests := []struct {
+		name   string
+		filter AuditFilter
+		want   int
+	}{
+		{"everything", AuditFilter{}, 3},
+		{"container", AuditFilter{Locator: "/image/container"}, 2},
+		{"container and host", AuditFilter{Locator: "//host/image/container"}, 1},
+		{"host is case-insensitive", AuditFilter{Locator: "//HOST/image/container"}, 1},
+		{"container and target", AuditFilter{Locator: "/image/container?target=run"}, 1},
+		{"source or umbrella organization", AuditFilter{Organization: "CONFETTI-CMS"}, 2},
+		{"since is inclusive", AuditFilter{Since: start.Add(time.Hour)}, 2},
+		{"until is exclusive", AuditFilter{Until: start.Add(time.Hour)}, 1},
+		{"combined", AuditFilter{Locator: "/image/container", Since: start.Add(time.Hour)}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			entries, err := dbManager.AuditLog(tt.filter)
+
+			// Then
+			is.NoErr(err)
+			is.Equal(len(entries), tt.want)
+		})
+	}
+}
+
+func TestAudit_rolled_back_with_failed_save(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	_, err := dbManager.db.Exec(`CREATE TRIGGER fail_second BEFORE INSERT ON requested
+		WHEN NEW.request_scheme = 'fail' BEGIN SELECT RAISE(ABORT, 'failed'); END`)
+	is.NoErr(err)
+
+	// When
+	err = dbManager.SaveRequested([]Requested{{RequestScheme: "image"}, {RequestScheme: "fail"}})
+
+	// Then
+	is.True(err != nil)
+	entries, err := dbManager.AuditLog(AuditFilter{})
+	is.NoErr(err)
+	is.Equal(len(entries), 0)
+}
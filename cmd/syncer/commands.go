@@ -0,0 +1,1063 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/confetti-cms/syncer"
+)
+
+// options are the flags shared by all commands
+type options struct {
+	db     string
+	format string
+}
+
+func newFlagSet(name string, stderr io.Writer) (*flag.FlagSet, *options) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	opts := &options{}
+	fs.StringVar(&opts.db, "db", "syncer.db", "path to the database file")
+	fs.StringVar(&opts.format, "format", formatTable, "output format: table or json")
+
+	return fs, opts
+}
+
+// parseFlags parses the arguments, extra formats are accepted next to table and json
+func parseFlags(fs *flag.FlagSet, opts *options, args []string, formats ...string) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	formats = append([]string{formatTable, formatJSON}, formats...)
+	if !slices.Contains(formats, opts.format) {
+		return fmt.Errorf("unknown format %q, use %s", opts.format, strings.Join(formats, ", "))
+	}
+
+	return nil
+}
+
+func (opts *options) open() (*syncer.DbManager, error) {
+	return syncer.OpenDbManager(opts.db)
+}
+
+// input are the flags that describe the permissions a command works on
+type input struct {
+	locator     string
+	json        string
+	scheme      string
+	action      string
+	description string
+	path        string
+	notBefore   string
+	expiresAt   string
+}
+
+func (in *input) register(fs *flag.FlagSet, pathFlag, pathUsage string) {
+	fs.StringVar(&in.locator, "locator", "", "locator of the container")
+	fs.StringVar(&in.json, "json", "", "JSON object or array, - reads from stdin")
+	fs.StringVar(&in.scheme, "scheme", "", "scheme, used with -locator")
+	fs.StringVar(&in.action, "action", "", "action, used with -locator (default *)")
+	fs.StringVar(&in.description, "description", "", "description, used with -locator")
+	fs.StringVar(&in.path, pathFlag, "", pathUsage+", used with -locator")
+}
+
+// registerTimeWindow adds the flags that limit the time a grant is active
+func (in *input) registerTimeWindow(fs *flag.FlagSet) {
+	fs.StringVar(&in.notBefore, "not-before", "", "RFC 3339 time the grant becomes active, used with -locator")
+	fs.StringVar(&in.expiresAt, "expires-at", "", "RFC 3339 time the grant expires, used with -locator")
+}
+
+func (in *input) validate() error {
+	if (in.locator == "") == (in.json == "") {
+		return errors.New("exactly one of -locator or -json is required")
+	}
+
+	return nil
+}
+
+func (in *input) requested(stdin io.Reader) ([]syncer.Requested, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+	if in.json != "" {
+		return decodeList[syncer.Requested](in.json, stdin)
+	}
+
+	requested, err := syncer.FillRequestedByLocator(in.locator, syncer.Requested{
+		Description:     in.description,
+		DestinationPath: in.path,
+		RequestScheme:   in.scheme,
+		RequestAction:   in.action,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []syncer.Requested{requested}, nil
+}
+
+func (in *input) granted(stdin io.Reader) ([]syncer.Granted, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+	if in.json != "" {
+		return decodeList[syncer.Granted](in.json, stdin)
+	}
+
+	notBefore, err := parseTime("not-before", in.notBefore)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt, err := parseTime("expires-at", in.expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	granted, err := syncer.FillGrantedByLocator(in.locator, syncer.Granted{
+		Description: in.description,
+		ExposePath:  in.path,
+		GrandScheme: in.scheme,
+		GrandAction: in.action,
+		NotBefore:   notBefore,
+		ExpiresAt:   expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []syncer.Granted{granted}, nil
+}
+
+// registerAsOf adds the -as-of flag, the returned function converts it to query options
+func registerAsOf(fs *flag.FlagSet) func() ([]syncer.QueryOption, error) {
+	asOf := fs.String("as-of", "", "RFC 3339 time to query the stored permissions at")
+
+	return func() ([]syncer.QueryOption, error) {
+		t, err := parseTime("as-of", *asOf)
+		if err != nil || t.IsZero() {
+			return nil, err
+		}
+
+		return []syncer.QueryOption{syncer.AsOf(t)}, nil
+	}
+}
+
+// parseTime parses an optional RFC 3339 flag value
+func parseTime(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid -%s: %w", name, err)
+	}
+
+	return t, nil
+}
+
+// decodeList decodes a JSON object or array of objects, "-" reads the JSON from stdin
+func decodeList[T any](value string, stdin io.Reader) ([]T, error) {
+	data := []byte(value)
+	if value == "-" {
+		var err error
+		data, err = io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stdin: %w", err)
+		}
+	}
+
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		var list []T
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return list, nil
+	}
+
+	var item T
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	return []T{item}, nil
+}
+
+func runGrant(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("grant", stderr)
+	in := &input{}
+	in.register(fs, "expose-path", "path exposed to the grantee")
+	in.registerTimeWindow(fs)
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+
+	granted, err := in.granted(stdin)
+	if err != nil {
+		return err
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	if err := dm.SaveGrantedBatch(granted); err != nil {
+		return fmt.Errorf("failed to save granted: %w", err)
+	}
+
+	return printGranted(stdout, opts.format, granted)
+}
+
+func runRequest(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("request", stderr)
+	in := &input{}
+	in.register(fs, "destination-path", "path the data is synced to")
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+
+	requested, err := in.requested(stdin)
+	if err != nil {
+		return err
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	if err := dm.SaveRequested(requested); err != nil {
+		return fmt.Errorf("failed to save requested: %w", err)
+	}
+
+	return printRequested(stdout, opts.format, requested)
+}
+
+func runFindGranted(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("find-granted", stderr)
+	in := &input{}
+	in.register(fs, "destination-path", "path the data is synced to")
+	asOf := registerAsOf(fs)
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+	queryOptions, err := asOf()
+	if err != nil {
+		return err
+	}
+
+	requested, err := in.requested(stdin)
+	if err != nil {
+		return err
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	granted, err := dm.FindGranted(requested, queryOptions...)
+	if err != nil {
+		return err
+	}
+
+	return printGranted(stdout, opts.format, granted)
+}
+
+func runFindRequested(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("find-requested", stderr)
+	in := &input{}
+	in.register(fs, "expose-path", "path exposed to the grantee")
+	asOf := registerAsOf(fs)
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+	queryOptions, err := asOf()
+	if err != nil {
+		return err
+	}
+
+	granted, err := in.granted(stdin)
+	if err != nil {
+		return err
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	requested, err := dm.FindRequested(granted, queryOptions...)
+	if err != nil {
+		return err
+	}
+
+	return printRequested(stdout, opts.format, requested)
+}
+
+// explanation is the comparison of one requested permission with one stored grant
+type explanation struct {
+	Requested  syncer.Requested        `json:"requested"`
+	Granted    syncer.Granted          `json:"granted"`
+	Matched    bool                    `json:"matched"`
+	Dimensions []syncer.DimensionMatch `json:"dimensions"`
+}
+
+func runExplain(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("explain", stderr)
+	in := &input{}
+	in.register(fs, "destination-path", "path the data is synced to")
+	matchedOnly := fs.Bool("matched", false, "only show grants that match")
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+
+	requested, err := in.requested(stdin)
+	if err != nil {
+		return err
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	granted, err := dm.ListGranted()
+	if err != nil {
+		return err
+	}
+
+	explanations := []explanation{}
+	for _, r := range requested {
+		for _, g := range granted {
+			dimensions, err := dm.Explain(r, g)
+			if err != nil {
+				return err
+			}
+			matched := true
+			for _, d := range dimensions {
+				matched = matched && d.Matched
+			}
+			if *matchedOnly && !matched {
+				continue
+			}
+			explanations = append(explanations, explanation{
+				Requested:  r,
+				Granted:    g,
+				Matched:    matched,
+				Dimensions: dimensions,
+			})
+		}
+	}
+
+	return printExplanations(stdout, opts.format, explanations)
+}
+
+func runList(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("list", stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: syncer list [flags] granted|requested")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	switch fs.Arg(0) {
+	case "granted":
+		granted, err := dm.ListGranted()
+		if err != nil {
+			return err
+		}
+		return printGranted(stdout, opts.format, granted)
+	case "requested":
+		requested, err := dm.ListRequested()
+		if err != nil {
+			return err
+		}
+		return printRequested(stdout, opts.format, requested)
+	default:
+		return fmt.Errorf("unknown table %q, use granted or requested", fs.Arg(0))
+	}
+}
+
+func runPurgeExpired(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("purge-expired", stderr)
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	count, err := dm.PurgeExpired()
+	if err != nil {
+		return err
+	}
+
+	if opts.format == formatJSON {
+		return printJSON(stdout, map[string]int{"purged": count})
+	}
+	_, err = fmt.Fprintf(stdout, "purged %d expired grants\n", count)
+
+	return err
+}
+
+func runExport(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("export", stderr)
+	fileFormat := fs.String("file-format", string(syncer.FormatJSON), "format of the export: json or yaml")
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	return dm.Export(stdout, syncer.Format(*fileFormat))
+}
+
+func runImport(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("import", stderr)
+	fileFormat := fs.String("file-format", "", "format of the file: json or yaml (default from the file extension, else json)")
+	dryRun := fs.Bool("dry-run", false, "report the changes without saving them")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: syncer import [flags] file|-")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	path := fs.Arg(0)
+	format := syncer.Format(*fileFormat)
+	if format == "" {
+		format = formatFromPath(path)
+	}
+
+	r := stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open import file: %w", err)
+		}
+		defer file.Close()
+		r = file
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	var importOptions []syncer.ImportOption
+	if *dryRun {
+		importOptions = append(importOptions, syncer.DryRun())
+	}
+	report, err := dm.Import(r, format, importOptions...)
+	if err != nil {
+		return err
+	}
+
+	return printImportReport(stdout, opts.format, report)
+}
+
+// formatFromPath picks the document format from the file extension
+func formatFromPath(path string) syncer.Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return syncer.FormatYAML
+	default:
+		return syncer.FormatJSON
+	}
+}
+
+// registerPermissionDir adds the -dir flag, the returned function loads its permission files
+func registerPermissionDir(fs *flag.FlagSet) func() ([]syncer.PermissionFile, error) {
+	dir := fs.String("dir", "permissions", "directory with a YAML file per umbrella repository")
+
+	return func() ([]syncer.PermissionFile, error) {
+		return syncer.LoadPermissionFiles(*dir)
+	}
+}
+
+func runPlan(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("plan", stderr)
+	load := registerPermissionDir(fs)
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+
+	files, err := load()
+	if err != nil {
+		return err
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	plan, err := dm.Plan(files)
+	if err != nil {
+		return err
+	}
+
+	return printPlan(stdout, opts.format, plan, false)
+}
+
+func runApply(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("apply", stderr)
+	load := registerPermissionDir(fs)
+	actor := fs.String("actor", "", "name recorded in the audit log")
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+
+	files, err := load()
+	if err != nil {
+		return err
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	plan, err := dm.Plan(files)
+	if err != nil {
+		return err
+	}
+	if err := dm.AsActor(*actor).Apply(plan); err != nil {
+		return err
+	}
+
+	return printPlan(stdout, opts.format, plan, true)
+}
+
+func runDrift(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("drift", stderr)
+	load := registerPermissionDir(fs)
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+
+	files, err := load()
+	if err != nil {
+		return err
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	drift, err := dm.DetectDrift(files)
+	if err != nil {
+		return err
+	}
+	if err := printDrift(stdout, opts.format, drift); err != nil {
+		return err
+	}
+	if len(drift) > 0 {
+		return fmt.Errorf("found %d records that differ from the permission files", len(drift))
+	}
+
+	return nil
+}
+
+func runAccess(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("access", stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: syncer access [flags] -container name")
+		fs.PrintDefaults()
+	}
+	containerName := fs.String("container", "", "container name to report the access of")
+	target := fs.String("target", "", "target of the container")
+	asOf := registerAsOf(fs)
+	if err := parseFlags(fs, opts, args, formatCSV); err != nil {
+		return err
+	}
+	if *containerName == "" {
+		fs.Usage()
+		return errUsage
+	}
+	queryOptions, err := asOf()
+	if err != nil {
+		return err
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	report, err := dm.AccessReport(*containerName, *target, queryOptions...)
+	if err != nil {
+		return err
+	}
+
+	return printAccessReport(stdout, opts.format, report)
+}
+
+func runUnsatisfied(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("unsatisfied", stderr)
+	closest := fs.Int("closest", 3, "number of closest grants to show for every requested permission")
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	groups, err := dm.UnsatisfiedRequests(*closest)
+	if err != nil {
+		return err
+	}
+	if err := printUnsatisfied(stdout, opts.format, groups); err != nil {
+		return err
+	}
+	count := 0
+	for _, group := range groups {
+		count += len(group.Unsatisfied)
+	}
+	if count > 0 {
+		return fmt.Errorf("found %d requested permissions without a grant", count)
+	}
+
+	return nil
+}
+
+func runUnused(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("unused", stderr)
+	decisionLog := fs.String("decisions", "", "JSON lines decision log, only grants without a matching decision within -window are reported")
+	window := fs.Duration("window", 30*24*time.Hour, "window of the decisions, used with -decisions")
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+
+	var unusedOptions []syncer.UnusedOption
+	if *decisionLog != "" {
+		file, err := os.Open(*decisionLog)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		decisions, err := syncer.ReadDecisions(file)
+		if err != nil {
+			return err
+		}
+		unusedOptions = append(unusedOptions, syncer.NoDecisionWithin(*window, decisions))
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	unused, err := dm.UnusedGrants(unusedOptions...)
+	if err != nil {
+		return err
+	}
+
+	return printUnused(stdout, opts.format, unused)
+}
+
+func runLint(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("lint", stderr)
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	findings, err := dm.LintGranted(syncer.NewLinter())
+	if err != nil {
+		return err
+	}
+	if err := printLintFindings(stdout, opts.format, findings); err != nil {
+		return err
+	}
+	errorCount := 0
+	for _, finding := range findings {
+		if finding.Severity == syncer.SeverityError {
+			errorCount++
+		}
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("found %d lint errors", errorCount)
+	}
+
+	return nil
+}
+
+func runRedundant(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("redundant", stderr)
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	redundancies, err := dm.RedundantGrants()
+	if err != nil {
+		return err
+	}
+
+	return printRedundancies(stdout, opts.format, redundancies)
+}
+
+func runMounts(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("mounts", stderr)
+	in := &input{}
+	in.register(fs, "destination-path", "path the data is synced to")
+	stored := fs.Bool("stored", false, "resolve the mounts of all stored requested permissions")
+	asOf := registerAsOf(fs)
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+	queryOptions, err := asOf()
+	if err != nil {
+		return err
+	}
+
+	var requested []syncer.Requested
+	if !*stored {
+		if requested, err = in.requested(stdin); err != nil {
+			return err
+		}
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	if *stored {
+		if requested, err = dm.ListRequested(); err != nil {
+			return err
+		}
+	}
+	resolution, err := dm.ResolveMounts(requested, queryOptions...)
+	if err != nil {
+		return err
+	}
+	if err := printMounts(stdout, opts.format, resolution); err != nil {
+		return err
+	}
+	if len(resolution.Conflicts) > 0 {
+		return fmt.Errorf("found %d destinations with conflicting mounts", len(resolution.Conflicts))
+	}
+
+	return nil
+}
+
+func runOverlaps(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("overlaps", stderr)
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	overlaps, err := dm.DestinationOverlaps()
+	if err != nil {
+		return err
+	}
+	if err := printOverlaps(stdout, opts.format, overlaps); err != nil {
+		return err
+	}
+	if len(overlaps) > 0 {
+		return fmt.Errorf("found %d overlapping destination paths", len(overlaps))
+	}
+
+	return nil
+}
+
+func runSyncPlan(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("sync-plan", stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: syncer sync-plan [flags] -container name")
+		fs.PrintDefaults()
+	}
+	containerName := fs.String("container", "", "container name to plan the sync of")
+	target := fs.String("target", "", "target of the container")
+	asOf := registerAsOf(fs)
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+	if *containerName == "" {
+		fs.Usage()
+		return errUsage
+	}
+	queryOptions, err := asOf()
+	if err != nil {
+		return err
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	plan, err := dm.PlanContainerSync(*containerName, *target, queryOptions...)
+	if err != nil {
+		return err
+	}
+	if err := printSyncPlan(stdout, opts.format, plan); err != nil {
+		return err
+	}
+	if len(plan.Rejected) > 0 {
+		return fmt.Errorf("found %d rejected sync operations", len(plan.Rejected))
+	}
+
+	return nil
+}
+
+func runRole(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("role", stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: syncer role [flags] list|assignments|save|delete|assign|revoke [name] [key=value ...]")
+		fs.PrintDefaults()
+	}
+	roleJSON := fs.String("json", "", "JSON role or array of roles to save, - reads from stdin")
+	actor := fs.String("actor", "", "name recorded in the audit log")
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+	dm = dm.AsActor(*actor)
+
+	switch action := fs.Arg(0); action {
+	case "list":
+		roles, err := dm.ListRoles()
+		if err != nil {
+			return err
+		}
+		return printRoles(stdout, opts.format, roles)
+	case "assignments":
+		assignments, err := dm.ListAssignments()
+		if err != nil {
+			return err
+		}
+		return printAssignments(stdout, opts.format, assignments)
+	case "save":
+		if *roleJSON == "" {
+			return errors.New("role save needs -json")
+		}
+		roles, err := decodeList[syncer.Role](*roleJSON, stdin)
+		if err != nil {
+			return err
+		}
+		for _, role := range roles {
+			if err := dm.SaveRole(role); err != nil {
+				return err
+			}
+		}
+		return printRoles(stdout, opts.format, roles)
+	case "delete", "assign", "revoke":
+		if fs.NArg() < 2 {
+			fs.Usage()
+			return errUsage
+		}
+		name := fs.Arg(1)
+		if action == "delete" {
+			return dm.DeleteRole(name)
+		}
+		subject := syncer.Subject{}
+		for _, arg := range fs.Args()[2:] {
+			key, value, ok := strings.Cut(arg, "=")
+			if !ok {
+				return fmt.Errorf("invalid subject value %q, use key=value", arg)
+			}
+			subject[key] = value
+		}
+		if action == "revoke" {
+			return dm.RevokeRole(name, subject)
+		}
+		assignment, err := dm.AssignRole(name, subject)
+		if err != nil {
+			return err
+		}
+		return printGranted(stdout, opts.format, assignment.Granted)
+	default:
+		return fmt.Errorf("unknown role action %q, use list, assignments, save, delete, assign or revoke", action)
+	}
+}
+
+func runParent(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("parent", stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: syncer parent [flags] list|set|remove [organization/repository] [parent organization/repository]")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	switch action := fs.Arg(0); action {
+	case "list":
+		parents, err := dm.ListParents()
+		if err != nil {
+			return err
+		}
+		return printParents(stdout, opts.format, parents)
+	case "set", "remove":
+		if (action == "set" && fs.NArg() != 3) || (action == "remove" && fs.NArg() != 2) {
+			fs.Usage()
+			return errUsage
+		}
+		child, err := parseOwner(fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		if action == "remove" {
+			return dm.RemoveParent(child)
+		}
+		parent, err := parseOwner(fs.Arg(2))
+		if err != nil {
+			return err
+		}
+		return dm.SetParent(child, parent)
+	default:
+		return fmt.Errorf("unknown parent action %q, use list, set or remove", action)
+	}
+}
+
+// parseOwner parses an owner written as organization/repository
+func parseOwner(value string) (syncer.Owner, error) {
+	organization, repository, ok := strings.Cut(value, "/")
+	if !ok || organization == "" || repository == "" || strings.Contains(repository, "/") {
+		return syncer.Owner{}, fmt.Errorf("invalid owner %q, use organization/repository", value)
+	}
+
+	return syncer.Owner{Organization: organization, Repository: repository}, nil
+}
+
+func runAction(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, opts := newFlagSet("action", stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: syncer action [flags] list|add|remove [scheme action implied ...]")
+		fmt.Fprintln(stderr, "add and remove take a chain, e.g. image admin push pull for admin > push > pull")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, opts, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	dm, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer dm.Close()
+
+	switch operation := fs.Arg(0); operation {
+	case "list":
+		implications, err := dm.ListImpliedActions()
+		if err != nil {
+			return err
+		}
+		return printImpliedActions(stdout, opts.format, implications)
+	case "add", "remove":
+		if fs.NArg() < 4 {
+			fs.Usage()
+			return errUsage
+		}
+		scheme, chain := fs.Arg(1), fs.Args()[2:]
+		for i := 0; i < len(chain)-1; i++ {
+			if operation == "add" {
+				err = dm.AddImpliedAction(scheme, chain[i], chain[i+1])
+			} else {
+				err = dm.RemoveImpliedAction(scheme, chain[i], chain[i+1])
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown action operation %q, use list, add or remove", operation)
+	}
+}
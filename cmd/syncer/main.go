@@ -0,0 +1,116 @@
+// Command syncer inspects and queries a permission store.
+//
+// Usage:
+//
+//	syncer <command> [flags]
+//
+// The commands are:
+//
+//	grant           save granted permissions from a locator or JSON
+//	request         save requested permissions from a locator or JSON
+//	find-granted    show the granted permissions that match requested permissions
+//	find-requested  show the requested permissions that match granted permissions
+//	explain         compare requested permissions with every stored grant
+//	list            show all stored granted or requested permissions
+//	purge-expired   remove the grants that have expired
+//	export          write all stored permissions as JSON or YAML
+//	import          save the permissions of a JSON or YAML file
+//	plan            show the changes that bring the store in line with the permission files
+//	apply           execute the plan of the permission files
+//	drift           list the records that differ from the permission files
+//	access          show who can access a container target
+//	unsatisfied     list the requested permissions that no grant satisfies
+//	unused          list the grants that match no requested permission
+//	lint            check the stored grants for likely mistakes
+//	redundant       list the grants that other grants fully cover
+//	mounts          show where the data of the matched grants is copied to
+//	overlaps        list the requested permissions with overlapping destination paths
+//	sync-plan       list the ordered copy operations of a container target
+//	role            manage roles and assign them to subjects
+//	parent          manage the parents of owners that inherit their grants
+//	action          manage the actions that imply other actions within a scheme
+//
+// Every command accepts -db to select the database file and -format to print
+// a table or JSON.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// errUsage is returned when the command line is incomplete, the usage has already been printed
+var errUsage = errors.New("invalid usage")
+
+type command struct {
+	name        string
+	description string
+	run         func(args []string, stdin io.Reader, stdout, stderr io.Writer) error
+}
+
+var commands = []command{
+	{name: "grant", description: "save granted permissions from a locator or JSON", run: runGrant},
+	{name: "request", description: "save requested permissions from a locator or JSON", run: runRequest},
+	{name: "find-granted", description: "show the granted permissions that match requested permissions", run: runFindGranted},
+	{name: "find-requested", description: "show the requested permissions that match granted permissions", run: runFindRequested},
+	{name: "explain", description: "compare requested permissions with every stored grant", run: runExplain},
+	{name: "list", description: "show all stored granted or requested permissions", run: runList},
+	{name: "purge-expired", description: "remove the grants that have expired", run: runPurgeExpired},
+	{name: "export", description: "write all stored permissions as JSON or YAML", run: runExport},
+	{name: "import", description: "save the permissions of a JSON or YAML file", run: runImport},
+	{name: "plan", description: "show the changes that bring the store in line with the permission files", run: runPlan},
+	{name: "apply", description: "execute the plan of the permission files", run: runApply},
+	{name: "drift", description: "list the records that differ from the permission files", run: runDrift},
+	{name: "access", description: "show who can access a container target", run: runAccess},
+	{name: "unsatisfied", description: "list the requested permissions that no grant satisfies", run: runUnsatisfied},
+	{name: "unused", description: "list the grants that match no requested permission", run: runUnused},
+	{name: "lint", description: "check the stored grants for likely mistakes", run: runLint},
+	{name: "redundant", description: "list the grants that other grants fully cover", run: runRedundant},
+	{name: "mounts", description: "show where the data of the matched grants is copied to", run: runMounts},
+	{name: "overlaps", description: "list the requested permissions with overlapping destination paths", run: runOverlaps},
+	{name: "sync-plan", description: "list the ordered copy operations of a container target", run: runSyncPlan},
+	{name: "role", description: "manage roles and assign them to subjects", run: runRole},
+	{name: "parent", description: "manage the parents of owners that inherit their grants", run: runParent},
+	{name: "action", description: "manage the actions that imply other actions within a scheme", run: runAction},
+}
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	if errors.Is(err, errUsage) {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "syncer:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		usage(stderr)
+		return errUsage
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdin, stdout, stderr)
+		}
+	}
+
+	fmt.Fprintf(stderr, "syncer: unknown command %q\n", args[0])
+	usage(stderr)
+	return errUsage
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: syncer <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-15s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'syncer <command> -h' for the flags of a command.")
+}
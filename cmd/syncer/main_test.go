@@ -0,0 +1,557 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/confetti-cms/syncer"
+	"github.com/matryer/is"
+)
+
+const testLocator = "//host/image/container?target=cmd&umbrella_organization=confetti-sites&umbrella_repository=confetti-cms&source_organization=confetti-cms&source_repository=image"
+
+func setupTestCommand(t *testing.T) (*is.I, func(stdin string, args ...string) (string, error)) {
+	is := is.New(t)
+	db := filepath.Join(t.TempDir(), "syncer.db")
+
+	return is, func(stdin string, args ...string) (string, error) {
+		var stdout, stderr bytes.Buffer
+		if len(args) > 0 {
+			args = append([]string{args[0], "-db", db}, args[1:]...)
+		}
+		err := run(args, strings.NewReader(stdin), &stdout, &stderr)
+		return stdout.String(), err
+	}
+}
+
+func TestCommand_grant_and_list(t *testing.T) {
+	// Given
+	is, syncerCmd := setupTestCommand(t)
+
+	// When
+	_, err := syncerCmd("", "grant", "-locator", testLocator, "-scheme", "image", "-action", "pull")
+	is.NoErr(err)
+	output, err := syncerCmd("", "list", "-format", "json", "granted")
+
+	// Then
+	is.NoErr(err)
+	var granted []syncer.Granted
+	is.NoErr(json.Unmarshal([]byte(output), &granted))
+	is.Equal(len(granted), 1)
+	is.Equal(granted[0].Host, "host")
+	is.Equal(granted[0].ContainerName, "image/container")
+	is.Equal(granted[0].GrandScheme, "image")
+	is.Equal(granted[0].GrandAction, "pull")
+}
+
+func TestCommand_request_from_json_stdin(t *testing.T) {
+	// Given
+	is, syncerCmd := setupTestCommand(t)
+	input := `[{"ContainerName": "image/container", "container_name": "image/container", "scheme": "image"}, {"ContainerName": "image/other", "scheme": "json"}]`
+
+	// When
+	_, err := syncerCmd(input, "request", "-json", "-")
+	is.NoErr(err)
+	output, err := syncerCmd("", "list", "requested")
+
+	// Then
+	is.NoErr(err)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	is.Equal(len(lines), 3) // header and two rows
+	is.True(strings.HasPrefix(lines[0], "HOST"))
+	is.True(strings.Contains(lines[1], "image/container"))
+	is.True(strings.Contains(lines[2], "image/other"))
+}
+
+func TestCommand_find_granted(t *testing.T) {
+	// Given
+	is, syncerCmd := setupTestCommand(t)
+	_, err := syncerCmd("", "grant", "-locator", testLocator, "-scheme", "image", "-action", "pull")
+	is.NoErr(err)
+
+	// When
+	matching, err := syncerCmd("", "find-granted", "-format", "json", "-locator", testLocator, "-scheme", "image", "-action", "pull")
+	is.NoErr(err)
+	other, err := syncerCmd("", "find-granted", "-format", "json", "-locator", testLocator, "-scheme", "image", "-action", "push")
+	is.NoErr(err)
+
+	// Then
+	var granted []syncer.Granted
+	is.NoErr(json.Unmarshal([]byte(matching), &granted))
+	is.Equal(len(granted), 1)
+	is.NoErr(json.Unmarshal([]byte(other), &granted))
+	is.Equal(len(granted), 0)
+}
+
+func TestCommand_find_requested(t *testing.T) {
+	// Given
+	is, syncerCmd := setupTestCommand(t)
+	_, err := syncerCmd("", "request", "-locator", testLocator, "-scheme", "image")
+	is.NoErr(err)
+
+	// When
+	output, err := syncerCmd("", "find-requested", "-format", "json", "-locator", testLocator, "-scheme", "image")
+
+	// Then
+	is.NoErr(err)
+	var requested []syncer.Requested
+	is.NoErr(json.Unmarshal([]byte(output), &requested))
+	is.Equal(len(requested), 1)
+	is.Equal(requested[0].RequestAction, "*")
+}
+
+func TestCommand_explain(t *testing.T) {
+	// Given
+	is, syncerCmd := setupTestCommand(t)
+	_, err := syncerCmd("", "grant", "-locator", testLocator, "-scheme", "image", "-action", "pull")
+	is.NoErr(err)
+	_, err = syncerCmd("", "grant", "-locator", testLocator, "-scheme", "hive", "-action", "pull")
+	is.NoErr(err)
+
+	// When
+	output, err := syncerCmd("", "explain", "-format", "json", "-locator", testLocator, "-scheme", "image", "-action", "pull")
+	is.NoErr(err)
+	table, err := syncerCmd("", "explain", "-matched", "-locator", testLocator, "-scheme", "image", "-action", "pull")
+	is.NoErr(err)
+
+	// Then
+	var explanations []explanation
+	is.NoErr(json.Unmarshal([]byte(output), &explanations))
+	is.Equal(len(explanations), 2)
+	matched := 0
+	for _, e := range explanations {
+		is.Equal(len(e.Dimensions), len(syncer.Dimensions))
+		if e.Matched {
+			matched++
+			is.Equal(e.Granted.GrandScheme, "image")
+		}
+	}
+	is.Equal(matched, 1)
+	is.True(strings.Contains(table, ": match"))
+	is.True(!strings.Contains(table, "no match"))
+}
+
+func TestCommand_errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		usage bool
+	}{
+		{name: "no command", args: nil, usage: true},
+		{name: "unknown command", args: []string{"revoke"}, usage: true},
+		{name: "unknown flag", args: []string{"grant", "-unknown"}, usage: true},
+		{name: "list without table", args: []string{"list"}, usage: true},
+		{name: "list unknown table", args: []string{"list", "roles"}},
+		{name: "missing input", args: []string{"grant"}},
+		{name: "both inputs", args: []string{"grant", "-locator", testLocator, "-json", "{}"}},
+		{name: "invalid json", args: []string{"request", "-json", "{"}},
+		{name: "invalid locator", args: []string{"request", "-locator", "//host/%zz"}},
+		{name: "unknown format", args: []string{"list", "-format", "xml", "granted"}},
+		{name: "csv format of list", args: []string{"list", "-format", "csv", "granted"}},
+		{name: "access without container", args: []string{"access"}, usage: true},
+		{name: "unsatisfied with negative closest", args: []string{"unsatisfied", "-closest", "-1"}},
+		{name: "sync-plan without container", args: []string{"sync-plan"}, usage: true},
+		{name: "role without action", args: []string{"role"}, usage: true},
+		{name: "role assign without name", args: []string{"role", "assign"}, usage: true},
+		{name: "role with invalid subject", args: []string{"role", "assign", "cms", "container"}},
+		{name: "parent without action", args: []string{"parent"}, usage: true},
+		{name: "parent set without parent", args: []string{"parent", "set", "confetti-cms/image"}, usage: true},
+		{name: "parent with invalid owner", args: []string{"parent", "set", "confetti-cms", "confetti-cms/cms"}},
+		{name: "parent cycle", args: []string{"parent", "set", "confetti-cms/cms", "confetti-cms/cms"}},
+		{name: "action without operation", args: []string{"action"}, usage: true},
+		{name: "action add without implied action", args: []string{"action", "add", "image", "push"}, usage: true},
+		{name: "action cycle", args: []string{"action", "add", "image", "push", "push"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is, syncerCmd := setupTestCommand(t)
+
+			_, err := syncerCmd("", tt.args...)
+
+			is.True(err != nil)
+			is.Equal(errors.Is(err, errUsage), tt.usage)
+		})
+	}
+}
+
+func TestCommand_grant_with_time_window_and_purge(t *testing.T) {
+	// Given
+	is, syncerCmd := setupTestCommand(t)
+	_, err := syncerCmd("", "grant", "-locator", testLocator, "-scheme", "image", "-expires-at", "2000-01-01T00:00:00Z")
+	is.NoErr(err)
+	_, err = syncerCmd("", "grant", "-locator", testLocator, "-scheme", "hive", "-not-before", "2000-01-01T00:00:00Z")
+	is.NoErr(err)
+
+	// When
+	table, err := syncerCmd("", "list", "granted")
+	is.NoErr(err)
+	purged, err := syncerCmd("", "purge-expired")
+	is.NoErr(err)
+	remaining, err := syncerCmd("", "list", "-format", "json", "granted")
+	is.NoErr(err)
+
+	// Then
+	is.True(strings.Contains(table, "..2000-01-01T00:00:00Z"))
+	is.True(strings.Contains(table, "2000-01-01T00:00:00Z.."))
+	is.Equal(purged, "purged 1 expired grants\n")
+	var granted []syncer.Granted
+	is.NoErr(json.Unmarshal([]byte(remaining), &granted))
+	is.Equal(len(granted), 1)
+	is.Equal(granted[0].GrandScheme, "hive")
+}
+
+func TestCommand_grant_with_invalid_time(t *testing.T) {
+	is, syncerCmd := setupTestCommand(t)
+
+	_, err := syncerCmd("", "grant", "-locator", testLocator, "-expires-at", "tomorrow")
+
+	is.True(err != nil)
+}
+
+func TestCommand_find_granted_as_of(t *testing.T) {
+	// Given
+	is, syncerCmd := setupTestCommand(t)
+	_, err := syncerCmd("", "grant", "-locator", testLocator, "-scheme", "image")
+	is.NoErr(err)
+
+	// When
+	past, err := syncerCmd("", "find-granted", "-format", "json", "-as-of", "2000-01-01T00:00:00Z", "-locator", testLocator, "-scheme", "image")
+	is.NoErr(err)
+	_, invalidErr := syncerCmd("", "find-granted", "-as-of", "yesterday", "-locator", testLocator)
+
+	// Then
+	is.Equal(past, "[]\n") // The grant did not exist yet
+	is.True(invalidErr != nil)
+}
+
+func TestCommand_export_and_import(t *testing.T) {
+	// Given
+	is, sourceCmd := setupTestCommand(t)
+	_, targetCmd := setupTestCommand(t)
+	_, err := sourceCmd("", "grant", "-locator", testLocator, "-scheme", "image")
+	is.NoErr(err)
+	exported, err := sourceCmd("", "export", "-file-format", "yaml")
+	is.NoErr(err)
+
+	// When
+	dryRun, err := targetCmd(exported, "import", "-file-format", "yaml", "-dry-run", "-")
+	is.NoErr(err)
+	listedAfterDryRun, err := targetCmd("", "list", "-format", "json", "granted")
+	is.NoErr(err)
+	imported, err := targetCmd(exported, "import", "-file-format", "yaml", "-")
+	is.NoErr(err)
+	reimported, err := targetCmd(exported, "import", "-format", "json", "-file-format", "yaml", "-")
+	is.NoErr(err)
+
+	// Then
+	is.True(strings.Contains(dryRun, "would import: 1 created, 0 updated, 0 unchanged"))
+	is.Equal(listedAfterDryRun, "[]\n")
+	is.True(strings.Contains(imported, "imported: 1 created, 0 updated, 0 unchanged"))
+	var report syncer.ImportReport
+	is.NoErr(json.Unmarshal([]byte(reimported), &report))
+	is.Equal(report.Count(syncer.ImportUnchanged), 1)
+}
+
+func TestCommand_plan_apply_and_drift(t *testing.T) {
+	// Given
+	is, syncerCmd := setupTestCommand(t)
+	dir := t.TempDir()
+	is.NoErr(os.WriteFile(filepath.Join(dir, "cms.yaml"), []byte(`
+umbrella_organization: confetti-sites
+umbrella_repository: confetti-cms
+granted:
+  - ContainerName: image/container
+    scheme: image
+`), 0o644))
+
+	// When
+	plan, err := syncerCmd("", "plan", "-dir", dir)
+	is.NoErr(err)
+	applied, err := syncerCmd("", "apply", "-dir", dir, "-actor", "ci")
+	is.NoErr(err)
+	clean, err := syncerCmd("", "drift", "-dir", dir, "-format", "json")
+	is.NoErr(err)
+	_, err = syncerCmd("", "request", "-locator", testLocator, "-scheme", "image")
+	is.NoErr(err)
+	_, err = syncerCmd("", "grant", "-locator", "/image/extra?umbrella_organization=confetti-sites&umbrella_repository=confetti-cms", "-scheme", "image")
+	is.NoErr(err)
+	drift, driftErr := syncerCmd("", "drift", "-dir", dir)
+
+	// Then
+	is.True(strings.Contains(plan, "create  granted"))
+	is.True(strings.Contains(plan, "plan: 1 create, 0 update, 0 delete"))
+	is.True(strings.Contains(applied, "applied: 1 create, 0 update, 0 delete"))
+	is.Equal(clean, "[]\n")
+	is.True(driftErr != nil)
+	is.True(strings.Contains(drift, "unmanaged  granted"))
+	is.True(strings.Contains(drift, "unmanaged  requested")) // testLocator belongs to the same umbrella repository
+}
+
+func TestCommand_access(t *testing.T) {
+	// Given
+	is, syncerCmd := setupTestCommand(t)
+	_, err := syncerCmd("", "grant", "-locator", testLocator, "-scheme", "image", "-action", "pull")
+	is.NoErr(err)
+	_, err = syncerCmd("", "grant", "-locator", testLocator, "-scheme", "video")
+	is.NoErr(err)
+	_, err = syncerCmd("", "request", "-locator", testLocator, "-scheme", "image", "-destination-path", "/images")
+	is.NoErr(err)
+
+	// When
+	table, err := syncerCmd("", "access", "-container", "image/container", "-target", "cmd")
+	is.NoErr(err)
+	output, err := syncerCmd("", "access", "-format", "json", "-container", "image/container", "-target", "cmd")
+	is.NoErr(err)
+	csv, err := syncerCmd("", "access", "-format", "csv", "-container", "image/container", "-target", "cmd")
+	is.NoErr(err)
+
+	// Then
+	is.True(strings.Contains(table, "/images"))
+	is.True(strings.HasSuffix(table, "image/container (target cmd): 2 grants, 1 requested permissions satisfied\n"))
+	var report syncer.AccessReport
+	is.NoErr(json.Unmarshal([]byte(output), &report))
+	is.Equal(len(report.Granted), 2)
+	is.Equal(len(report.Access), 1)
+	is.Equal(report.Access[0].Granted[0].GrandScheme, "image")
+	is.Equal(len(strings.Split(strings.TrimSpace(csv), "\n")), 3) // The header, the request with its grant and the unused grant
+}
+
+func TestCommand_unsatisfied(t *testing.T) {
+	// Given
+	is, syncerCmd := setupTestCommand(t)
+	_, err := syncerCmd("", "grant", "-locator", testLocator, "-scheme", "image", "-action", "pull")
+	is.NoErr(err)
+	_, err = syncerCmd("", "request", "-locator", testLocator, "-scheme", "image", "-action", "pull")
+	is.NoErr(err)
+	satisfied, satisfiedErr := syncerCmd("", "unsatisfied", "-format", "json")
+	_, err = syncerCmd("", "request", "-locator", testLocator, "-scheme", "image", "-action", "push")
+	is.NoErr(err)
+
+	// When
+	table, err := syncerCmd("", "unsatisfied")
+
+	// Then
+	is.NoErr(satisfiedErr)
+	is.Equal(satisfied, "[]\n")
+	is.True(err != nil) // Unsatisfied requested permissions fail the command
+	is.True(strings.Contains(table, "umbrella confetti-sites/confetti-cms"))
+	is.True(strings.Contains(table, "image pull on image/container  action (value mismatch)"))
+}
+
+func TestCommand_unused(t *testing.T) {
+	// Given
+	is, syncerCmd := setupTestCommand(t)
+	_, err := syncerCmd("", "grant", "-locator", testLocator, "-scheme", "image", "-action", "pull")
+	is.NoErr(err)
+	_, err = syncerCmd("", "grant", "-locator", testLocator, "-scheme", "video", "-description", "old export")
+	is.NoErr(err)
+	_, err = syncerCmd("", "request", "-locator", testLocator, "-scheme", "image", "-action", "pull")
+	is.NoErr(err)
+	decisionLog := filepath.Join(t.TempDir(), "decisions.jsonl")
+	is.NoErr(os.WriteFile(decisionLog, nil, 0o644))
+
+	// When
+	table, err := syncerCmd("", "unused")
+	is.NoErr(err)
+	output, err := syncerCmd("", "unused", "-format", "json", "-decisions", decisionLog, "-window", "24h")
+	is.NoErr(err)
+
+	// Then
+	is.True(strings.Contains(table, "old export"))
+	is.True(!strings.Contains(table, "pull"))
+	var unused []syncer.UnusedGrant
+	is.NoErr(json.Unmarshal([]byte(output), &unused))
+	is.Equal(len(unused), 1)
+	is.Equal(unused[0].Granted.GrandScheme, "video")
+	is.True(!unused[0].DecisionsSince.IsZero())
+}
+
+func TestCommand_lint(t *testing.T) {
+	// Given
+	is, syncerCmd := setupTestCommand(t)
+	_, err := syncerCmd("", "grant", "-locator", testLocator, "-scheme", "image", "-action", "pull")
+	is.NoErr(err)
+	clean, cleanErr := syncerCmd("", "lint", "-format", "json")
+	_, err = syncerCmd("", "grant", "-locator", testLocator, "-scheme", "*", "-action", "push")
+	is.NoErr(err)
+
+	// When
+	table, err := syncerCmd("", "lint")
+
+	// Then
+	is.NoErr(cleanErr)
+	is.Equal(clean, "[]\n")
+	is.True(err != nil) // Lint errors fail the command
+	is.True(strings.Contains(table, "error     wildcard-scheme-write"))
+}
+
+func TestCommand_redundant(t *testing.T) {
+	// Given
+	is, syncerCmd := setupTestCommand(t)
+	_, err := syncerCmd("", "grant", "-locator", testLocator, "-scheme", "image", "-action", "pull")
+	is.NoErr(err)
+	_, err = syncerCmd("", "grant", "-locator", testLocator, "-scheme", "image", "-action", "*")
+	is.NoErr(err)
+
+	// When
+	output, err := syncerCmd("", "redundant", "-format", "json")
+
+	// Then
+	is.NoErr(err)
+	var redundancies []syncer.Redundancy
+	is.NoErr(json.Unmarshal([]byte(output), &redundancies))
+	is.Equal(len(redundancies), 1)
+	is.Equal(redundancies[0].Granted.GrandAction, "pull")
+	is.Equal(redundancies[0].CoveredBy[0].Broader, []syncer.Dimension{syncer.DimensionAction})
+}
+
+func TestCommand_role(t *testing.T) {
+	// Given
+	is, syncerCmd := setupTestCommand(t)
+	role := `{"name": "image-pull", "grants": [{"ContainerName": "{container}", "scheme": "image", "action": "pull"}]}`
+	_, err := syncerCmd("", "role", "-json", "-", "save")
+	is.True(err != nil) // Nothing on stdin
+	_, err = syncerCmd(role, "role", "-json", "-", "save")
+	is.NoErr(err)
+
+	// When
+	assigned, err := syncerCmd("", "role", "-format", "json", "assign", "image-pull", "container=cms/uploads")
+	is.NoErr(err)
+	assignments, err := syncerCmd("", "role", "assignments")
+	is.NoErr(err)
+	_, err = syncerCmd("", "role", "revoke", "image-pull", "container=cms/uploads")
+	is.NoErr(err)
+	remaining, err := syncerCmd("", "list", "-format", "json", "granted")
+	is.NoErr(err)
+
+	// Then
+	var granted []syncer.Granted
+	is.NoErr(json.Unmarshal([]byte(assigned), &granted))
+	is.Equal(granted[0].ContainerName, "cms/uploads")
+	is.True(strings.Contains(assignments, "container=cms/uploads"))
+	is.Equal(remaining, "[]\n")
+}
+
+func TestCommand_parent(t *testing.T) {
+	// Given
+	is, syncerCmd := setupTestCommand(t)
+	_, err := syncerCmd("", "grant", "-json", `{"ContainerName": "image/container", "SourceOrganization": "confetti-cms", "SourceRepository": "cms", "scheme": "image", "action": "pull"}`)
+	is.NoErr(err)
+
+	// When
+	_, err = syncerCmd("", "parent", "set", "confetti-cms/image", "confetti-cms/cms")
+	is.NoErr(err)
+	parents, err := syncerCmd("", "parent", "list")
+	is.NoErr(err)
+	found, err := syncerCmd("", "find-granted", "-format", "json", "-json",
+		`{"ContainerName": "image/container", "SourceOrganization": "confetti-cms", "SourceRepository": "image", "scheme": "image", "action": "pull"}`)
+	is.NoErr(err)
+	_, err = syncerCmd("", "parent", "remove", "confetti-cms/image")
+	is.NoErr(err)
+	removed, err := syncerCmd("", "parent", "-format", "json", "list")
+	is.NoErr(err)
+
+	// Then
+	is.True(strings.Contains(parents, "confetti-cms/image  confetti-cms/cms"))
+	var granted []syncer.Granted
+	is.NoErr(json.Unmarshal([]byte(found), &granted))
+	is.Equal(len(granted), 1)
+	is.Equal(granted[0].SourceRepository, "cms")
+	is.Equal(removed, "[]\n")
+}
+
+func TestCommand_action(t *testing.T) {
+	// Given
+	is, syncerCmd := setupTestCommand(t)
+	_, err := syncerCmd("", "grant", "-json", `{"ContainerName": "image/container", "scheme": "image", "action": "admin"}`)
+	is.NoErr(err)
+
+	// When
+	_, err = syncerCmd("", "action", "add", "image", "admin", "push", "pull")
+	is.NoErr(err)
+	implications, err := syncerCmd("", "action", "list")
+	is.NoErr(err)
+	found, err := syncerCmd("", "find-granted", "-format", "json", "-json", `{"ContainerName": "image/container", "scheme": "image", "action": "pull"}`)
+	is.NoErr(err)
+	explained, err := syncerCmd("", "explain", "-matched", "-json", `{"ContainerName": "image/container", "scheme": "image", "action": "pull"}`)
+	is.NoErr(err)
+	_, err = syncerCmd("", "action", "remove", "image", "admin", "push", "pull")
+	is.NoErr(err)
+	removed, err := syncerCmd("", "action", "-format", "json", "list")
+	is.NoErr(err)
+
+	// Then
+	is.True(strings.Contains(implications, "image   admin   push"))
+	is.True(strings.Contains(implications, "image   push    pull"))
+	var granted []syncer.Granted
+	is.NoErr(json.Unmarshal([]byte(found), &granted))
+	is.Equal(len(granted), 1)
+	is.True(strings.Contains(explained, syncer.ReasonImpliedAction))
+	is.Equal(removed, "[]\n")
+}
+
+func TestCommand_mounts(t *testing.T) {
+	// Given
+	is, syncerCmd := setupTestCommand(t)
+	_, err := syncerCmd("", "grant", "-json", `[{"Host": "assets", "ContainerName": "cms/uploads", "scheme": "hive", "action": "sync", "expose_path": "/public"},
+		{"Host": "mirror", "ContainerName": "cms/uploads", "scheme": "hive", "action": "sync", "expose_path": "/public/images"}]`)
+	is.NoErr(err)
+	_, err = syncerCmd("", "request", "-json", `[{"ContainerName": "cms/uploads", "scheme": "hive", "action": "sync", "destination_path": "/public/logo.png"}]`)
+	is.NoErr(err)
+
+	// When
+	stored, err := syncerCmd("", "mounts", "-stored")
+	is.NoErr(err)
+	conflicting, conflictErr := syncerCmd("", "mounts", "-json", `{"ContainerName": "cms/uploads", "scheme": "hive", "action": "sync", "destination_path": "/public/images"}`)
+
+	// Then
+	is.True(strings.Contains(stored, "assets       /public      /public/logo.png  /public/logo.png"))
+	is.True(conflictErr != nil)
+	is.True(strings.Contains(conflicting, "conflict cms/uploads target - at /public/images from assets:/public/images, mirror:/public/images"))
+}
+
+func TestCommand_overlaps(t *testing.T) {
+	// Given
+	is, syncerCmd := setupTestCommand(t)
+	empty, err := syncerCmd("", "overlaps", "-format", "json")
+	is.NoErr(err)
+	_, err = syncerCmd("", "request", "-json", `[{"ContainerName": "cms/uploads", "scheme": "hive", "destination_path": "/public"},
+		{"ContainerName": "cms/uploads", "scheme": "image", "destination_path": "/public/images"}]`)
+	is.NoErr(err)
+
+	// When
+	table, err := syncerCmd("", "overlaps")
+
+	// Then
+	is.Equal(empty, "[]\n")
+	is.True(err != nil)
+	is.True(strings.Contains(table, "cms/uploads  -       /public      hive    /public/images  image   nested"))
+}
+
+func TestCommand_syncPlan(t *testing.T) {
+	// Given
+	is, syncerCmd := setupTestCommand(t)
+	_, err := syncerCmd("", "grant", "-json", `[{"Host": "assets", "ContainerName": "cms/uploads", "Target": "web", "scheme": "hive", "action": "sync", "expose_path": "/public"}]`)
+	is.NoErr(err)
+	_, err = syncerCmd("", "request", "-json", `[{"ContainerName": "cms/uploads", "Target": "web", "scheme": "hive", "action": "sync", "destination_path": "/public/css"},
+		{"ContainerName": "cms/uploads", "Target": "web", "scheme": "hive", "action": "sync", "destination_path": "/public"}]`)
+	is.NoErr(err)
+	_, err = syncerCmd("", "request", "-json", `{"ContainerName": "cms/uploads", "Target": "cmd", "scheme": "hive", "action": "sync", "destination_path": "/private"}`)
+	is.NoErr(err)
+
+	// When
+	planned, err := syncerCmd("", "sync-plan", "-container", "cms/uploads", "-target", "web")
+	is.NoErr(err)
+	rejected, rejectedErr := syncerCmd("", "sync-plan", "-container", "cms/uploads", "-target", "cmd")
+
+	// Then
+	is.True(strings.Index(planned, "/public      assets") < strings.Index(planned, "/public/css  assets")) // Parents first
+	is.True(rejectedErr != nil)
+	is.True(strings.Contains(rejected, "rejected hive sync at /private from -: no active grant satisfies the request"))
+}
@@ -0,0 +1,532 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/confetti-cms/syncer"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+func printJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(value)
+}
+
+func printGranted(w io.Writer, format string, granted []syncer.Granted) error {
+	if format == formatJSON {
+		if granted == nil {
+			granted = []syncer.Granted{}
+		}
+		return printJSON(w, granted)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tCONTAINER\tTARGET\tSCHEME\tACTION\tSOURCE\tUMBRELLA\tEXPOSE PATH\tACTIVE\tDESCRIPTION")
+	for _, g := range granted {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			cell(g.Host),
+			field(g.ContainerName, g.GrandContainerName),
+			field(g.Target, g.GrandTarget),
+			cell(g.GrandScheme),
+			cell(g.GrandAction),
+			owner(field(g.SourceOrganization, g.GrandSourceOrganization), field(g.SourceRepository, g.GrandSourceRepository)),
+			owner(field(g.UmbrellaOrganization, g.GrandUmbrellaOrganization), field(g.UmbrellaRepository, g.GrandUmbrellaRepository)),
+			cell(g.ExposePath),
+			timeWindow(g.NotBefore, g.ExpiresAt),
+			cell(g.Description),
+		)
+	}
+
+	return tw.Flush()
+}
+
+func printRequested(w io.Writer, format string, requested []syncer.Requested) error {
+	if format == formatJSON {
+		if requested == nil {
+			requested = []syncer.Requested{}
+		}
+		return printJSON(w, requested)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tCONTAINER\tTARGET\tSCHEME\tACTION\tSOURCE\tUMBRELLA\tDESTINATION PATH\tDESCRIPTION")
+	for _, r := range requested {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			cell(r.Host),
+			field(r.ContainerName, r.RequestContainerName),
+			field(r.Target, r.RequestTarget),
+			cell(r.RequestScheme),
+			cell(r.RequestAction),
+			owner(field(r.SourceOrganization, r.RequestSourceOrganization), field(r.SourceRepository, r.RequestSourceRepository)),
+			owner(field(r.UmbrellaOrganization, r.RequestUmbrellaOrganization), field(r.UmbrellaRepository, r.RequestUmbrellaRepository)),
+			cell(r.DestinationPath),
+			cell(r.Description),
+		)
+	}
+
+	return tw.Flush()
+}
+
+func printExplanations(w io.Writer, format string, explanations []explanation) error {
+	if format == formatJSON {
+		return printJSON(w, explanations)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, e := range explanations {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		verdict := "no match"
+		if e.Matched {
+			verdict = "match"
+		}
+		fmt.Fprintf(tw, "requested %s (target %s) against granted %s (target %s): %s\n",
+			cell(e.Requested.ContainerName), cell(e.Requested.Target),
+			cell(e.Granted.ContainerName), cell(e.Granted.Target), verdict)
+		fmt.Fprintln(tw, "DIMENSION\tREQUESTED\tGRANTED\tRESULT")
+		for _, d := range e.Dimensions {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
+				d.Dimension,
+				field(d.RequestedResource, d.Requested),
+				field(d.GrantedResource, d.Granted),
+				d.Reason,
+			)
+		}
+	}
+
+	return tw.Flush()
+}
+
+func printImportReport(w io.Writer, format string, report syncer.ImportReport) error {
+	if format == formatJSON {
+		return printJSON(w, report)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHANGE\tKIND\tHOST\tCONTAINER\tTARGET\tSCHEME\tACTION\tDESCRIPTION")
+	for _, change := range report.Changes {
+		var host, container, target, scheme, action, description string
+		if r := change.Requested; r != nil {
+			host, container, target, scheme, action, description = r.Host, field(r.ContainerName, r.RequestContainerName), field(r.Target, r.RequestTarget), r.RequestScheme, r.RequestAction, r.Description
+		}
+		if g := change.Granted; g != nil {
+			host, container, target, scheme, action, description = g.Host, field(g.ContainerName, g.GrandContainerName), field(g.Target, g.GrandTarget), g.GrandScheme, g.GrandAction, g.Description
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			change.Action, change.Kind, cell(host), container, target, cell(scheme), cell(action), cell(description))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	verb := "imported"
+	if report.DryRun {
+		verb = "would import"
+	}
+	_, err := fmt.Fprintf(w, "%s: %d created, %d updated, %d unchanged\n", verb,
+		report.Count(syncer.ImportCreate), report.Count(syncer.ImportUpdate), report.Count(syncer.ImportUnchanged))
+
+	return err
+}
+
+func printPlan(w io.Writer, format string, plan syncer.Plan, applied bool) error {
+	if format == formatJSON {
+		return printJSON(w, plan)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHANGE\tKIND\tFILE\tHOST\tCONTAINER\tTARGET\tSCHEME\tACTION\tDESCRIPTION")
+	for _, change := range plan.Changes {
+		var host, container, target, scheme, action, description string
+		r := change.AfterRequested
+		if r == nil {
+			r = change.BeforeRequested
+		}
+		if r != nil {
+			host, container, target, scheme, action, description = r.Host, field(r.ContainerName, r.RequestContainerName), field(r.Target, r.RequestTarget), r.RequestScheme, r.RequestAction, r.Description
+		}
+		g := change.AfterGranted
+		if g == nil {
+			g = change.BeforeGranted
+		}
+		if g != nil {
+			host, container, target, scheme, action, description = g.Host, field(g.ContainerName, g.GrandContainerName), field(g.Target, g.GrandTarget), g.GrandScheme, g.GrandAction, g.Description
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			change.Action, change.Kind, change.File, cell(host), container, target, cell(scheme), cell(action), cell(description))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	summary := "plan"
+	if applied {
+		summary = "applied"
+	}
+	_, err := fmt.Fprintf(w, "%s: %d create, %d update, %d delete\n", summary,
+		plan.Count(syncer.PlanCreate), plan.Count(syncer.PlanUpdate), plan.Count(syncer.PlanDelete))
+
+	return err
+}
+
+func printDrift(w io.Writer, format string, drift []syncer.Drift) error {
+	if format == formatJSON {
+		return printJSON(w, drift)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATE\tKIND\tFILE\tLOCATOR")
+	for _, d := range drift {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", d.State, d.Kind, d.File, d.Locator)
+	}
+
+	return tw.Flush()
+}
+
+func printAccessReport(w io.Writer, format string, report syncer.AccessReport) error {
+	switch format {
+	case formatJSON:
+		return printJSON(w, report)
+	case formatCSV:
+		return report.WriteCSV(w)
+	}
+
+	used := map[syncer.Granted]bool{}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "REQUESTED HOST\tSCHEME\tACTION\tDESTINATION PATH\tGRANTED HOST\tSCHEME\tACTION\tEXPOSE PATH\tACTIVE")
+	for _, access := range report.Access {
+		r := access.Requested
+		for _, g := range access.Granted {
+			used[g] = true
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+				cell(r.Host), cell(r.RequestScheme), cell(r.RequestAction), cell(r.DestinationPath), accessGrant(g))
+		}
+	}
+	for _, g := range report.Granted {
+		if !used[g] {
+			fmt.Fprintf(tw, "-\t-\t-\t-\t%s\n", accessGrant(g))
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "%s (target %s): %d grants, %d requested permissions satisfied\n",
+		report.ContainerName, cell(report.Target), len(report.Granted), len(report.Access))
+
+	return err
+}
+
+func printUnsatisfied(w io.Writer, format string, groups []syncer.UnsatisfiedGroup) error {
+	if format == formatJSON {
+		return printJSON(w, groups)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, group := range groups {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		fmt.Fprintf(tw, "umbrella %s\n", owner(cell(group.UmbrellaOrganization), cell(group.UmbrellaRepository)))
+		fmt.Fprintln(tw, "HOST\tCONTAINER\tTARGET\tSCHEME\tACTION\tCLOSEST GRANT\tDIFFERENCES")
+		for _, u := range group.Unsatisfied {
+			r := u.Requested
+			requested := fmt.Sprintf("%s\t%s\t%s\t%s\t%s", cell(r.Host), field(r.ContainerName, r.RequestContainerName),
+				field(r.Target, r.RequestTarget), cell(r.RequestScheme), cell(r.RequestAction))
+			if len(u.Closest) == 0 {
+				fmt.Fprintf(tw, "%s\t-\t-\n", requested)
+			}
+			for _, c := range u.Closest {
+				g := c.Granted
+				fmt.Fprintf(tw, "%s\t%s %s on %s\t%s\n", requested, cell(g.GrandScheme), cell(g.GrandAction),
+					field(g.ContainerName, g.GrandContainerName), differences(c))
+			}
+		}
+	}
+
+	return tw.Flush()
+}
+
+func printUnused(w io.Writer, format string, unused []syncer.UnusedGrant) error {
+	if format == formatJSON {
+		return printJSON(w, unused)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tCONTAINER\tTARGET\tSCHEME\tACTION\tACTIVE\tLAST MATCHED\tDESCRIPTION")
+	for _, u := range unused {
+		g := u.Granted
+		active := timeWindow(g.NotBefore, g.ExpiresAt)
+		if !u.Active {
+			active = "expired " + active
+		}
+		lastMatched := "-"
+		if !u.LastMatched.IsZero() {
+			lastMatched = u.LastMatched.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			cell(g.Host), field(g.ContainerName, g.GrandContainerName), field(g.Target, g.GrandTarget),
+			cell(g.GrandScheme), cell(g.GrandAction), active, lastMatched, cell(g.Description))
+	}
+
+	return tw.Flush()
+}
+
+func printLintFindings(w io.Writer, format string, findings []syncer.LintFinding) error {
+	if format == formatJSON {
+		return printJSON(w, findings)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SEVERITY\tRULE\tHOST\tCONTAINER\tTARGET\tSCHEME\tACTION\tMESSAGE")
+	for _, finding := range findings {
+		g := finding.Granted
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			finding.Severity, finding.Rule, cell(g.Host), field(g.ContainerName, g.GrandContainerName),
+			field(g.Target, g.GrandTarget), cell(g.GrandScheme), cell(g.GrandAction), finding.Message)
+	}
+
+	return tw.Flush()
+}
+
+func printRedundancies(w io.Writer, format string, redundancies []syncer.Redundancy) error {
+	if format == formatJSON {
+		return printJSON(w, redundancies)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tCONTAINER\tTARGET\tSCHEME\tACTION\tCOVERED BY\tBROADER")
+	for _, r := range redundancies {
+		g := r.Granted
+		for _, c := range r.CoveredBy {
+			broader := make([]string, 0, len(c.Broader))
+			for _, d := range c.Broader {
+				broader = append(broader, string(d))
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s %s\t%s\n",
+				cell(g.Host), field(g.ContainerName, g.GrandContainerName), field(g.Target, g.GrandTarget),
+				cell(g.GrandScheme), cell(g.GrandAction), cell(c.Granted.GrandScheme), cell(c.Granted.GrandAction),
+				cell(strings.Join(broader, ", ")))
+		}
+	}
+
+	return tw.Flush()
+}
+
+func printMounts(w io.Writer, format string, resolution syncer.MountResolution) error {
+	if format == formatJSON {
+		return printJSON(w, resolution)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONTAINER\tTARGET\tSOURCE HOST\tEXPOSE PATH\tSOURCE PATH\tDESTINATION PATH")
+	for _, rm := range resolution.Resolved {
+		r := rm.Requested
+		for _, m := range rm.Mounts {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", field(r.ContainerName, r.RequestContainerName), field(r.Target, r.RequestTarget),
+				cell(m.SourceHost), cell(m.ExposePath), m.SourcePath, m.DestinationPath)
+		}
+	}
+	for _, s := range resolution.Skipped {
+		fmt.Fprintf(tw, "skipped %s %s on %s: %s\n", cell(s.Granted.GrandScheme), cell(s.Granted.GrandAction),
+			field(s.Granted.ContainerName, s.Granted.GrandContainerName), s.Reason)
+	}
+	for _, c := range resolution.Conflicts {
+		hosts := make([]string, 0, len(c.Mounts))
+		for _, m := range c.Mounts {
+			hosts = append(hosts, cell(m.SourceHost)+":"+m.SourcePath)
+		}
+		fmt.Fprintf(tw, "conflict %s target %s at %s from %s\n", cell(c.ContainerName), cell(c.Target), c.DestinationPath, strings.Join(hosts, ", "))
+	}
+
+	return tw.Flush()
+}
+
+func printSyncPlan(w io.Writer, format string, plan syncer.SyncPlan) error {
+	if format == formatJSON {
+		return printJSON(w, plan)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DESTINATION\tSOURCE HOST\tSOURCE CONTAINER\tSOURCE PATH\tSCHEME\tACTION")
+	for _, o := range plan.Operations {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", o.DestinationPath, cell(o.SourceHost), cell(o.SourceContainer),
+			o.SourcePath, o.Scheme, o.Action)
+	}
+	for _, r := range plan.Rejected {
+		source := "-"
+		if r.Granted != nil {
+			source = cell(r.Granted.Host) + ":" + cell(r.Granted.ExposePath)
+		}
+		fmt.Fprintf(tw, "rejected %s %s at %s from %s: %s\n", cell(r.Requested.RequestScheme), cell(r.Requested.RequestAction),
+			cell(r.Requested.DestinationPath), source, r.Reason)
+	}
+
+	return tw.Flush()
+}
+
+func printOverlaps(w io.Writer, format string, overlaps []syncer.DestinationOverlap) error {
+	if format == formatJSON {
+		return printJSON(w, overlaps)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONTAINER\tTARGET\tDESTINATION\tSCHEME\tOVERLAPS WITH\tSCHEME\tKIND")
+	for _, o := range overlaps {
+		kind := "same"
+		if o.Nested {
+			kind = "nested"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", cell(o.ContainerName), cell(o.Target),
+			o.First.DestinationPath, cell(o.First.RequestScheme), o.Second.DestinationPath, cell(o.Second.RequestScheme), kind)
+	}
+
+	return tw.Flush()
+}
+
+func printRoles(w io.Writer, format string, roles []syncer.Role) error {
+	if format == formatJSON {
+		return printJSON(w, roles)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ROLE\tCONTAINER\tTARGET\tSCHEME\tACTION\tDESCRIPTION")
+	for _, role := range roles {
+		for _, g := range role.Grants {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", role.Name, field(g.ContainerName, g.GrandContainerName),
+				field(g.Target, g.GrandTarget), cell(g.GrandScheme), cell(g.GrandAction), cell(g.Description))
+		}
+	}
+
+	return tw.Flush()
+}
+
+func printParents(w io.Writer, format string, parents []syncer.OwnerParent) error {
+	if format == formatJSON {
+		return printJSON(w, parents)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "OWNER\tPARENT")
+	for _, p := range parents {
+		fmt.Fprintf(tw, "%s\t%s\n", p.Child, p.Parent)
+	}
+
+	return tw.Flush()
+}
+
+func printImpliedActions(w io.Writer, format string, implications []syncer.ActionImplication) error {
+	if format == formatJSON {
+		return printJSON(w, implications)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SCHEME\tACTION\tIMPLIES")
+	for _, i := range implications {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", i.Scheme, i.Action, i.Implies)
+	}
+
+	return tw.Flush()
+}
+
+func printAssignments(w io.Writer, format string, assignments []syncer.Assignment) error {
+	if format == formatJSON {
+		return printJSON(w, assignments)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ROLE\tSUBJECT\tGRANTS")
+	for _, a := range assignments {
+		keys := make([]string, 0, len(a.Subject))
+		for key := range a.Subject {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		values := make([]string, 0, len(keys))
+		for _, key := range keys {
+			values = append(values, key+"="+a.Subject[key])
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", a.Role, cell(strings.Join(values, " ")), len(a.Granted))
+	}
+
+	return tw.Flush()
+}
+
+// differences lists the dimensions in which a grant differs from a requested permission
+func differences(c syncer.ClosestGrant) string {
+	var parts []string
+	for _, d := range c.Differences {
+		parts = append(parts, fmt.Sprintf("%s (%s)", d.Dimension, d.Reason))
+	}
+	if !c.Active {
+		parts = append(parts, "not active")
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// accessGrant are the grant columns of the access table
+func accessGrant(g syncer.Granted) string {
+	return strings.Join([]string{cell(g.Host), cell(g.GrandScheme), cell(g.GrandAction), cell(g.ExposePath), timeWindow(g.NotBefore, g.ExpiresAt)}, "\t")
+}
+
+// field shows a resource together with the value that is requested or granted on it
+func field(resource, value string) string {
+	switch {
+	case value == "" || value == resource:
+		return cell(resource)
+	case resource == "":
+		return value
+	default:
+		return resource + " (" + value + ")"
+	}
+}
+
+// owner joins an organization and a repository
+func owner(organization, repository string) string {
+	if organization == "-" && repository == "-" {
+		return "-"
+	}
+
+	return strings.Join([]string{organization, repository}, "/")
+}
+
+// timeWindow shows the time a grant is active
+func timeWindow(notBefore, expiresAt time.Time) string {
+	if notBefore.IsZero() && expiresAt.IsZero() {
+		return "always"
+	}
+
+	from, until := "", ""
+	if !notBefore.IsZero() {
+		from = notBefore.Format(time.RFC3339)
+	}
+	if !expiresAt.IsZero() {
+		until = expiresAt.Format(time.RFC3339)
+	}
+
+	return from + ".." + until
+}
+
+// cell replaces empty values so the columns stay aligned
+func cell(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
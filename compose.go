@@ -0,0 +1,270 @@
+package syncer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Compose labels declare the permissions of a service in a docker-compose file:
+//
+//	labels:
+//	  confetti.request.<name>.locator: //host/image/container?target=cmd
+//	  confetti.request.<name>.scheme: image
+//	  confetti.grant.<name>.locator: /image/container
+//	  confetti.grant.<name>.action: pull
+//
+// The name groups the labels of one record. Every record needs a locator, the
+// other fields are scheme, action, description and path (the destination path
+// of a request or the expose path of a grant). Grants also accept not_before
+// and expires_at as RFC 3339 times.
+const (
+	composeRequestPrefix = "confetti.request."
+	composeGrantPrefix   = "confetti.grant."
+)
+
+// ComposeError is an invalid permission label, with its position in the compose file
+type ComposeError struct {
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ComposeError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %v", e.File, e.Line, e.Column, e.Err)
+}
+
+func (e *ComposeError) Unwrap() error {
+	return e.Err
+}
+
+// ParseComposeFile reads the permission labels of the services in a docker-compose file
+func ParseComposeFile(path string) (Document, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Document{}, fmt.Errorf("failed to open compose file: %w", err)
+	}
+	defer file.Close()
+
+	return ParseCompose(file, path)
+}
+
+// ParseCompose reads the permission labels of the services in a docker-compose
+// file, name is used in the positions of errors
+func ParseCompose(r io.Reader, name string) (Document, error) {
+	document := Document{Requested: []Requested{}, Granted: []Granted{}}
+
+	var root yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&root); err != nil {
+		if err == io.EOF {
+			return document, nil
+		}
+		return document, fmt.Errorf("%s: invalid compose file: %w", name, err)
+	}
+	if len(root.Content) == 0 {
+		return document, nil
+	}
+
+	services := mappingValue(root.Content[0], "services")
+	if services == nil {
+		return document, nil
+	}
+	if services.Kind != yaml.MappingNode {
+		return document, composeError(name, services, fmt.Errorf("services must be a mapping"))
+	}
+
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		labels := mappingValue(services.Content[i+1], "labels")
+		if labels == nil {
+			continue
+		}
+		entries, err := composeLabels(name, labels)
+		if err != nil {
+			return document, err
+		}
+		requested, granted, err := composeRecords(name, entries)
+		if err != nil {
+			return document, err
+		}
+		document.Requested = append(document.Requested, requested...)
+		document.Granted = append(document.Granted, granted...)
+	}
+
+	return document, nil
+}
+
+// composeLabel is one label with the node of its value
+type composeLabel struct {
+	key   string
+	value string
+	node  *yaml.Node
+}
+
+// composeLabels reads labels in the mapping form or the list form (key=value)
+func composeLabels(name string, labels *yaml.Node) ([]composeLabel, error) {
+	var entries []composeLabel
+
+	switch labels.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(labels.Content); i += 2 {
+			entries = append(entries, composeLabel{key: labels.Content[i].Value, value: labels.Content[i+1].Value, node: labels.Content[i+1]})
+		}
+	case yaml.SequenceNode:
+		for _, item := range labels.Content {
+			key, value, _ := strings.Cut(item.Value, "=")
+			entries = append(entries, composeLabel{key: key, value: value, node: item})
+		}
+	default:
+		return nil, composeError(name, labels, fmt.Errorf("labels must be a mapping or a list"))
+	}
+
+	return entries, nil
+}
+
+// composeFields are the labels of one record by field name
+type composeFields struct {
+	first  *yaml.Node
+	values map[string]composeLabel
+}
+
+// composeRecords turns the permission labels of one service into records, in the order they first appear
+func composeRecords(name string, labels []composeLabel) ([]Requested, []Granted, error) {
+	requests, requestNames, err := groupComposeLabels(name, labels, composeRequestPrefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	grants, grantNames, err := groupComposeLabels(name, labels, composeGrantPrefix)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var requested []Requested
+	for _, recordName := range requestNames {
+		fields := requests[recordName]
+		locator, err := fields.require(name, composeRequestPrefix+recordName)
+		if err != nil {
+			return nil, nil, err
+		}
+		r, err := FillRequestedByLocator(locator.value, Requested{
+			RequestScheme:   fields.values["scheme"].value,
+			RequestAction:   fields.values["action"].value,
+			Description:     fields.values["description"].value,
+			DestinationPath: fields.values["path"].value,
+		})
+		if err != nil {
+			return nil, nil, composeError(name, locator.node, fmt.Errorf("%s: %w", locator.key, err))
+		}
+		if err := ValidateRequested(r); err != nil {
+			return nil, nil, composeError(name, fields.first, fmt.Errorf("%s: %w", composeRequestPrefix+recordName, err))
+		}
+		requested = append(requested, r)
+	}
+
+	var granted []Granted
+	for _, recordName := range grantNames {
+		fields := grants[recordName]
+		locator, err := fields.require(name, composeGrantPrefix+recordName)
+		if err != nil {
+			return nil, nil, err
+		}
+		g := Granted{
+			GrandScheme: fields.values["scheme"].value,
+			GrandAction: fields.values["action"].value,
+			Description: fields.values["description"].value,
+			ExposePath:  fields.values["path"].value,
+		}
+		for _, window := range []struct {
+			field  string
+			target *time.Time
+		}{{"not_before", &g.NotBefore}, {"expires_at", &g.ExpiresAt}} {
+			label, ok := fields.values[window.field]
+			if !ok {
+				continue
+			}
+			if *window.target, err = time.Parse(time.RFC3339, label.value); err != nil {
+				return nil, nil, composeError(name, label.node, fmt.Errorf("%s: %w", label.key, err))
+			}
+		}
+		g, err = FillGrantedByLocator(locator.value, g)
+		if err != nil {
+			return nil, nil, composeError(name, locator.node, fmt.Errorf("%s: %w", locator.key, err))
+		}
+		if err := ValidateGranted(g); err != nil {
+			return nil, nil, composeError(name, fields.first, fmt.Errorf("%s: %w", composeGrantPrefix+recordName, err))
+		}
+		granted = append(granted, g)
+	}
+
+	return requested, granted, nil
+}
+
+// groupComposeLabels collects the labels with the prefix by record name
+func groupComposeLabels(name string, labels []composeLabel, prefix string) (map[string]*composeFields, []string, error) {
+	allowed := map[string]bool{"locator": true, "scheme": true, "action": true, "description": true, "path": true}
+	if prefix == composeGrantPrefix {
+		allowed["not_before"] = true
+		allowed["expires_at"] = true
+	}
+
+	records := map[string]*composeFields{}
+	var names []string
+	for _, label := range labels {
+		rest, ok := strings.CutPrefix(label.key, prefix)
+		if !ok {
+			continue
+		}
+		recordName, field, ok := strings.Cut(rest, ".")
+		if !ok || recordName == "" {
+			return nil, nil, composeError(name, label.node, fmt.Errorf("label %s must be %s<name>.<field>", label.key, prefix))
+		}
+		if !allowed[field] {
+			return nil, nil, composeError(name, label.node, fmt.Errorf("label %s has an unknown field %q", label.key, field))
+		}
+
+		fields, ok := records[recordName]
+		if !ok {
+			fields = &composeFields{first: label.node, values: map[string]composeLabel{}}
+			records[recordName] = fields
+			names = append(names, recordName)
+		}
+		if _, ok := fields.values[field]; ok {
+			return nil, nil, composeError(name, label.node, fmt.Errorf("label %s is set twice", label.key))
+		}
+		fields.values[field] = label
+	}
+
+	return records, names, nil
+}
+
+// require returns the locator label, which every record needs
+func (f *composeFields) require(name, record string) (composeLabel, error) {
+	locator, ok := f.values["locator"]
+	if !ok || locator.value == "" {
+		return locator, composeError(name, f.first, fmt.Errorf("%s.locator is required", record))
+	}
+
+	return locator, nil
+}
+
+// mappingValue returns the value of a key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func composeError(name string, node *yaml.Node, err error) error {
+	return &ComposeError{File: name, Line: node.Line, Column: node.Column, Err: err}
+}
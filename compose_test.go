@@ -0,0 +1,176 @@
+package syncer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testCompose = `services:
+  cms:
+    image: confetti-cms/cms
+    labels:
+      confetti.request.images.locator: //Host/image/container?target=cmd&source_organization=Confetti-CMS
+      confetti.request.images.scheme: image
+      confetti.request.images.path: /var/images
+      confetti.grant.uploads.locator: /cms/uploads
+      confetti.grant.uploads.scheme: hive
+      confetti.grant.uploads.action: pull
+      confetti.grant.uploads.expires_at: 2100-01-01T00:00:00Z
+      traefik.enable: "true"
+  worker:
+    image: confetti-cms/worker
+    labels:
+      - confetti.request.uploads.locator=/cms/uploads
+      - confetti.request.uploads.scheme=hive
+  db:
+    image: postgres
+`
+
+func TestParseCompose(t *testing.T) {
+	// Given
+	is, _ := setupTestDB(t)
+
+	// When
+	document, err := ParseCompose(strings.NewReader(testCompose), "docker-compose.yml")
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(document.Requested), 2)
+	is.Equal(document.Requested[0].Host, "host")
+	is.Equal(document.Requested[0].ContainerName, "image/container")
+	is.Equal(document.Requested[0].Target, "cmd")
+	is.Equal(document.Requested[0].SourceOrganization, "confetti-cms")
+	is.Equal(document.Requested[0].RequestScheme, "image")
+	is.Equal(document.Requested[0].RequestAction, "*") // Filled by FillRequestedByLocator
+	is.Equal(document.Requested[0].DestinationPath, "/var/images")
+	is.Equal(document.Requested[1].ContainerName, "cms/uploads")
+	is.Equal(document.Requested[1].RequestScheme, "hive")
+
+	is.Equal(len(document.Granted), 1)
+	is.Equal(document.Granted[0].ContainerName, "cms/uploads")
+	is.Equal(document.Granted[0].GrandScheme, "hive")
+	is.Equal(document.Granted[0].GrandAction, "pull")
+	is.Equal(document.Granted[0].ExpiresAt, time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	// The declarations of the worker are satisfied by the grant of the cms
+	is.True(Matches(document.Requested[1], document.Granted[0]))
+}
+
+func TestParseComposeFile(t *testing.T) {
+	// Given
+	is, _ := setupTestDB(t)
+	path := filepath.Join(t.TempDir(), "docker-compose.yml")
+	is.NoErr(os.WriteFile(path, []byte(testCompose), 0o644))
+
+	// When
+	document, err := ParseComposeFile(path)
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(document.Requested), 2)
+	is.Equal(len(document.Granted), 1)
+}
+
+func TestParseCompose_without_labels(t *testing.T) {
+	is, _ := setupTestDB(t)
+
+	for _, compose := range []string{"", "version: '3'\n", "services:\n  db:\n    image: postgres\n"} {
+		document, err := ParseCompose(strings.NewReader(compose), "docker-compose.yml")
+
+		is.NoErr(err)
+		is.Equal(len(document.Requested), 0)
+		is.Equal(len(document.Granted), 0)
+	}
+}
+
+func TestParseCompose_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		compose string
+		want    string
+	}{
+		{
+			"missing locator",
+			"services:\n  cms:\n    labels:\n      confetti.request.images.scheme: image\n",
+			"docker-compose.yml:4:39: confetti.request.images.locator is required",
+		},
+		{
+			"unknown field",
+			"services:\n  cms:\n    labels:\n      - confetti.grant.uploads.locator=/cms/uploads\n      - confetti.grant.uploads.scope=hive\n",
+			`docker-compose.yml:5:9: label confetti.grant.uploads.scope has an unknown field "scope"`,
+		},
+		{
+			"time window on a request",
+			"services:\n  cms:\n    labels:\n      confetti.request.images.expires_at: 2100-01-01T00:00:00Z\n",
+			`docker-compose.yml:4:43: label confetti.request.images.expires_at has an unknown field "expires_at"`,
+		},
+		{
+			"missing name",
+			"services:\n  cms:\n    labels:\n      confetti.grant.locator: /cms/uploads\n",
+			"docker-compose.yml:4:31: label confetti.grant.locator must be confetti.grant.<name>.<field>",
+		},
+		{
+			"invalid locator",
+			"services:\n  cms:\n    labels:\n      confetti.grant.uploads.locator: \"://bad\"\n",
+			"docker-compose.yml:4:39: confetti.grant.uploads.locator: invalid locator format",
+		},
+		{
+			"invalid request resource",
+			"services:\n  cms:\n    labels:\n      confetti.request.images.scheme: image\n      confetti.request.images.locator: /image%3Fx\n",
+			`docker-compose.yml:4:39: confetti.request.images: container name "image?x" contains a query or fragment`,
+		},
+		{
+			"invalid time",
+			"services:\n  cms:\n    labels:\n      confetti.grant.uploads.locator: /cms/uploads\n      confetti.grant.uploads.not_before: tomorrow\n",
+			"docker-compose.yml:5:42: confetti.grant.uploads.not_before: parsing time",
+		},
+		{
+			"label set twice",
+			"services:\n  cms:\n    labels:\n      - confetti.grant.uploads.locator=/a\n      - confetti.grant.uploads.locator=/b\n",
+			"docker-compose.yml:5:9: label confetti.grant.uploads.locator is set twice",
+		},
+		{
+			"invalid labels",
+			"services:\n  cms:\n    labels: yes\n",
+			"docker-compose.yml:3:13: labels must be a mapping or a list",
+		},
+		{
+			"invalid YAML",
+			"services:\n  cms: [\n",
+			"docker-compose.yml: invalid compose file: yaml: line",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			is, _ := setupTestDB(t)
+
+			// When
+			_, err := ParseCompose(strings.NewReader(tt.compose), "docker-compose.yml")
+
+			// Then
+			is.True(err != nil)
+			is.True(strings.HasPrefix(err.Error(), tt.want))
+		})
+	}
+}
+
+func TestParseCompose_error_position(t *testing.T) {
+	// Given
+	is, _ := setupTestDB(t)
+	compose := "services:\n  cms:\n    labels:\n      confetti.grant.uploads.scheme: hive\n"
+
+	// When
+	_, err := ParseCompose(strings.NewReader(compose), "docker-compose.yml")
+
+	// Then
+	var composeErr *ComposeError
+	is.True(errors.As(err, &composeErr))
+	is.Equal(composeErr.File, "docker-compose.yml")
+	is.Equal(composeErr.Line, 4)
+}
@@ -0,0 +1,157 @@
+package syncer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"time"
+)
+
+// Decision is the outcome of FindGranted for one requested permission
+type Decision struct {
+	Time      time.Time `json:"time"`
+	Actor     string    `json:"actor,omitempty"`
+	Requested Requested `json:"requested"`
+	// Matched holds the locators of the active grants that match the request
+	Matched []string `json:"matched"`
+	Allowed bool     `json:"allowed"`
+}
+
+// DecisionLogger records the decisions of FindGranted. When it returns an
+// error FindGranted fails, so no decision goes unrecorded.
+type DecisionLogger interface {
+	LogDecision(decision Decision) error
+}
+
+// WithDecisionLogger logs a Decision for every requested permission passed to FindGranted
+func WithDecisionLogger(logger DecisionLogger) Option {
+	return func(dm *DbManager) {
+		dm.decisions = logger
+	}
+}
+
+// logDecisions logs a decision for each requested permission, granted holds
+// the grants FindGranted found for all of them
+func (dm *DbManager) logDecisions(now time.Time, requested []Requested, granted []Granted) error {
+	if dm.decisions == nil {
+		return nil
+	}
+	rules, err := loadMatchRules(dm.db)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range requested {
+		decision := Decision{Time: now, Actor: dm.actor, Requested: r, Matched: []string{}}
+		for _, g := range granted {
+			if rules.matches(r, g) {
+				decision.Matched = append(decision.Matched, grantedLocator(g))
+			}
+		}
+		decision.Allowed = len(decision.Matched) > 0
+
+		if err := dm.decisions.LogDecision(decision); err != nil {
+			return fmt.Errorf("failed to log decision: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// JSONLinesLogger writes every decision as one JSON object per line
+type JSONLinesLogger struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewJSONLinesLogger creates a JSONLinesLogger that writes to w
+func NewJSONLinesLogger(w io.Writer) *JSONLinesLogger {
+	return &JSONLinesLogger{w: w}
+}
+
+// OpenJSONLinesLogger creates a JSONLinesLogger that appends to the file at path.
+// The file is created when it does not exist yet.
+func OpenJSONLinesLogger(path string) (*JSONLinesLogger, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open decision log: %w", err)
+	}
+
+	return &JSONLinesLogger{w: file, closer: file}, nil
+}
+
+func (l *JSONLinesLogger) LogDecision(decision Decision) error {
+	data, err := json.Marshal(decision)
+	if err != nil {
+		return fmt.Errorf("failed to encode decision: %w", err)
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.w.Write(data); err != nil {
+		return fmt.Errorf("failed to write decision: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the file opened by OpenJSONLinesLogger
+func (l *JSONLinesLogger) Close() error {
+	if l.closer == nil {
+		return nil
+	}
+
+	return l.closer.Close()
+}
+
+// ReadDecisions reads the decisions written by a JSONLinesLogger
+func ReadDecisions(r io.Reader) ([]Decision, error) {
+	decisions := []Decision{}
+	decoder := json.NewDecoder(r)
+	for {
+		var decision Decision
+		err := decoder.Decode(&decision)
+		if err == io.EOF {
+			return decisions, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read decision %d: %w", len(decisions)+1, err)
+		}
+		decisions = append(decisions, decision)
+	}
+}
+
+// SampledLogger passes a fraction of the decisions to Logger. Denied
+// decisions are usually rare and interesting, so they have their own rate.
+type SampledLogger struct {
+	Logger DecisionLogger
+	// AllowedRate and DeniedRate are the fractions of the allowed and denied
+	// decisions that are logged, from 0 (none) to 1 (all)
+	AllowedRate float64
+	DeniedRate  float64
+	// Random returns a number in [0, 1), it defaults to math/rand
+	Random func() float64
+}
+
+func (l *SampledLogger) LogDecision(decision Decision) error {
+	rate := l.DeniedRate
+	if decision.Allowed {
+		rate = l.AllowedRate
+	}
+
+	random := l.Random
+	if random == nil {
+		random = rand.Float64
+	}
+	if random() >= rate {
+		return nil
+	}
+
+	return l.Logger.LogDecision(decision)
+}
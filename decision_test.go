@@ -0,0 +1,187 @@
+package syncer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// recordingLogger keeps the decisions in memory
+type recordingLogger struct {
+	decisions []Decision
+	err       error
+}
+
+func (l *recordingLogger) LogDecision(decision Decision) error {
+	l.decisions = append(l.decisions, decision)
+	return l.err
+}
+
+func TestDecision_logged_for_each_requested(t *testing.T) {
+	// Given
+	is := is.New(t)
+	clock := &fakeClock{now: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}
+	logger := &recordingLogger{}
+	dbManager, err := NewDbManager(WithClock(clock.Now), WithDecisionLogger(logger))
+	is.NoErr(err)
+	defer dbManager.Close()
+	granted := Granted{ContainerName: "image/container", GrandScheme: "image", GrandAction: "pull"}
+	mockGranted(dbManager, granted)
+	allowed := Requested{ContainerName: "image/container", RequestScheme: "image", RequestAction: "pull"}
+	denied := Requested{ContainerName: "image/container", RequestScheme: "image", RequestAction: "push"}
+
+	// When
+	result, err := dbManager.AsActor("ci").FindGranted([]Requested{allowed, denied})
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(result), 1)
+	is.Equal(len(logger.decisions), 2)
+	is.Equal(logger.decisions[0], Decision{
+		Time:      clock.Now(),
+		Actor:     "ci",
+		Requested: allowed,
+		Matched:   []string{grantedLocator(granted)},
+		Allowed:   true,
+	})
+	is.Equal(logger.decisions[1].Requested, denied)
+	is.Equal(logger.decisions[1].Matched, []string{})
+	is.Equal(logger.decisions[1].Allowed, false)
+}
+
+func TestDecision_logger_error_fails_check(t *testing.T) {
+	// Given
+	is := is.New(t)
+	logger := &recordingLogger{err: errors.New("disk full")}
+	dbManager, err := NewDbManager(WithDecisionLogger(logger))
+	is.NoErr(err)
+	defer dbManager.Close()
+
+	// When
+	_, err = dbManager.FindGranted([]Requested{{RequestScheme: "image"}})
+
+	// Then
+	is.True(err != nil)
+}
+
+func TestDecision_not_logged_for_change_events(t *testing.T) {
+	// Given
+	is := is.New(t)
+	logger := &recordingLogger{}
+	dbManager, err := NewDbManager(WithDecisionLogger(logger))
+	is.NoErr(err)
+	defer dbManager.Close()
+	_, unsubscribe := dbManager.Subscribe(ChangeFilter{})
+	defer unsubscribe()
+
+	// When
+	mockRequested(dbManager, Requested{RequestScheme: "image"})
+
+	// Then
+	is.Equal(len(logger.decisions), 0)
+}
+
+func TestJSONLinesLogger(t *testing.T) {
+	// Given
+	is := is.New(t)
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	logger, err := OpenJSONLinesLogger(path)
+	is.NoErr(err)
+
+	// When
+	is.NoErr(logger.LogDecision(Decision{Actor: "first", Allowed: true}))
+	is.NoErr(logger.LogDecision(Decision{Actor: "second"}))
+	is.NoErr(logger.Close())
+
+	// Then
+	data, err := os.ReadFile(path)
+	is.NoErr(err)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	var decisions []Decision
+	for scanner.Scan() {
+		var decision Decision
+		is.NoErr(json.Unmarshal(scanner.Bytes(), &decision))
+		decisions = append(decisions, decision)
+	}
+	is.Equal(len(decisions), 2)
+	is.Equal(decisions[0].Actor, "first")
+	is.Equal(decisions[0].Allowed, true)
+	is.Equal(decisions[1].Actor, "second")
+}
+
+func TestJSONLinesLogger_appends(t *testing.T) {
+	// Given
+	is := is.New(t)
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	is.NoErr(os.WriteFile(path, []byte("{}\n"), 0o644))
+
+	// When
+	logger, err := OpenJSONLinesLogger(path)
+	is.NoErr(err)
+	is.NoErr(logger.LogDecision(Decision{}))
+	is.NoErr(logger.Close())
+
+	// Then
+	data, err := os.ReadFile(path)
+	is.NoErr(err)
+	is.Equal(bytes.Count(data, []byte("\n")), 2)
+}
+
+func TestSampledLogger(t *testing.T) {
+	// Given
+	is := is.New(t)
+	recorder := &recordingLogger{}
+	random := 0.0
+	logger := &SampledLogger{
+		Logger:      recorder,
+		AllowedRate: 0.1,
+		DeniedRate:  1,
+		Random:      func() float64 { return random },
+	}
+
+	// When
+	for _, r := range []float64{0.05, 0.5, 0.99} {
+		random = r
+		is.NoErr(logger.LogDecision(Decision{Actor: "allowed", Allowed: true}))
+		is.NoErr(logger.LogDecision(Decision{Actor: "denied"}))
+	}
+
+	// Then
+	var allowed, denied int
+	for _, decision := range recorder.decisions {
+		if decision.Allowed {
+			allowed++
+		} else {
+			denied++
+		}
+	}
+	is.Equal(allowed, 1) // Only the draw below the rate
+	is.Equal(denied, 3)  // Every denied decision
+}
+
+func TestReadDecisions(t *testing.T) {
+	// Given
+	is := is.New(t)
+	var buf bytes.Buffer
+	logger := NewJSONLinesLogger(&buf)
+	is.NoErr(logger.LogDecision(Decision{Actor: "first", Matched: []string{"a"}, Allowed: true}))
+	is.NoErr(logger.LogDecision(Decision{Actor: "second", Matched: []string{}}))
+
+	// When
+	decisions, err := ReadDecisions(&buf)
+	_, invalidErr := ReadDecisions(bytes.NewReader([]byte("{}\n{")))
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(decisions), 2)
+	is.Equal(decisions[0].Matched, []string{"a"})
+	is.Equal(decisions[1].Actor, "second")
+	is.True(invalidErr != nil)
+}
@@ -0,0 +1,353 @@
+package syncer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the file format of Export and Import
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// Document holds all stored permissions, it is written by Export and read by
+// Import. Both formats use the JSON field names of Requested and Granted.
+type Document struct {
+	Requested []Requested `json:"requested"`
+	Granted   []Granted   `json:"granted"`
+}
+
+// ImportAction is what Import does with a record of the document
+type ImportAction string
+
+const (
+	ImportCreate    ImportAction = "create"
+	ImportUpdate    ImportAction = "update"
+	ImportUnchanged ImportAction = "unchanged"
+)
+
+// ImportChange is the outcome of Import for one record of the document
+type ImportChange struct {
+	Action    ImportAction `json:"action"`
+	Kind      RecordKind   `json:"kind"`
+	Locator   string       `json:"locator"`
+	Requested *Requested   `json:"requested,omitempty"`
+	Granted   *Granted     `json:"granted,omitempty"`
+}
+
+// ImportReport lists what Import changed, or would change in a dry run
+type ImportReport struct {
+	DryRun  bool           `json:"dry_run"`
+	Changes []ImportChange `json:"changes"`
+}
+
+// Count returns the number of records with the given action
+func (r ImportReport) Count(action ImportAction) int {
+	count := 0
+	for _, change := range r.Changes {
+		if change.Action == action {
+			count++
+		}
+	}
+
+	return count
+}
+
+// ImportOption configures Import
+type ImportOption func(*importOptions)
+
+type importOptions struct {
+	dryRun bool
+}
+
+// DryRun makes Import report the changes without storing them
+func DryRun() ImportOption {
+	return func(o *importOptions) {
+		o.dryRun = true
+	}
+}
+
+// Export writes all stored permissions as a Document
+func (dm *DbManager) Export(w io.Writer, format Format) error {
+	requested, err := dm.ListRequested()
+	if err != nil {
+		return err
+	}
+	granted, err := dm.ListGranted()
+	if err != nil {
+		return err
+	}
+
+	return EncodeDocument(w, format, Document{Requested: nonNil(requested), Granted: nonNil(granted)})
+}
+
+// Import reads a Document and saves its records in one transaction. Records
+// that are stored already are left alone, stored records that are missing from
+// the document are kept. Nothing is stored when a record is invalid.
+func (dm *DbManager) Import(r io.Reader, format Format, opts ...ImportOption) (ImportReport, error) {
+	var o importOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	report := ImportReport{DryRun: o.dryRun, Changes: []ImportChange{}}
+
+	document, err := DecodeDocument(r, format)
+	if err != nil {
+		return report, err
+	}
+	if err := ValidateDocument(document); err != nil {
+		return report, err
+	}
+
+	tx, err := dm.db.Begin()
+	if err != nil {
+		return report, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var savedRequested []Requested
+	for _, req := range document.Requested {
+		locator := requestedLocator(req)
+		before, err := getRequested(tx, locator)
+		if err != nil {
+			return report, err
+		}
+		change := ImportChange{Action: importAction(before != nil, before != nil && *before == req), Kind: KindRequested, Locator: locator, Requested: &req}
+		report.Changes = append(report.Changes, change)
+		if change.Action == ImportUnchanged {
+			continue
+		}
+		if err := dm.saveRequested(tx, req); err != nil {
+			return report, err
+		}
+		savedRequested = append(savedRequested, req)
+	}
+
+	var savedGranted []Granted
+	for _, g := range document.Granted {
+		locator := grantedLocator(g)
+		before, err := getGranted(tx, locator)
+		if err != nil {
+			return report, err
+		}
+		change := ImportChange{Action: importAction(before != nil, before != nil && sameGranted(*before, g)), Kind: KindGranted, Locator: locator, Granted: &g}
+		report.Changes = append(report.Changes, change)
+		if change.Action == ImportUnchanged {
+			continue
+		}
+		if err := dm.saveGranted(tx, g); err != nil {
+			return report, err
+		}
+		savedGranted = append(savedGranted, g)
+	}
+
+	if err := dm.overlapsOf(tx, savedRequested); err != nil {
+		return report, err
+	}
+	if o.dryRun {
+		return report, nil
+	}
+
+	requestedEvents, err := dm.requestedChanges(tx, ChangeSaved, savedRequested)
+	if err != nil {
+		return report, err
+	}
+	grantedEvents, err := dm.grantedChanges(tx, ChangeSaved, savedGranted)
+	if err != nil {
+		return report, err
+	}
+
+	if err := dm.commit(tx, append(requestedEvents, grantedEvents...)); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+func importAction(exists, unchanged bool) ImportAction {
+	switch {
+	case unchanged:
+		return ImportUnchanged
+	case exists:
+		return ImportUpdate
+	default:
+		return ImportCreate
+	}
+}
+
+// sameGranted compares two grants, the time window is compared as instants
+func sameGranted(a, b Granted) bool {
+	if !a.NotBefore.Equal(b.NotBefore) || !a.ExpiresAt.Equal(b.ExpiresAt) {
+		return false
+	}
+	a.NotBefore, a.ExpiresAt = b.NotBefore, b.ExpiresAt
+
+	return a == b
+}
+
+// EncodeDocument writes a Document in the given format
+func EncodeDocument(w io.Writer, format Format, document Document) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(document); err != nil {
+			return fmt.Errorf("failed to encode document: %w", err)
+		}
+		return nil
+	case FormatYAML:
+		// Convert through JSON, so YAML uses the same field names
+		data, err := json.Marshal(document)
+		if err != nil {
+			return fmt.Errorf("failed to encode document: %w", err)
+		}
+		var value any
+		if err := json.Unmarshal(data, &value); err != nil {
+			return fmt.Errorf("failed to encode document: %w", err)
+		}
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(value); err != nil {
+			return fmt.Errorf("failed to encode document: %w", err)
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("unknown format %q, use %s or %s", format, FormatJSON, FormatYAML)
+	}
+}
+
+// DecodeDocument reads a Document in the given format, unknown fields are an error
+func DecodeDocument(r io.Reader, format Format) (Document, error) {
+	var document Document
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return document, fmt.Errorf("failed to read document: %w", err)
+	}
+
+	return document, decodeStrict(data, format, &document)
+}
+
+// decodeStrict decodes a JSON or YAML document into v and rejects unknown fields
+func decodeStrict(data []byte, format Format, v any) error {
+	switch format {
+	case FormatJSON:
+	case FormatYAML:
+		// Convert through JSON, so YAML uses the same field names
+		var value any
+		if err := yaml.Unmarshal(data, &value); err != nil {
+			return fmt.Errorf("invalid YAML document: %w", err)
+		}
+		var err error
+		if data, err = json.Marshal(value); err != nil {
+			return fmt.Errorf("invalid YAML document: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown format %q, use %s or %s", format, FormatJSON, FormatYAML)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid %s document: %w", format, err)
+	}
+
+	return nil
+}
+
+// ValidateDocument checks every record of the document and returns all problems at once
+func ValidateDocument(document Document) error {
+	var errs []error
+	for i, r := range document.Requested {
+		if err := ValidateRequested(r); err != nil {
+			errs = append(errs, fmt.Errorf("requested[%d]: %w", i, err))
+		}
+	}
+	for i, g := range document.Granted {
+		if err := ValidateGranted(g); err != nil {
+			errs = append(errs, fmt.Errorf("granted[%d]: %w", i, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateRequested checks that the resource of a requested record forms a valid locator
+func ValidateRequested(r Requested) error {
+	return validateResource(r.Host, r.ContainerName, r.Target, r.SourceOrganization, r.SourceRepository, r.UmbrellaOrganization, r.UmbrellaRepository)
+}
+
+// ValidateGranted checks that the resource of a granted record forms a valid
+// locator and that its time window is not empty
+func ValidateGranted(g Granted) error {
+	if err := validateResource(g.Host, g.ContainerName, g.Target, g.SourceOrganization, g.SourceRepository, g.UmbrellaOrganization, g.UmbrellaRepository); err != nil {
+		return err
+	}
+	if !g.NotBefore.IsZero() && !g.ExpiresAt.IsZero() && !g.NotBefore.Before(g.ExpiresAt) {
+		return fmt.Errorf("not_before %s is not before expires_at %s", g.NotBefore.Format(time.RFC3339), g.ExpiresAt.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// validateResource parses the resource as a locator. The container name and
+// the owners must be in the form the locator parser produces, otherwise no
+// locator can match them.
+func validateResource(host, containerName, target, sourceOrganization, sourceRepository, umbrellaOrganization, umbrellaRepository string) error {
+	owners := map[string]string{
+		"source_organization":   sourceOrganization,
+		"source_repository":     sourceRepository,
+		"umbrella_organization": umbrellaOrganization,
+		"umbrella_repository":   umbrellaRepository,
+	}
+	if host != strings.ToLower(host) {
+		return fmt.Errorf("host %q is not normalized, use %q", host, strings.ToLower(host))
+	}
+	for _, key := range locatorOwnerParameters {
+		if value := owners[key]; value != strings.ToLower(value) {
+			return fmt.Errorf("%s %q is not normalized, use %q", strings.ReplaceAll(key, "_", " "), value, strings.ToLower(value))
+		}
+	}
+
+	query := url.Values{}
+	for key, value := range owners {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	if target != "" {
+		query.Set("target", target)
+	}
+
+	if strings.ContainsAny(containerName, "?#") {
+		return fmt.Errorf("container name %q contains a query or fragment", containerName)
+	}
+	if normalized := normalizeContainerName(containerName); normalized != containerName {
+		return fmt.Errorf("container name %q is not normalized, use %q", containerName, normalized)
+	}
+
+	locator := "//" + host + "/" + containerName
+	if len(query) > 0 {
+		locator += "?" + query.Encode()
+	}
+	u, err := parseLocator(locator)
+	if err != nil {
+		return err
+	}
+	if u.Host != host {
+		return fmt.Errorf("invalid host %q", host)
+	}
+
+	return nil
+}
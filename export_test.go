@@ -0,0 +1,158 @@
+package syncer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExport_Import_round_trip(t *testing.T) {
+	for _, format := range []Format{FormatJSON, FormatYAML} {
+		t.Run(string(format), func(t *testing.T) {
+			// Given
+			is, source := setupTestDB(t)
+			mockGranted(source, fullGranted)
+			mockRequested(source, fullRequested)
+			var exported bytes.Buffer
+			is.NoErr(source.Export(&exported, format))
+			_, target := setupTestDB(t)
+
+			// When
+			report, err := target.Import(&exported, format)
+
+			// Then
+			is.NoErr(err)
+			is.Equal(report.Count(ImportCreate), 2)
+			granted, err := target.ListGranted()
+			is.NoErr(err)
+			is.Equal(granted, []Granted{fullGranted})
+			requested, err := target.ListRequested()
+			is.NoErr(err)
+			is.Equal(requested, []Requested{fullRequested})
+		})
+	}
+}
+
+func TestExport_empty_store(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	var exported bytes.Buffer
+
+	// When
+	err := dbManager.Export(&exported, FormatJSON)
+
+	// Then
+	is.NoErr(err)
+	is.Equal(exported.String(), "{\n  \"requested\": [],\n  \"granted\": []\n}\n")
+}
+
+func TestImport_reports_changes(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	stored := Granted{ContainerName: "image/container", GrandScheme: "image"}
+	mockGranted(dbManager, stored)
+	mockGranted(dbManager, Granted{ContainerName: "image/other", GrandScheme: "image"})
+	document := `
+granted:
+  - ContainerName: image/container
+    scheme: image
+    expires_at: 2100-01-01T00:00:00Z
+  - ContainerName: image/other
+    scheme: image
+requested:
+  - ContainerName: image/container
+    scheme: image
+`
+
+	// When
+	report, err := dbManager.Import(strings.NewReader(document), FormatYAML)
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(report.Changes), 3)
+	is.Equal(report.Changes[0].Action, ImportCreate)
+	is.Equal(report.Changes[0].Kind, KindRequested)
+	is.Equal(report.Changes[1].Action, ImportUpdate)
+	is.Equal(report.Changes[1].Locator, grantedLocator(stored))
+	is.Equal(report.Changes[1].Granted.ExpiresAt, time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC))
+	is.Equal(report.Changes[2].Action, ImportUnchanged)
+
+	entries, err := dbManager.AuditLog(AuditFilter{})
+	is.NoErr(err)
+	is.Equal(len(entries), 4) // Two mocks, the update and the create, not the unchanged grant
+}
+
+func TestImport_dry_run(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	document := `{"granted": [{"ContainerName": "image/container", "scheme": "image"}]}`
+
+	// When
+	report, err := dbManager.Import(strings.NewReader(document), FormatJSON, DryRun())
+
+	// Then
+	is.NoErr(err)
+	is.True(report.DryRun)
+	is.Equal(report.Count(ImportCreate), 1)
+	granted, err := dbManager.ListGranted()
+	is.NoErr(err)
+	is.Equal(len(granted), 0)
+	entries, err := dbManager.AuditLog(AuditFilter{})
+	is.NoErr(err)
+	is.Equal(len(entries), 0)
+}
+
+func TestImport_invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   Format
+		document string
+		want     string
+	}{
+		{"unknown format", "xml", `{}`, "unknown format"},
+		{"invalid JSON", FormatJSON, `{`, "invalid json document"},
+		{"invalid YAML", FormatYAML, "granted: [", "invalid YAML document"},
+		{"unknown field", FormatJSON, `{"granted": [{"scope": "image"}]}`, "unknown field"},
+		{"container name with slashes", FormatJSON, `{"granted": [{"ContainerName": "/image//container"}]}`, `granted[0]: container name "/image//container" is not normalized, use "image/container"`},
+		{"organization with capitals", FormatJSON, `{"granted": [{"SourceOrganization": "Confetti"}]}`, `granted[0]: source organization "Confetti" is not normalized, use "confetti"`},
+		{"repository with capitals", FormatJSON, `{"requested": [{"UmbrellaRepository": "Sites"}]}`, `requested[0]: umbrella repository "Sites" is not normalized, use "sites"`},
+		{"container name with query", FormatJSON, `{"requested": [{"ContainerName": "image?target=cmd"}]}`, "requested[0]: container name"},
+		{"invalid host", FormatJSON, `{"granted": [{"Host": "bad host"}]}`, "granted[0]: invalid locator format"},
+		{"host with capitals", FormatJSON, `{"granted": [{"Host": "Example.com"}]}`, `granted[0]: host "Example.com" is not normalized, use "example.com"`},
+		{"host with path", FormatJSON, `{"granted": [{"Host": "host/path"}]}`, `granted[0]: invalid host "host/path"`},
+		{"empty time window", FormatJSON, `{"granted": [{"not_before": "2030-01-01T00:00:00Z", "expires_at": "2020-01-01T00:00:00Z"}]}`, "granted[0]: not_before"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			is, dbManager := setupTestDB(t)
+
+			// When
+			_, err := dbManager.Import(strings.NewReader(tt.document), tt.format)
+
+			// Then
+			is.True(err != nil)
+			is.True(strings.Contains(err.Error(), tt.want))
+		})
+	}
+}
+
+func TestImport_is_transactional(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	_, err := dbManager.db.Exec(`CREATE TRIGGER fail_granted BEFORE INSERT ON granted
+		WHEN NEW.grand_scheme = 'fail' BEGIN SELECT RAISE(ABORT, 'failed'); END`)
+	is.NoErr(err)
+	document := `{"requested": [{"scheme": "image"}], "granted": [{"scheme": "fail"}]}`
+
+	// When
+	_, err = dbManager.Import(strings.NewReader(document), FormatJSON)
+
+	// Then
+	is.True(err != nil)
+	requested, err := dbManager.ListRequested()
+	is.NoErr(err)
+	is.Equal(len(requested), 0)
+}
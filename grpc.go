@@ -0,0 +1,253 @@
+package syncer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/confetti-cms/syncer/syncerpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GRPCServer implements the Syncer gRPC service on top of a DbManager
+type GRPCServer struct {
+	syncerpb.UnimplementedSyncerServer
+	dm *DbManager
+}
+
+// NewGRPCServer creates a GRPCServer, register it with syncerpb.RegisterSyncerServer
+func NewGRPCServer(dm *DbManager) *GRPCServer {
+	return &GRPCServer{dm: dm}
+}
+
+func (s *GRPCServer) SaveRequested(ctx context.Context, in *syncerpb.SaveRequestedRequest) (*syncerpb.SaveRequestedResponse, error) {
+	if err := s.dm.SaveRequested(RequestedFromProto(in.GetRequested())); err != nil {
+		return nil, status.Error(saveCode(err), err.Error())
+	}
+
+	return &syncerpb.SaveRequestedResponse{}, nil
+}
+
+func (s *GRPCServer) SaveGranted(ctx context.Context, in *syncerpb.SaveGrantedRequest) (*syncerpb.SaveGrantedResponse, error) {
+	if err := s.dm.SaveGrantedBatch(GrantedFromProto(in.GetGranted())); err != nil {
+		return nil, status.Error(saveCode(err), err.Error())
+	}
+
+	return &syncerpb.SaveGrantedResponse{}, nil
+}
+
+func (s *GRPCServer) FindGranted(ctx context.Context, in *syncerpb.FindGrantedRequest) (*syncerpb.FindGrantedResponse, error) {
+	granted, err := s.dm.FindGranted(RequestedFromProto(in.GetRequested()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &syncerpb.FindGrantedResponse{Granted: GrantedToProto(granted)}, nil
+}
+
+func (s *GRPCServer) FindRequested(ctx context.Context, in *syncerpb.FindRequestedRequest) (*syncerpb.FindRequestedResponse, error) {
+	requested, err := s.dm.FindRequested(GrantedFromProto(in.GetGranted()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &syncerpb.FindRequestedResponse{Requested: RequestedToProto(requested)}, nil
+}
+
+func (s *GRPCServer) DeleteRequested(ctx context.Context, in *syncerpb.DeleteRequestedRequest) (*syncerpb.DeleteRequestedResponse, error) {
+	if err := s.dm.DeleteRequested(RequestedFromProto(in.GetRequested())); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &syncerpb.DeleteRequestedResponse{}, nil
+}
+
+func (s *GRPCServer) DeleteGranted(ctx context.Context, in *syncerpb.DeleteGrantedRequest) (*syncerpb.DeleteGrantedResponse, error) {
+	if err := s.dm.deleteGrantedBatch(GrantedFromProto(in.GetGranted())); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &syncerpb.DeleteGrantedResponse{}, nil
+}
+
+func (s *GRPCServer) StreamRequested(in *syncerpb.StreamRequestedRequest, stream grpc.ServerStreamingServer[syncerpb.Requested]) error {
+	requested, err := s.dm.ListRequested()
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	for _, r := range RequestedToProto(requested) {
+		if err := stream.Send(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *GRPCServer) StreamGranted(in *syncerpb.StreamGrantedRequest, stream grpc.ServerStreamingServer[syncerpb.Granted]) error {
+	granted, err := s.dm.ListGranted()
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	for _, g := range GrantedToProto(granted) {
+		if err := stream.Send(g); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RequestedToProto converts requested permissions to their protobuf messages
+func RequestedToProto(requested []Requested) []*syncerpb.Requested {
+	messages := make([]*syncerpb.Requested, 0, len(requested))
+	for _, r := range requested {
+		messages = append(messages, &syncerpb.Requested{
+			Description:                 r.Description,
+			Host:                        r.Host,
+			DestinationPath:             r.DestinationPath,
+			SourceOrganization:          r.SourceOrganization,
+			SourceRepository:            r.SourceRepository,
+			UmbrellaOrganization:        r.UmbrellaOrganization,
+			UmbrellaRepository:          r.UmbrellaRepository,
+			ContainerName:               r.ContainerName,
+			Target:                      r.Target,
+			RequestScheme:               r.RequestScheme,
+			RequestAction:               r.RequestAction,
+			RequestSourceOrganization:   r.RequestSourceOrganization,
+			RequestSourceRepository:     r.RequestSourceRepository,
+			RequestUmbrellaOrganization: r.RequestUmbrellaOrganization,
+			RequestUmbrellaRepository:   r.RequestUmbrellaRepository,
+			RequestContainerName:        r.RequestContainerName,
+			RequestTarget:               r.RequestTarget,
+		})
+	}
+
+	return messages
+}
+
+// RequestedFromProto converts protobuf messages to requested permissions
+func RequestedFromProto(messages []*syncerpb.Requested) []Requested {
+	requested := make([]Requested, 0, len(messages))
+	for _, m := range messages {
+		requested = append(requested, Requested{
+			Description:                 m.GetDescription(),
+			Host:                        m.GetHost(),
+			DestinationPath:             m.GetDestinationPath(),
+			SourceOrganization:          m.GetSourceOrganization(),
+			SourceRepository:            m.GetSourceRepository(),
+			UmbrellaOrganization:        m.GetUmbrellaOrganization(),
+			UmbrellaRepository:          m.GetUmbrellaRepository(),
+			ContainerName:               m.GetContainerName(),
+			Target:                      m.GetTarget(),
+			RequestScheme:               m.GetRequestScheme(),
+			RequestAction:               m.GetRequestAction(),
+			RequestSourceOrganization:   m.GetRequestSourceOrganization(),
+			RequestSourceRepository:     m.GetRequestSourceRepository(),
+			RequestUmbrellaOrganization: m.GetRequestUmbrellaOrganization(),
+			RequestUmbrellaRepository:   m.GetRequestUmbrellaRepository(),
+			RequestContainerName:        m.GetRequestContainerName(),
+			RequestTarget:               m.GetRequestTarget(),
+		})
+	}
+
+	return requested
+}
+
+// GrantedToProto converts granted permissions to their protobuf messages
+func GrantedToProto(granted []Granted) []*syncerpb.Granted {
+	messages := make([]*syncerpb.Granted, 0, len(granted))
+	for _, g := range granted {
+		messages = append(messages, &syncerpb.Granted{
+			Description:               g.Description,
+			Host:                      g.Host,
+			ExposePath:                g.ExposePath,
+			SourceOrganization:        g.SourceOrganization,
+			SourceRepository:          g.SourceRepository,
+			UmbrellaOrganization:      g.UmbrellaOrganization,
+			UmbrellaRepository:        g.UmbrellaRepository,
+			ContainerName:             g.ContainerName,
+			Target:                    g.Target,
+			GrandScheme:               g.GrandScheme,
+			GrandAction:               g.GrandAction,
+			GrandSourceOrganization:   g.GrandSourceOrganization,
+			GrandSourceRepository:     g.GrandSourceRepository,
+			GrandUmbrellaOrganization: g.GrandUmbrellaOrganization,
+			GrandUmbrellaRepository:   g.GrandUmbrellaRepository,
+			GrandContainerName:        g.GrandContainerName,
+			GrandTarget:               g.GrandTarget,
+			NotBefore:                 timestampToProto(g.NotBefore),
+			ExpiresAt:                 timestampToProto(g.ExpiresAt),
+		})
+	}
+
+	return messages
+}
+
+// GrantedFromProto converts protobuf messages to granted permissions
+func GrantedFromProto(messages []*syncerpb.Granted) []Granted {
+	granted := make([]Granted, 0, len(messages))
+	for _, m := range messages {
+		granted = append(granted, Granted{
+			Description:               m.GetDescription(),
+			Host:                      m.GetHost(),
+			ExposePath:                m.GetExposePath(),
+			SourceOrganization:        m.GetSourceOrganization(),
+			SourceRepository:          m.GetSourceRepository(),
+			UmbrellaOrganization:      m.GetUmbrellaOrganization(),
+			UmbrellaRepository:        m.GetUmbrellaRepository(),
+			ContainerName:             m.GetContainerName(),
+			Target:                    m.GetTarget(),
+			GrandScheme:               m.GetGrandScheme(),
+			GrandAction:               m.GetGrandAction(),
+			GrandSourceOrganization:   m.GetGrandSourceOrganization(),
+			GrandSourceRepository:     m.GetGrandSourceRepository(),
+			GrandUmbrellaOrganization: m.GetGrandUmbrellaOrganization(),
+			GrandUmbrellaRepository:   m.GetGrandUmbrellaRepository(),
+			GrandContainerName:        m.GetGrandContainerName(),
+			GrandTarget:               m.GetGrandTarget(),
+			NotBefore:                 timestampFromProto(m.GetNotBefore()),
+			ExpiresAt:                 timestampFromProto(m.GetExpiresAt()),
+		})
+	}
+
+	return granted
+}
+
+// timestampToProto leaves the timestamp unset for the zero time
+func timestampToProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(t)
+}
+
+// timestampFromProto returns the zero time for an unset timestamp
+func timestampFromProto(t *timestamppb.Timestamp) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+
+	return t.AsTime()
+}
+
+// saveCode is InvalidArgument for permissions that their scheme does not
+// accept and FailedPrecondition for overlapping destination paths
+func saveCode(err error) codes.Code {
+	var invalid *ValidationError
+	if errors.As(err, &invalid) {
+		return codes.InvalidArgument
+	}
+	var overlap *OverlapError
+	if errors.As(err, &overlap) {
+		return codes.FailedPrecondition
+	}
+
+	return codes.Internal
+}
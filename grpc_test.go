@@ -0,0 +1,247 @@
+package syncer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/confetti-cms/syncer/syncerpb"
+	"github.com/matryer/is"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func setupTestGRPC(t *testing.T) (*is.I, *DbManager, syncerpb.SyncerClient) {
+	is, dbManager := setupTestDB(t)
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	syncerpb.RegisterSyncerServer(server, NewGRPCServer(dbManager))
+	go server.Serve(listener)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	is.NoErr(err)
+
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+	})
+
+	return is, dbManager, syncerpb.NewSyncerClient(conn)
+}
+
+// fullRequested has a distinct value in every field, including wildcards
+var fullRequested = Requested{
+	Description:                 "description",
+	Host:                        "host",
+	DestinationPath:             "/expose/destination",
+	SourceOrganization:          "source-org",
+	SourceRepository:            "source-repo",
+	UmbrellaOrganization:        "umbrella-org",
+	UmbrellaRepository:          "umbrella-repo",
+	ContainerName:               "image/container",
+	Target:                      "cmd",
+	RequestScheme:               "image",
+	RequestAction:               "*",
+	RequestSourceOrganization:   "*",
+	RequestSourceRepository:     "request-source-repo",
+	RequestUmbrellaOrganization: "request-umbrella-org",
+	RequestUmbrellaRepository:   "*",
+	RequestContainerName:        "request-container",
+	RequestTarget:               "*",
+}
+
+// fullGranted has a distinct value in every field and matches fullRequested
+var fullGranted = Granted{
+	Description:               "description",
+	Host:                      "host",
+	ExposePath:                "/expose",
+	SourceOrganization:        "source-org",
+	SourceRepository:          "source-repo",
+	UmbrellaOrganization:      "umbrella-org",
+	UmbrellaRepository:        "umbrella-repo",
+	ContainerName:             "image/container",
+	Target:                    "cmd",
+	GrandScheme:               "*",
+	GrandAction:               "pull",
+	GrandSourceOrganization:   "grand-source-org",
+	GrandSourceRepository:     "*",
+	GrandUmbrellaOrganization: "*",
+	GrandUmbrellaRepository:   "grand-umbrella-repo",
+	GrandContainerName:        "*",
+	GrandTarget:               "run",
+	NotBefore:                 time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	ExpiresAt:                 time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+func TestGRPC_granted_round_trip(t *testing.T) {
+	// Given
+	is, _, client := setupTestGRPC(t)
+	ctx := context.Background()
+
+	// When
+	_, err := client.SaveGranted(ctx, &syncerpb.SaveGrantedRequest{Granted: GrantedToProto([]Granted{fullGranted})})
+	is.NoErr(err)
+	response, err := client.FindGranted(ctx, &syncerpb.FindGrantedRequest{Requested: RequestedToProto([]Requested{fullRequested})})
+
+	// Then
+	is.NoErr(err)
+	granted := GrantedFromProto(response.GetGranted())
+	is.Equal(len(granted), 1)
+	is.Equal(granted[0], fullGranted)
+}
+
+func TestGRPC_requested_round_trip(t *testing.T) {
+	// Given
+	is, _, client := setupTestGRPC(t)
+	ctx := context.Background()
+
+	// When
+	_, err := client.SaveRequested(ctx, &syncerpb.SaveRequestedRequest{Requested: RequestedToProto([]Requested{fullRequested})})
+	is.NoErr(err)
+	response, err := client.FindRequested(ctx, &syncerpb.FindRequestedRequest{Granted: GrantedToProto([]Granted{fullGranted})})
+
+	// Then
+	is.NoErr(err)
+	requested := RequestedFromProto(response.GetRequested())
+	is.Equal(len(requested), 1)
+	is.Equal(requested[0], fullRequested)
+}
+
+func TestGRPC_stream(t *testing.T) {
+	// Given
+	is, dbManager, client := setupTestGRPC(t)
+	ctx := context.Background()
+	mockGranted(dbManager, fullGranted)
+	mockGranted(dbManager, Granted{ContainerName: "other", GrandScheme: "*"})
+	mockRequested(dbManager, fullRequested)
+
+	// When
+	grantedStream, err := client.StreamGranted(ctx, &syncerpb.StreamGrantedRequest{})
+	is.NoErr(err)
+	var granted []*syncerpb.Granted
+	for {
+		message, err := grantedStream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		is.NoErr(err)
+		granted = append(granted, message)
+	}
+	requestedStream, err := client.StreamRequested(ctx, &syncerpb.StreamRequestedRequest{})
+	is.NoErr(err)
+	var requested []*syncerpb.Requested
+	for {
+		message, err := requestedStream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		is.NoErr(err)
+		requested = append(requested, message)
+	}
+
+	// Then
+	is.Equal(len(granted), 2)
+	is.Equal(GrantedFromProto(granted)[0], fullGranted)
+	is.Equal(GrantedFromProto(granted)[1].GrandScheme, "*")
+	is.Equal(len(requested), 1)
+	is.Equal(RequestedFromProto(requested)[0], fullRequested)
+}
+
+func TestGRPC_delete(t *testing.T) {
+	// Given
+	is, dbManager, client := setupTestGRPC(t)
+	ctx := context.Background()
+	mockGranted(dbManager, fullGranted)
+	mockRequested(dbManager, fullRequested)
+
+	// When
+	_, err := client.DeleteGranted(ctx, &syncerpb.DeleteGrantedRequest{Granted: GrantedToProto([]Granted{fullGranted})})
+	is.NoErr(err)
+	_, err = client.DeleteRequested(ctx, &syncerpb.DeleteRequestedRequest{Requested: RequestedToProto([]Requested{fullRequested})})
+	is.NoErr(err)
+
+	// Then
+	granted, err := dbManager.ListGranted()
+	is.NoErr(err)
+	is.Equal(len(granted), 0)
+	requested, err := dbManager.ListRequested()
+	is.NoErr(err)
+	is.Equal(len(requested), 0)
+}
+
+func TestGRPC_store_failure(t *testing.T) {
+	// Given
+	is, dbManager, client := setupTestGRPC(t)
+	is.NoErr(dbManager.Close())
+
+	// When
+	_, err := client.FindGranted(context.Background(), &syncerpb.FindGrantedRequest{Requested: RequestedToProto([]Requested{fullRequested})})
+
+	// Then
+	is.Equal(status.Code(err), codes.Internal)
+}
+
+func TestGRPC_save_invalid_for_scheme(t *testing.T) {
+	// Given
+	is, dbManager, client := setupTestGRPC(t)
+	is.NoErr(dbManager.RegisterScheme(Scheme{Name: "hive", RequiredRequested: []string{"DestinationPath"}}))
+	requested := Requested{ContainerName: "hive/container", RequestScheme: "hive", RequestAction: "sync"}
+
+	// When
+	_, err := client.SaveRequested(context.Background(), &syncerpb.SaveRequestedRequest{Requested: RequestedToProto([]Requested{requested})})
+
+	// Then
+	is.Equal(status.Code(err), codes.InvalidArgument)
+}
+
+func TestGRPC_save_granted_batch_is_atomic(t *testing.T) {
+	// Given
+	is, dbManager, client := setupTestGRPC(t)
+	is.NoErr(dbManager.RegisterScheme(Scheme{Name: "hive", RequiredGranted: []string{"ExposePath"}}))
+	valid := Granted{ContainerName: "hive/container", GrandScheme: "hive", GrandAction: "sync", ExposePath: "/public"}
+	invalid := Granted{ContainerName: "hive/other", GrandScheme: "hive", GrandAction: "sync"}
+
+	// When
+	_, err := client.SaveGranted(context.Background(), &syncerpb.SaveGrantedRequest{Granted: GrantedToProto([]Granted{valid, invalid})})
+
+	// Then
+	is.Equal(status.Code(err), codes.InvalidArgument)
+	granted, err := dbManager.ListGranted()
+	is.NoErr(err)
+	is.Equal(len(granted), 0) // The valid grant is not saved either
+}
+
+func TestGRPC_delete_granted_batch(t *testing.T) {
+	// Given
+	is, dbManager, client := setupTestGRPC(t)
+	ctx := context.Background()
+	other := Granted{ContainerName: "hive/other", GrandScheme: "hive", GrandAction: "sync"}
+	missing := Granted{ContainerName: "hive/missing", GrandScheme: "hive", GrandAction: "sync"}
+	mockGranted(dbManager, fullGranted)
+	mockGranted(dbManager, other)
+	changes, cancel := dbManager.Subscribe(ChangeFilter{})
+	defer cancel()
+
+	// When
+	_, err := client.DeleteGranted(ctx, &syncerpb.DeleteGrantedRequest{Granted: GrantedToProto([]Granted{fullGranted, missing, other})})
+
+	// Then
+	is.NoErr(err)
+	granted, err := dbManager.ListGranted()
+	is.NoErr(err)
+	is.Equal(len(granted), 0)
+	is.Equal((<-changes).Operation, ChangeDeleted)
+	is.Equal((<-changes).Operation, ChangeDeleted)
+}
@@ -0,0 +1,129 @@
+package syncer
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrHistoryPruned is returned for a point-in-time query before the retained history
+var ErrHistoryPruned = errors.New("history before the retention period has been pruned")
+
+// WithHistoryRetention keeps the versions of records that were replaced or
+// deleted for the given duration. The zero value keeps them forever.
+func WithHistoryRetention(retention time.Duration) Option {
+	return func(dm *DbManager) {
+		dm.retention = retention
+	}
+}
+
+// QueryOption configures FindGranted and FindRequested
+type QueryOption func(*queryOptions)
+
+type queryOptions struct {
+	asOf time.Time
+}
+
+// AsOf runs the query against the records as they were stored at t, grants
+// are checked for being active at t as well. The parents of owners and the
+// implied actions are the ones that were registered at t. Decisions of these
+// queries are not logged, as they only reproduce a past state.
+func AsOf(t time.Time) QueryOption {
+	return func(o *queryOptions) {
+		o.asOf = t
+	}
+}
+
+// snapshot is the set of rows a query runs against, either the current table
+// or the versions of its history that were valid at a point in time
+type snapshot struct {
+	from     string
+	args     []any
+	historic bool
+	at       time.Time
+}
+
+func currentSnapshot(table string) snapshot {
+	return snapshot{from: table}
+}
+
+func historySnapshot(table string, at time.Time) snapshot {
+	return snapshot{
+		from:     fmt.Sprintf(`(SELECT * FROM %s_history WHERE valid_from <= ? AND (valid_to IS NULL OR valid_to > ?))`, table),
+		args:     []any{at.UnixNano(), at.UnixNano()},
+		historic: true,
+		at:       at,
+	}
+}
+
+// of returns the rows of another table at the same point in time
+func (s snapshot) of(table string) snapshot {
+	if !s.historic {
+		return currentSnapshot(table)
+	}
+
+	return historySnapshot(table, s.at)
+}
+
+// resolveQuery returns the time a query is evaluated at and the rows it runs against
+func (dm *DbManager) resolveQuery(table string, opts []QueryOption) (time.Time, snapshot, error) {
+	var o queryOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.asOf.IsZero() {
+		return dm.now(), currentSnapshot(table), nil
+	}
+	if dm.retention > 0 && o.asOf.Before(dm.now().Add(-dm.retention)) {
+		return time.Time{}, snapshot{}, fmt.Errorf("failed to query %s as of %s: %w", table, o.asOf.Format(time.RFC3339), ErrHistoryPruned)
+	}
+
+	return o.asOf, historySnapshot(table, o.asOf), nil
+}
+
+// historyTables are the tables whose rows are versioned in a table with the _history suffix
+var historyTables = []string{"requested", "granted", "owner_parents", "action_implications"}
+
+// recordHistory closes the current version of a record and copies the stored
+// row, if any, as the new version. Call it after every change of the record.
+func (dm *DbManager) recordHistory(q querier, table, columns, locator string) error {
+	return dm.recordVersion(q, table, "locator, "+columns, "locator = ?", locator)
+}
+
+// recordVersion is recordHistory for the row that where selects by its key
+func (dm *DbManager) recordVersion(q querier, table, columns, where string, key ...any) error {
+	now := dm.now().UnixNano()
+
+	_, err := q.Exec(fmt.Sprintf(`UPDATE %s_history SET valid_to = ? WHERE %s AND valid_to IS NULL`, table, where),
+		append([]any{now}, key...)...)
+	if err != nil {
+		return fmt.Errorf("failed to close %s history: %w", table, err)
+	}
+
+	_, err = q.Exec(fmt.Sprintf(`INSERT INTO %[1]s_history (%[2]s, valid_from)
+		SELECT %[2]s, ? FROM %[1]s WHERE %[3]s`, table, columns, where), append([]any{now}, key...)...)
+	if err != nil {
+		return fmt.Errorf("failed to write %s history: %w", table, err)
+	}
+
+	return dm.pruneHistory(q, table)
+}
+
+// pruneHistory removes the versions that were replaced before the retention
+// period of the given tables. It runs on every change, when the DbManager is
+// opened and in PurgeExpired, so a store without changes is pruned too.
+func (dm *DbManager) pruneHistory(q querier, tables ...string) error {
+	if dm.retention <= 0 {
+		return nil
+	}
+
+	cutoff := dm.now().Add(-dm.retention).UnixNano()
+	for _, table := range tables {
+		_, err := q.Exec(fmt.Sprintf(`DELETE FROM %s_history WHERE valid_to IS NOT NULL AND valid_to <= ?`, table), cutoff)
+		if err != nil {
+			return fmt.Errorf("failed to prune %s history: %w", table, err)
+		}
+	}
+
+	return nil
+}
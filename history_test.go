@@ -0,0 +1,199 @@
+package syncer
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestHistory_FindGranted_as_of(t *testing.T) {
+	// Given
+	is, dbManager, clock := setupTestDBWithClock(t)
+	created := clock.Now()
+	granted := Granted{ContainerName: "image/container", GrandScheme: "image"}
+	mockGranted(dbManager, granted)
+
+	clock.Advance(time.Hour)
+	updated := granted
+	updated.ExpiresAt = clock.Now().Add(24 * time.Hour)
+	mockGranted(dbManager, updated)
+
+	clock.Advance(time.Hour)
+	is.NoErr(dbManager.DeleteGranted(updated))
+	requested := []Requested{{ContainerName: "image/container", RequestScheme: "image"}}
+
+	// When / Then
+	result, err := dbManager.FindGranted(requested)
+	is.NoErr(err)
+	is.Equal(len(result), 0) // Deleted now
+
+	result, err = dbManager.FindGranted(requested, AsOf(created.Add(-time.Minute)))
+	is.NoErr(err)
+	is.Equal(len(result), 0) // Not saved yet
+
+	result, err = dbManager.FindGranted(requested, AsOf(created))
+	is.NoErr(err)
+	is.Equal(result, []Granted{granted})
+
+	result, err = dbManager.FindGranted(requested, AsOf(created.Add(90*time.Minute)))
+	is.NoErr(err)
+	is.Equal(result, []Granted{updated})
+
+	result, err = dbManager.FindGranted(requested, AsOf(clock.Now()))
+	is.NoErr(err)
+	is.Equal(len(result), 0) // Deleted at this moment
+}
+
+func TestHistory_FindGranted_as_of_checks_time_window_at_that_time(t *testing.T) {
+	// Given
+	is, dbManager, clock := setupTestDBWithClock(t)
+	saved := clock.Now()
+	mockGranted(dbManager, Granted{GrandScheme: "image", ExpiresAt: saved.Add(time.Hour)})
+	clock.Advance(2 * time.Hour)
+	requested := []Requested{{RequestScheme: "image"}}
+
+	// When
+	before, err := dbManager.FindGranted(requested, AsOf(saved.Add(30*time.Minute)))
+	is.NoErr(err)
+	after, err := dbManager.FindGranted(requested, AsOf(saved.Add(90*time.Minute)))
+	is.NoErr(err)
+
+	// Then
+	is.Equal(len(before), 1)
+	is.Equal(len(after), 0) // Expired by then, although it is still stored
+}
+
+func TestHistory_FindRequested_as_of(t *testing.T) {
+	// Given
+	is, dbManager, clock := setupTestDBWithClock(t)
+	saved := clock.Now()
+	requested := Requested{ContainerName: "image/container", RequestScheme: "image"}
+	mockRequested(dbManager, requested)
+	clock.Advance(time.Hour)
+	is.NoErr(dbManager.DeleteRequested([]Requested{requested}))
+	granted := []Granted{{ContainerName: "image/container", GrandScheme: "image"}}
+
+	// When
+	now, err := dbManager.FindRequested(granted)
+	is.NoErr(err)
+	past, err := dbManager.FindRequested(granted, AsOf(saved))
+	is.NoErr(err)
+
+	// Then
+	is.Equal(len(now), 0)
+	is.Equal(past, []Requested{requested})
+}
+
+func TestHistory_as_of_is_not_logged_as_decision(t *testing.T) {
+	// Given
+	is := is.New(t)
+	logger := &recordingLogger{}
+	dbManager, err := NewDbManager(WithDecisionLogger(logger))
+	is.NoErr(err)
+	defer dbManager.Close()
+
+	// When
+	_, err = dbManager.FindGranted([]Requested{{RequestScheme: "image"}}, AsOf(time.Now()))
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(logger.decisions), 0)
+}
+
+func TestHistory_retention(t *testing.T) {
+	// Given
+	is := is.New(t)
+	clock := &fakeClock{now: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}
+	dbManager, err := NewDbManager(WithClock(clock.Now), WithHistoryRetention(24*time.Hour))
+	is.NoErr(err)
+	defer dbManager.Close()
+	saved := clock.Now()
+	granted := Granted{GrandScheme: "image"}
+	mockGranted(dbManager, granted)
+	clock.Advance(time.Hour)
+	is.NoErr(dbManager.DeleteGranted(granted))
+
+	// When
+	clock.Advance(48 * time.Hour)
+	mockGranted(dbManager, Granted{GrandScheme: "other"}) // Every change prunes the history
+
+	// Then
+	_, err = dbManager.FindGranted([]Requested{{RequestScheme: "image"}}, AsOf(saved))
+	is.True(errors.Is(err, ErrHistoryPruned))
+
+	var versions int
+	is.NoErr(dbManager.db.QueryRow(`SELECT COUNT(*) FROM granted_history`).Scan(&versions))
+	is.Equal(versions, 1) // Only the current version of the other grant
+}
+
+func TestHistory_as_of_applies_the_rules_of_that_time(t *testing.T) {
+	// Given
+	is, dbManager, clock := setupTestDBWithClock(t)
+	mockGranted(dbManager, ownedGrant(confettiCms))
+	mockGranted(dbManager, Granted{GrandScheme: "hive", GrandAction: "admin"})
+	before := clock.Now()
+
+	clock.Advance(time.Hour)
+	is.NoErr(dbManager.SetParent(imageSource, confettiCms))
+	is.NoErr(dbManager.AddImpliedAction("hive", "admin", "sync"))
+	registered := clock.Now()
+
+	clock.Advance(time.Hour)
+	is.NoErr(dbManager.RemoveParent(imageSource))
+	is.NoErr(dbManager.RemoveImpliedAction("hive", "admin", "sync"))
+	requested := []Requested{ownedRequest(), {RequestScheme: "hive", RequestAction: "sync"}}
+
+	// When
+	beforeResult, err := dbManager.FindGranted(requested, AsOf(before))
+	is.NoErr(err)
+	registeredResult, err := dbManager.FindGranted(requested, AsOf(registered))
+	is.NoErr(err)
+	removedResult, err := dbManager.FindGranted(requested)
+	is.NoErr(err)
+
+	// Then
+	is.Equal(len(beforeResult), 0)
+	is.Equal(len(registeredResult), 2) // Inherited from the parent and implied by admin
+	is.Equal(len(removedResult), 0)
+}
+
+func TestHistory_retention_without_changes(t *testing.T) {
+	// Given
+	is := is.New(t)
+	clock := &fakeClock{now: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}
+	path := filepath.Join(t.TempDir(), "syncer.db")
+	dbManager, err := OpenDbManager(path, WithClock(clock.Now), WithHistoryRetention(24*time.Hour))
+	is.NoErr(err)
+	granted := Granted{GrandScheme: "image"}
+	mockGranted(dbManager, granted)
+	is.NoErr(dbManager.DeleteGranted(granted))
+	is.NoErr(dbManager.SetParent(imageSource, confettiCms))
+	is.NoErr(dbManager.RemoveParent(imageSource))
+	is.NoErr(dbManager.Close())
+	versions := func(dm *DbManager, table string) int {
+		var count int
+		is.NoErr(dm.db.QueryRow(`SELECT COUNT(*) FROM ` + table + `_history`).Scan(&count))
+		return count
+	}
+
+	// When
+	clock.Advance(48 * time.Hour)
+	reopened, err := OpenDbManager(path, WithClock(clock.Now), WithHistoryRetention(24*time.Hour))
+	is.NoErr(err)
+	defer reopened.Close()
+
+	// Then
+	is.Equal(versions(reopened, "granted"), 0) // Pruned on open
+	is.Equal(versions(reopened, "owner_parents"), 0)
+
+	mockGranted(reopened, granted)
+	is.NoErr(reopened.DeleteGranted(granted))
+	clock.Advance(48 * time.Hour)
+	purged, err := reopened.PurgeExpired()
+	is.NoErr(err)
+	is.Equal(purged, 0)
+	is.Equal(versions(reopened, "granted"), 0) // Pruned by a purge without expired grants
+}
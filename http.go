@@ -0,0 +1,203 @@
+package syncer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxRequestBodySize limits the size of the JSON bodies accepted by the HTTP API
+const maxRequestBodySize = 10 << 20
+
+// httpErrorResponse is the body of every unsuccessful response
+type httpErrorResponse struct {
+	Error string `json:"error"`
+	// Fields holds the fields that the scheme of a saved permission does not accept
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// NewHTTPHandler returns an http.Handler that exposes the DbManager as a JSON API.
+// Bodies use the JSON field names of Requested and Granted and accept a single
+// object or an array of objects.
+//
+//	GET  /requested       list all requested permissions
+//	POST /requested       save requested permissions
+//	POST /requested/find  find the requested permissions that match the granted permissions in the body
+//	GET  /granted         list all granted permissions
+//	POST /granted         save granted permissions
+//	POST /granted/find    find the granted permissions that match the requested permissions in the body
+func NewHTTPHandler(dm *DbManager) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/requested", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			requested, err := dm.ListRequested()
+			if err != nil {
+				writeHTTPError(w, http.StatusInternalServerError, err)
+				return
+			}
+			writeHTTPJSON(w, http.StatusOK, nonNil(requested))
+		case http.MethodPost:
+			requested, ok := readHTTPList[Requested](w, r)
+			if !ok {
+				return
+			}
+			if err := dm.SaveRequested(requested); err != nil {
+				writeHTTPSaveError(w, err)
+				return
+			}
+			writeHTTPJSON(w, http.StatusCreated, requested)
+		default:
+			writeMethodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+		}
+	})
+
+	mux.HandleFunc("/requested/find", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeMethodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+		granted, ok := readHTTPList[Granted](w, r)
+		if !ok {
+			return
+		}
+		requested, err := dm.FindRequested(granted)
+		if err != nil {
+			writeHTTPError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeHTTPJSON(w, http.StatusOK, nonNil(requested))
+	})
+
+	mux.HandleFunc("/granted", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			granted, err := dm.ListGranted()
+			if err != nil {
+				writeHTTPError(w, http.StatusInternalServerError, err)
+				return
+			}
+			writeHTTPJSON(w, http.StatusOK, nonNil(granted))
+		case http.MethodPost:
+			granted, ok := readHTTPList[Granted](w, r)
+			if !ok {
+				return
+			}
+			if err := dm.SaveGrantedBatch(granted); err != nil {
+				writeHTTPSaveError(w, err)
+				return
+			}
+			writeHTTPJSON(w, http.StatusCreated, granted)
+		default:
+			writeMethodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+		}
+	})
+
+	mux.HandleFunc("/granted/find", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeMethodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+		requested, ok := readHTTPList[Requested](w, r)
+		if !ok {
+			return
+		}
+		granted, err := dm.FindGranted(requested)
+		if err != nil {
+			writeHTTPError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeHTTPJSON(w, http.StatusOK, nonNil(granted))
+	})
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeHTTPError(w, http.StatusNotFound, fmt.Errorf("no route for %s", r.URL.Path))
+	})
+
+	return mux
+}
+
+// readHTTPList decodes a JSON object or array of objects from the request body.
+// When the body is invalid an error response is written and ok is false.
+func readHTTPList[T any](w http.ResponseWriter, r *http.Request) (list []T, ok bool) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeHTTPError(w, http.StatusRequestEntityTooLarge, err)
+			return nil, false
+		}
+		writeHTTPError(w, http.StatusBadRequest, fmt.Errorf("failed to read body: %w", err))
+		return nil, false
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		writeHTTPError(w, http.StatusBadRequest, errors.New("request body is empty"))
+		return nil, false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if data[0] == '[' {
+		err = decoder.Decode(&list)
+	} else {
+		var item T
+		err = decoder.Decode(&item)
+		list = []T{item}
+	}
+	if err == nil && decoder.More() {
+		err = errors.New("unexpected data after JSON value")
+	}
+	if err != nil {
+		writeHTTPError(w, http.StatusBadRequest, fmt.Errorf("invalid JSON body: %w", err))
+		return nil, false
+	}
+
+	return list, true
+}
+
+func writeHTTPJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeHTTPError(w http.ResponseWriter, status int, err error) {
+	writeHTTPJSON(w, status, httpErrorResponse{Error: err.Error()})
+}
+
+// writeHTTPSaveError responds with the fields of a ValidationError and with
+// a conflict for an OverlapError, other errors are internal
+func writeHTTPSaveError(w http.ResponseWriter, err error) {
+	var invalid *ValidationError
+	if errors.As(err, &invalid) {
+		writeHTTPJSON(w, http.StatusUnprocessableEntity, httpErrorResponse{Error: err.Error(), Fields: invalid.Fields})
+		return
+	}
+	var overlap *OverlapError
+	if errors.As(err, &overlap) {
+		writeHTTPError(w, http.StatusConflict, err)
+		return
+	}
+	writeHTTPError(w, http.StatusInternalServerError, err)
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter, r *http.Request, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeHTTPError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed on %s", r.Method, r.URL.Path))
+}
+
+// nonNil makes sure an empty result is encoded as [] instead of null
+func nonNil[T any](list []T) []T {
+	if list == nil {
+		return []T{}
+	}
+
+	return list
+}
@@ -0,0 +1,195 @@
+package syncer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func setupTestHTTP(t *testing.T) (*is.I, *DbManager, func(method, path, body string) *httptest.ResponseRecorder) {
+	is, dbManager := setupTestDB(t)
+	handler := NewHTTPHandler(dbManager)
+
+	return is, dbManager, func(method, path, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+}
+
+func TestHTTP_save_and_list_granted(t *testing.T) {
+	// Given
+	is, _, serve := setupTestHTTP(t)
+
+	// When
+	saved := serve(http.MethodPost, "/granted", `{"scheme": "image", "action": "pull", "ContainerName": "image/container", "container_name": "*"}`)
+	listed := serve(http.MethodGet, "/granted", "")
+
+	// Then
+	is.Equal(saved.Code, http.StatusCreated)
+	is.Equal(saved.Header().Get("Content-Type"), "application/json")
+	is.Equal(listed.Code, http.StatusOK)
+	var granted []Granted
+	is.NoErr(json.Unmarshal(listed.Body.Bytes(), &granted))
+	is.Equal(len(granted), 1)
+	is.Equal(granted[0].GrandScheme, "image")
+	is.Equal(granted[0].GrandAction, "pull")
+	is.Equal(granted[0].ContainerName, "image/container")
+	is.Equal(granted[0].GrandContainerName, "*")
+}
+
+func TestHTTP_save_and_list_requested(t *testing.T) {
+	// Given
+	is, _, serve := setupTestHTTP(t)
+
+	// When
+	saved := serve(http.MethodPost, "/requested", `[{"scheme": "image"}, {"scheme": "json", "destination_path": "/data"}]`)
+	listed := serve(http.MethodGet, "/requested", "")
+
+	// Then
+	is.Equal(saved.Code, http.StatusCreated)
+	is.Equal(listed.Code, http.StatusOK)
+	var requested []Requested
+	is.NoErr(json.Unmarshal(listed.Body.Bytes(), &requested))
+	is.Equal(len(requested), 2)
+}
+
+func TestHTTP_find_granted(t *testing.T) {
+	// Given
+	is, dbManager, serve := setupTestHTTP(t)
+	mockGranted(dbManager, Granted{GrandScheme: "image", GrandAction: "pull"})
+	mockGranted(dbManager, Granted{GrandScheme: "json", GrandAction: "pull"})
+
+	// When
+	response := serve(http.MethodPost, "/granted/find", `{"scheme": "image", "action": "pull"}`)
+
+	// Then
+	is.Equal(response.Code, http.StatusOK)
+	var granted []Granted
+	is.NoErr(json.Unmarshal(response.Body.Bytes(), &granted))
+	is.Equal(len(granted), 1)
+	is.Equal(granted[0].GrandScheme, "image")
+}
+
+func TestHTTP_find_requested(t *testing.T) {
+	// Given
+	is, dbManager, serve := setupTestHTTP(t)
+	mockRequested(dbManager, Requested{RequestScheme: "image"})
+	mockRequested(dbManager, Requested{RequestScheme: "json"})
+
+	// When
+	response := serve(http.MethodPost, "/requested/find", `[{"scheme": "*"}]`)
+
+	// Then
+	is.Equal(response.Code, http.StatusOK)
+	var requested []Requested
+	is.NoErr(json.Unmarshal(response.Body.Bytes(), &requested))
+	is.Equal(len(requested), 2)
+}
+
+func TestHTTP_find_without_matches_returns_empty_array(t *testing.T) {
+	// Given
+	is, _, serve := setupTestHTTP(t)
+
+	// When
+	response := serve(http.MethodPost, "/granted/find", `{"scheme": "image"}`)
+
+	// Then
+	is.Equal(response.Code, http.StatusOK)
+	is.Equal(strings.TrimSpace(response.Body.String()), "[]")
+}
+
+func TestHTTP_errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{name: "empty body", method: http.MethodPost, path: "/granted", body: "", status: http.StatusBadRequest},
+		{name: "invalid json", method: http.MethodPost, path: "/requested", body: "{", status: http.StatusBadRequest},
+		{name: "unknown field", method: http.MethodPost, path: "/granted/find", body: `{"schema": "image"}`, status: http.StatusBadRequest},
+		{name: "trailing data", method: http.MethodPost, path: "/requested/find", body: `{} {}`, status: http.StatusBadRequest},
+		{name: "wrong type", method: http.MethodPost, path: "/granted", body: `{"scheme": 1}`, status: http.StatusBadRequest},
+		{name: "method not allowed", method: http.MethodDelete, path: "/granted", status: http.StatusMethodNotAllowed},
+		{name: "find with get", method: http.MethodGet, path: "/granted/find", status: http.StatusMethodNotAllowed},
+		{name: "unknown route", method: http.MethodGet, path: "/roles", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			is, _, serve := setupTestHTTP(t)
+
+			// When
+			response := serve(tt.method, tt.path, tt.body)
+
+			// Then
+			is.Equal(response.Code, tt.status)
+			var body httpErrorResponse
+			is.NoErr(json.Unmarshal(response.Body.Bytes(), &body))
+			is.True(body.Error != "")
+		})
+	}
+}
+
+func TestHTTP_store_failure(t *testing.T) {
+	// Given
+	is, dbManager, serve := setupTestHTTP(t)
+	is.NoErr(dbManager.Close())
+
+	// When
+	response := serve(http.MethodGet, "/granted", "")
+
+	// Then
+	is.Equal(response.Code, http.StatusInternalServerError)
+}
+
+func TestHTTP_body_too_large(t *testing.T) {
+	// Given
+	is, _, serve := setupTestHTTP(t)
+	body := `{"description": "` + strings.Repeat("a", maxRequestBodySize) + `"}`
+
+	// When
+	response := serve(http.MethodPost, "/granted", body)
+
+	// Then
+	is.Equal(response.Code, http.StatusRequestEntityTooLarge)
+}
+
+func TestHTTP_save_invalid_for_scheme(t *testing.T) {
+	// Given
+	is, dbManager, serve := setupTestHTTP(t)
+	is.NoErr(dbManager.RegisterScheme(Scheme{Name: "hive", RequiredGranted: []string{"ExposePath"}}))
+
+	// When
+	response := serve(http.MethodPost, "/granted", `{"scheme": "hive", "action": "sync", "ContainerName": "hive/container"}`)
+
+	// Then
+	is.Equal(response.Code, http.StatusUnprocessableEntity)
+	var body httpErrorResponse
+	is.NoErr(json.Unmarshal(response.Body.Bytes(), &body))
+	is.Equal(body.Fields, []FieldError{{Field: "ExposePath", Message: "is required"}})
+}
+
+func TestHTTP_save_granted_batch_is_atomic(t *testing.T) {
+	// Given
+	is, dbManager, serve := setupTestHTTP(t)
+	is.NoErr(dbManager.RegisterScheme(Scheme{Name: "hive", RequiredGranted: []string{"ExposePath"}}))
+
+	// When
+	response := serve(http.MethodPost, "/granted", `[{"scheme": "hive", "action": "sync", "ContainerName": "hive/container", "expose_path": "/public"},
+		{"scheme": "hive", "action": "sync", "ContainerName": "hive/other"}]`)
+
+	// Then
+	is.Equal(response.Code, http.StatusUnprocessableEntity)
+	granted, err := dbManager.ListGranted()
+	is.NoErr(err)
+	is.Equal(len(granted), 0) // The valid grant is not saved either
+}
@@ -0,0 +1,289 @@
+package syncer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrOwnerCycle is returned when a parent would make an owner its own ancestor
+var ErrOwnerCycle = errors.New("parent would make the owner its own ancestor")
+
+// Owner is an organization and repository, the owner of a source or an umbrella
+type Owner struct {
+	Organization string `json:"organization"`
+	Repository   string `json:"repository"`
+}
+
+// OwnerParent is an entry of the owner registry
+type OwnerParent struct {
+	Child  Owner `json:"child"`
+	Parent Owner `json:"parent"`
+}
+
+// InheritedGrant is a grant found for a request, defined on the owners of the
+// request or on their ancestors
+type InheritedGrant struct {
+	Granted Granted `json:"granted"`
+	// Depth is the number of parent steps from the source and umbrella owners
+	// of the request to the ones of the grant, 0 for a grant on the same owners
+	Depth int `json:"depth"`
+}
+
+// SetParent registers the parent of an owner, e.g. the umbrella repository of
+// a source repository. Grants on an owner also apply to requests of its
+// descendants. Owners are compared case-insensitively, like in locators.
+func (dm *DbManager) SetParent(child, parent Owner) error {
+	child, parent = child.normalize(), parent.normalize()
+	if child.Organization == "" || child.Repository == "" || parent.Organization == "" || parent.Repository == "" {
+		return errors.New("failed to set parent: organization and repository are required")
+	}
+
+	tx, err := dm.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	tree, err := loadOwnerTree(tx)
+	if err != nil {
+		return err
+	}
+	if child == parent || tree.isAncestor(child, parent) {
+		return fmt.Errorf("failed to set parent of %s: %w", child, ErrOwnerCycle)
+	}
+
+	_, err = tx.Exec(`INSERT INTO owner_parents (`+ownerParentColumns+`)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(organization, repository) DO UPDATE SET
+			parent_organization=excluded.parent_organization,
+			parent_repository=excluded.parent_repository`,
+		child.Organization, child.Repository, parent.Organization, parent.Repository)
+	if err != nil {
+		return fmt.Errorf("failed to save owner parent: %w", err)
+	}
+	if err := dm.recordOwnerParent(tx, child); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveParent removes the parent of an owner
+func (dm *DbManager) RemoveParent(child Owner) error {
+	child = child.normalize()
+
+	tx, err := dm.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM owner_parents WHERE organization = ? AND repository = ?`, child.Organization, child.Repository)
+	if err != nil {
+		return fmt.Errorf("failed to delete owner parent: %w", err)
+	}
+	if err := dm.recordOwnerParent(tx, child); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// recordOwnerParent records the change of the parent of an owner in its history
+func (dm *DbManager) recordOwnerParent(q querier, child Owner) error {
+	return dm.recordVersion(q, "owner_parents", ownerParentColumns, "organization = ? AND repository = ?", child.Organization, child.Repository)
+}
+
+// ListParents returns the owner registry ordered by child
+func (dm *DbManager) ListParents() ([]OwnerParent, error) {
+	tree, err := loadOwnerTree(dm.db)
+	if err != nil {
+		return nil, err
+	}
+
+	parents := make([]OwnerParent, 0, len(tree))
+	for child, parent := range tree {
+		parents = append(parents, OwnerParent{Child: child, Parent: parent})
+	}
+	sort.Slice(parents, func(i, j int) bool {
+		return parents[i].Child.String() < parents[j].Child.String()
+	})
+
+	return parents, nil
+}
+
+// Ancestors returns the parent of an owner, its parent and so on
+func (dm *DbManager) Ancestors(owner Owner) ([]Owner, error) {
+	tree, err := loadOwnerTree(dm.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return tree.ancestors(owner.normalize()), nil
+}
+
+// FindGrantedWithDepth finds the same grants as FindGranted, together with the
+// inheritance depth at which they match the closest request
+func (dm *DbManager) FindGrantedWithDepth(requested []Requested, opts ...QueryOption) ([]InheritedGrant, error) {
+	return dm.findGrantedWithDepth(requested, opts)
+}
+
+func (o Owner) String() string {
+	return o.Organization + "/" + o.Repository
+}
+
+func (o Owner) normalize() Owner {
+	return Owner{Organization: strings.ToLower(o.Organization), Repository: strings.ToLower(o.Repository)}
+}
+
+// ownerTree maps an owner to its parent
+type ownerTree map[Owner]Owner
+
+// ownerParentColumns are the columns of owner_parents and its history
+const ownerParentColumns = "organization, repository, parent_organization, parent_repository"
+
+func loadOwnerTree(q querier) (ownerTree, error) {
+	return loadOwnerTreeIn(q, currentSnapshot("owner_parents"))
+}
+
+func loadOwnerTreeIn(q querier, from snapshot) (ownerTree, error) {
+	rows, err := q.Query(fmt.Sprintf(`SELECT %s FROM %s`, ownerParentColumns, from.from), from.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query owner parents: %w", err)
+	}
+	defer rows.Close()
+
+	tree := ownerTree{}
+	for rows.Next() {
+		var child, parent Owner
+		if err := rows.Scan(&child.Organization, &child.Repository, &parent.Organization, &parent.Repository); err != nil {
+			return nil, fmt.Errorf("failed to scan owner parent: %w", err)
+		}
+		tree[child] = parent
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read owner parents: %w", err)
+	}
+
+	return tree, nil
+}
+
+// ancestors returns the ancestors of an owner, the parent first
+func (t ownerTree) ancestors(owner Owner) []Owner {
+	var ancestors []Owner
+	for parent, ok := t[owner]; ok; parent, ok = t[parent] {
+		ancestors = append(ancestors, parent)
+	}
+
+	return ancestors
+}
+
+// isAncestor reports whether ancestor is an ancestor of owner
+func (t ownerTree) isAncestor(ancestor, owner Owner) bool {
+	for _, a := range t.ancestors(owner) {
+		if a == ancestor {
+			return true
+		}
+	}
+
+	return false
+}
+
+// descendants returns every owner below the owner, ordered by name
+func (t ownerTree) descendants(owner Owner) []Owner {
+	var descendants []Owner
+	for child := range t {
+		if t.isAncestor(owner, child) {
+			descendants = append(descendants, child)
+		}
+	}
+	sort.Slice(descendants, func(i, j int) bool {
+		return descendants[i].String() < descendants[j].String()
+	})
+
+	return descendants
+}
+
+// inheritedRequested is a request moved up to the owners of its ancestors
+type inheritedRequested struct {
+	Requested
+	depth int
+}
+
+// liftRequested returns the request itself and the request with its source
+// and umbrella owners replaced by every combination of their ancestors.
+// Requested values that equal the owner move along, wildcards stay.
+func (t ownerTree) liftRequested(r Requested) []inheritedRequested {
+	variants := []inheritedRequested{{Requested: r}}
+	if len(t) == 0 {
+		return variants
+	}
+
+	source := Owner{r.SourceOrganization, r.SourceRepository}
+	umbrella := Owner{r.UmbrellaOrganization, r.UmbrellaRepository}
+	sources := append([]Owner{source}, t.ancestors(source.normalize())...)
+	umbrellas := append([]Owner{umbrella}, t.ancestors(umbrella.normalize())...)
+	for sourceDepth, s := range sources {
+		for umbrellaDepth, u := range umbrellas {
+			if sourceDepth == 0 && umbrellaDepth == 0 {
+				continue
+			}
+			v := r
+			v.SourceOrganization, v.RequestSourceOrganization = moveOwner(r.SourceOrganization, r.RequestSourceOrganization, s.Organization)
+			v.SourceRepository, v.RequestSourceRepository = moveOwner(r.SourceRepository, r.RequestSourceRepository, s.Repository)
+			v.UmbrellaOrganization, v.RequestUmbrellaOrganization = moveOwner(r.UmbrellaOrganization, r.RequestUmbrellaOrganization, u.Organization)
+			v.UmbrellaRepository, v.RequestUmbrellaRepository = moveOwner(r.UmbrellaRepository, r.RequestUmbrellaRepository, u.Repository)
+			variants = append(variants, inheritedRequested{Requested: v, depth: sourceDepth + umbrellaDepth})
+		}
+	}
+
+	return variants
+}
+
+// lowerGranted returns the grant itself and the grant with its source and
+// umbrella owners replaced by every combination of their descendants
+func (t ownerTree) lowerGranted(g Granted) []Granted {
+	variants := []Granted{g}
+	if len(t) == 0 {
+		return variants
+	}
+
+	source := Owner{g.SourceOrganization, g.SourceRepository}
+	umbrella := Owner{g.UmbrellaOrganization, g.UmbrellaRepository}
+	sources := append([]Owner{source}, t.descendants(source.normalize())...)
+	umbrellas := append([]Owner{umbrella}, t.descendants(umbrella.normalize())...)
+	for i, s := range sources {
+		for j, u := range umbrellas {
+			if i == 0 && j == 0 {
+				continue
+			}
+			v := g
+			v.SourceOrganization, v.GrandSourceOrganization = moveOwner(g.SourceOrganization, g.GrandSourceOrganization, s.Organization)
+			v.SourceRepository, v.GrandSourceRepository = moveOwner(g.SourceRepository, g.GrandSourceRepository, s.Repository)
+			v.UmbrellaOrganization, v.GrandUmbrellaOrganization = moveOwner(g.UmbrellaOrganization, g.GrandUmbrellaOrganization, u.Organization)
+			v.UmbrellaRepository, v.GrandUmbrellaRepository = moveOwner(g.UmbrellaRepository, g.GrandUmbrellaRepository, u.Repository)
+			variants = append(variants, v)
+		}
+	}
+
+	return variants
+}
+
+// moveOwner replaces a resource by another owner name, the value moves along when it names the resource
+func moveOwner(resource, value, to string) (string, string) {
+	if value == resource {
+		return to, to
+	}
+
+	return to, value
+}
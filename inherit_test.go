@@ -0,0 +1,163 @@
+package syncer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+var (
+	confettiSites = Owner{Organization: "confetti-sites", Repository: "sites"}
+	confettiCms   = Owner{Organization: "confetti-cms", Repository: "cms"}
+	imageSource   = Owner{Organization: "confetti-cms", Repository: "image"}
+)
+
+// ownedRequest is a request of a container in the image source repository
+func ownedRequest() Requested {
+	return Requested{
+		ContainerName: "image/container", RequestContainerName: "image/container",
+		SourceOrganization: "confetti-cms", RequestSourceOrganization: "confetti-cms",
+		SourceRepository: "image", RequestSourceRepository: "image",
+		RequestScheme: "image", RequestAction: "pull",
+	}
+}
+
+// ownedGrant is a grant on the container for the sources of owner
+func ownedGrant(owner Owner) Granted {
+	return Granted{
+		ContainerName: "image/container", GrandContainerName: "image/container",
+		SourceOrganization: owner.Organization, GrandSourceOrganization: owner.Organization,
+		SourceRepository: owner.Repository, GrandSourceRepository: owner.Repository,
+		GrandScheme: "image", GrandAction: "pull",
+	}
+}
+
+func TestSetParent(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+
+	// When
+	is.NoErr(dbManager.SetParent(imageSource, confettiCms))
+	is.NoErr(dbManager.SetParent(Owner{"Confetti-CMS", "CMS"}, confettiSites)) // Owners are compared case-insensitively
+	cycleErr := dbManager.SetParent(confettiSites, imageSource)
+	selfErr := dbManager.SetParent(confettiCms, confettiCms)
+	emptyErr := dbManager.SetParent(Owner{Organization: "confetti-cms"}, confettiSites)
+
+	// Then
+	is.True(errors.Is(cycleErr, ErrOwnerCycle))
+	is.True(errors.Is(selfErr, ErrOwnerCycle))
+	is.True(emptyErr != nil)
+	ancestors, err := dbManager.Ancestors(imageSource)
+	is.NoErr(err)
+	is.Equal(ancestors, []Owner{confettiCms, confettiSites})
+	parents, err := dbManager.ListParents()
+	is.NoErr(err)
+	is.Equal(parents, []OwnerParent{{Child: confettiCms, Parent: confettiSites}, {Child: imageSource, Parent: confettiCms}})
+}
+
+func TestFindGranted_inherited_from_ancestors(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	mockGranted(dbManager, ownedGrant(confettiSites))
+	without, err := dbManager.FindGranted([]Requested{ownedRequest()})
+	is.NoErr(err)
+	is.NoErr(dbManager.SetParent(imageSource, confettiCms))
+	is.NoErr(dbManager.SetParent(confettiCms, confettiSites))
+	mockGranted(dbManager, ownedGrant(confettiCms))
+	mockGranted(dbManager, ownedGrant(imageSource))
+
+	// When
+	granted, err := dbManager.FindGrantedWithDepth([]Requested{ownedRequest()})
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(without), 0) // Without the registry the grant on the umbrella does not cascade
+	is.Equal(len(granted), 3)
+	depths := map[string]int{}
+	for _, g := range granted {
+		depths[g.Granted.SourceRepository] = g.Depth
+	}
+	is.Equal(depths, map[string]int{"image": 0, "cms": 1, "sites": 2})
+}
+
+func TestFindGrantedWithDepth_logs_decisions_and_applies_as_of(t *testing.T) {
+	// Given
+	is := is.New(t)
+	clock := &fakeClock{now: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}
+	recorder := &recordingLogger{}
+	dbManager, err := NewDbManager(WithClock(clock.Now), WithDecisionLogger(recorder))
+	is.NoErr(err)
+	defer dbManager.Close()
+	mockGranted(dbManager, ownedGrant(confettiCms))
+	before := clock.Now()
+	clock.Advance(time.Hour)
+	is.NoErr(dbManager.SetParent(imageSource, confettiCms))
+
+	// When
+	granted, err := dbManager.FindGrantedWithDepth([]Requested{ownedRequest()})
+	is.NoErr(err)
+	historic, err := dbManager.FindGrantedWithDepth([]Requested{ownedRequest()}, AsOf(before))
+	is.NoErr(err)
+
+	// Then
+	is.Equal(granted, []InheritedGrant{{Granted: ownedGrant(confettiCms), Depth: 1}})
+	is.Equal(historic, []InheritedGrant{}) // The parent was not registered yet
+	is.Equal(len(recorder.decisions), 1)   // Historic queries are not logged
+	is.True(recorder.decisions[0].Allowed)
+}
+
+func TestFindGranted_inherited_keeps_other_dimensions(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	is.NoErr(dbManager.SetParent(imageSource, confettiCms))
+	push := ownedGrant(confettiCms)
+	push.GrandAction = "push"
+	mockGranted(dbManager, push)
+
+	// When
+	granted, err := dbManager.FindGranted([]Requested{ownedRequest()})
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(granted), 0)
+}
+
+func TestFindRequested_inherited_by_descendants(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	is.NoErr(dbManager.SetParent(imageSource, confettiCms))
+	mockRequested(dbManager, ownedRequest())
+
+	// When
+	requested, err := dbManager.FindRequested([]Granted{ownedGrant(confettiCms)})
+	is.NoErr(err)
+	is.NoErr(dbManager.RemoveParent(imageSource))
+	removed, err := dbManager.FindRequested([]Granted{ownedGrant(confettiCms)})
+	is.NoErr(err)
+
+	// Then
+	is.Equal(requested, []Requested{ownedRequest()})
+	is.Equal(len(removed), 0)
+}
+
+func TestDecision_allowed_by_inherited_grant(t *testing.T) {
+	// Given
+	is, _ := setupTestDB(t)
+	recorder := &recordingLogger{}
+	dbManager, err := NewDbManager(WithDecisionLogger(recorder))
+	is.NoErr(err)
+	defer dbManager.Close()
+	is.NoErr(dbManager.SetParent(imageSource, confettiCms))
+	mockGranted(dbManager, ownedGrant(confettiCms))
+
+	// When
+	_, err = dbManager.FindGranted([]Requested{ownedRequest()})
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(recorder.decisions), 1)
+	is.True(recorder.decisions[0].Allowed)
+	is.Equal(recorder.decisions[0].Matched, []string{grantedLocator(ownedGrant(confettiCms))})
+}
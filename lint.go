@@ -0,0 +1,168 @@
+package syncer
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Severity is the importance of a lint finding
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// IDs of the built-in lint rules
+const (
+	RuleTooManyWildcards    = "too-many-wildcards"
+	RuleWildcardSchemeWrite = "wildcard-scheme-write"
+	RuleEmptyContainerName  = "empty-container-name"
+	RuleRedundantGrant      = "redundant-grant"
+)
+
+// maxWildcards is the number of wildcard dimensions a grant may have before
+// RuleTooManyWildcards reports it
+const maxWildcards = 2
+
+// writeActions are the actions that change data, a wildcard action includes them
+var writeActions = map[string]bool{"*": true, "push": true, "write": true, "delete": true, "admin": true}
+
+// LintRule checks a grant. Check returns a message for every problem it
+// finds, all holds every grant that is linted, including the grant itself.
+type LintRule struct {
+	ID       string
+	Severity Severity
+	Check    func(g Granted, all []Granted) []string
+
+	// checkWithRules replaces Check when the stored matching rules are known, see DbManager.LintGranted
+	checkWithRules func(g Granted, all []Granted, rules matchRules) []string
+}
+
+// LintFinding is a problem a rule found in a grant
+type LintFinding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Granted  Granted  `json:"granted"`
+	Message  string   `json:"message"`
+}
+
+// Linter runs lint rules over grants
+type Linter struct {
+	rules []LintRule
+}
+
+// NewLinter creates a Linter with the built-in rules
+func NewLinter() *Linter {
+	return &Linter{rules: []LintRule{
+		{ID: RuleTooManyWildcards, Severity: SeverityWarning, Check: checkTooManyWildcards},
+		{ID: RuleWildcardSchemeWrite, Severity: SeverityError, Check: checkWildcardSchemeWrite},
+		{ID: RuleEmptyContainerName, Severity: SeverityError, Check: checkEmptyContainerName},
+		{ID: RuleRedundantGrant, Severity: SeverityWarning, Check: func(g Granted, all []Granted) []string {
+			return checkRedundantGrant(g, all, matchRules{})
+		}, checkWithRules: checkRedundantGrant},
+	}}
+}
+
+// Register adds a rule, its ID must be unique
+func (l *Linter) Register(rule LintRule) error {
+	if rule.ID == "" || rule.Check == nil {
+		return errors.New("failed to register lint rule: ID and Check are required")
+	}
+	for _, existing := range l.rules {
+		if existing.ID == rule.ID {
+			return fmt.Errorf("failed to register lint rule: %s is already registered", rule.ID)
+		}
+	}
+	l.rules = append(l.rules, rule)
+
+	return nil
+}
+
+// Rules returns the registered rules in the order they run
+func (l *Linter) Rules() []LintRule {
+	return append([]LintRule{}, l.rules...)
+}
+
+// Lint runs every rule over every grant, the findings are ordered by grant
+// and then by rule. Like Subsumes it does not apply stored rules.
+func (l *Linter) Lint(granted []Granted) []LintFinding {
+	return l.lint(granted, matchRules{})
+}
+
+func (l *Linter) lint(granted []Granted, rules matchRules) []LintFinding {
+	findings := []LintFinding{}
+	for _, g := range granted {
+		for _, rule := range l.rules {
+			check := rule.Check
+			if rule.checkWithRules != nil {
+				check = func(g Granted, all []Granted) []string {
+					return rule.checkWithRules(g, all, rules)
+				}
+			}
+			for _, message := range check(g, granted) {
+				findings = append(findings, LintFinding{Rule: rule.ID, Severity: rule.Severity, Granted: g, Message: message})
+			}
+		}
+	}
+
+	return findings
+}
+
+// LintGranted runs the linter over all stored grants, the built-in rules
+// apply the stored owner parents and implied actions
+func (dm *DbManager) LintGranted(linter *Linter) ([]LintFinding, error) {
+	granted, err := dm.ListGranted()
+	if err != nil {
+		return nil, err
+	}
+	rules, err := loadMatchRules(dm.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return linter.lint(granted, rules), nil
+}
+
+func checkTooManyWildcards(g Granted, _ []Granted) []string {
+	var wildcards []string
+	for _, v := range pairValues(Requested{}, g) {
+		if v.granted == "*" {
+			wildcards = append(wildcards, string(v.dimension))
+		}
+	}
+	if len(wildcards) <= maxWildcards {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("grant has %d wildcard dimensions %v, at most %d are expected", len(wildcards), wildcards, maxWildcards)}
+}
+
+func checkWildcardSchemeWrite(g Granted, _ []Granted) []string {
+	if g.GrandScheme != "*" || !writeActions[g.GrandAction] {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("grant allows the write action %q on every scheme", g.GrandAction)}
+}
+
+func checkEmptyContainerName(g Granted, _ []Granted) []string {
+	if g.ContainerName != "" {
+		return nil
+	}
+
+	return []string{"grant is on an empty container name"}
+}
+
+// checkRedundantGrant reports a grant that other grants subsume, see AnalyzeRedundancy
+func checkRedundantGrant(g Granted, all []Granted, rules matchRules) []string {
+	covering := coveringGrants(g, all, rules)
+	if len(covering) == 0 {
+		return nil
+	}
+	other := covering[0].Granted
+
+	return []string{fmt.Sprintf("grant is covered by the %s %s grant on %s (target %s)",
+		other.GrandScheme, other.GrandAction, other.ContainerName, other.Target)}
+}
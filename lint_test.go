@@ -0,0 +1,148 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestLinter_builtin_rules(t *testing.T) {
+	tests := []struct {
+		name    string
+		granted []Granted
+		rules   []string
+	}{
+		{
+			name:    "specific grant",
+			granted: []Granted{{ContainerName: "image/container", GrandScheme: "image", GrandAction: "pull"}},
+		},
+		{
+			name:    "too many wildcards",
+			granted: []Granted{{ContainerName: "image/container", GrandScheme: "image", GrandAction: "*", GrandSourceOrganization: "*", GrandContainerName: "*"}},
+			rules:   []string{RuleTooManyWildcards},
+		},
+		{
+			name:    "wildcard scheme with write action",
+			granted: []Granted{{ContainerName: "image/container", GrandScheme: "*", GrandAction: "push"}},
+			rules:   []string{RuleWildcardSchemeWrite},
+		},
+		{
+			name:    "wildcard scheme with read action",
+			granted: []Granted{{ContainerName: "image/container", GrandScheme: "*", GrandAction: "pull"}},
+		},
+		{
+			name:    "empty container name",
+			granted: []Granted{{GrandScheme: "image", GrandAction: "pull"}},
+			rules:   []string{RuleEmptyContainerName},
+		},
+		{
+			name: "subset of another grant",
+			granted: []Granted{
+				{ContainerName: "image/container", Target: "cmd", GrandScheme: "image", GrandAction: "pull", GrandTarget: "*"},
+				{ContainerName: "image/container", Target: "cmd", GrandScheme: "image", GrandAction: "pull", GrandTarget: "cmd"},
+			},
+			rules: []string{RuleRedundantGrant},
+		},
+		{
+			name: "grants that only differ in description",
+			granted: []Granted{
+				{ContainerName: "image/container", GrandScheme: "image", GrandAction: "pull", Description: "a"},
+				{ContainerName: "image/container", GrandScheme: "image", GrandAction: "pull", Description: "b"},
+			},
+			rules: []string{RuleRedundantGrant}, // Only one of both is reported
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			findings := NewLinter().Lint(tt.granted)
+
+			rules := []string{}
+			for _, finding := range findings {
+				rules = append(rules, finding.Rule)
+			}
+			if tt.rules == nil {
+				tt.rules = []string{}
+			}
+			is.Equal(rules, tt.rules)
+		})
+	}
+}
+
+func TestLinter_Register(t *testing.T) {
+	// Given
+	is := is.New(t)
+	linter := NewLinter()
+	rule := LintRule{
+		ID:       "missing-description",
+		Severity: SeverityInfo,
+		Check: func(g Granted, _ []Granted) []string {
+			if g.Description == "" {
+				return []string{"grant has no description"}
+			}
+			return nil
+		},
+	}
+
+	// When
+	err := linter.Register(rule)
+	duplicateErr := linter.Register(rule)
+	incompleteErr := linter.Register(LintRule{ID: "incomplete"})
+	findings := linter.Lint([]Granted{{ContainerName: "image/container", GrandScheme: "image", GrandAction: "pull"}})
+
+	// Then
+	is.NoErr(err)
+	is.True(duplicateErr != nil)
+	is.True(incompleteErr != nil)
+	is.Equal(len(linter.Rules()), 5)
+	is.Equal(findings, []LintFinding{{
+		Rule:     "missing-description",
+		Severity: SeverityInfo,
+		Granted:  Granted{ContainerName: "image/container", GrandScheme: "image", GrandAction: "pull"},
+		Message:  "grant has no description",
+	}})
+}
+
+func TestLintGranted(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	mockGranted(dbManager, Granted{ContainerName: "image/container", GrandScheme: "image", GrandAction: "pull"})
+	mockGranted(dbManager, Granted{ContainerName: "image/container", GrandScheme: "*", GrandAction: "*"})
+
+	// When
+	findings, err := dbManager.LintGranted(NewLinter())
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(findings), 2)
+	for _, finding := range findings {
+		switch finding.Rule {
+		case RuleWildcardSchemeWrite:
+			is.Equal(finding.Severity, SeverityError)
+			is.Equal(finding.Granted.GrandScheme, "*")
+		case RuleRedundantGrant:
+			is.Equal(finding.Granted.GrandScheme, "image")
+		default:
+			t.Fatalf("unexpected finding %+v", finding)
+		}
+	}
+}
+
+func TestLintGranted_applies_implied_actions(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	is.NoErr(imageHierarchy(dbManager))
+	mockGranted(dbManager, Granted{ContainerName: "image/container", GrandScheme: "image", GrandAction: "push"})
+	mockGranted(dbManager, Granted{ContainerName: "image/container", GrandScheme: "image", GrandAction: "pull"})
+
+	// When
+	findings, err := dbManager.LintGranted(NewLinter())
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(findings), 1)
+	is.Equal(findings[0].Rule, RuleRedundantGrant)
+	is.Equal(findings[0].Granted.GrandAction, "pull")
+}
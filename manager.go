@@ -3,24 +3,70 @@ package syncer
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
 type DbManager struct {
-	db *sql.DB
+	db      *sql.DB
+	changes *changeHub
+	now     func() time.Time
+	// actor is recorded in the audit and decision log, see AsActor
+	actor     string
+	decisions DecisionLogger
+	retention time.Duration
+	// schemes validate saved permissions, see RegisterScheme
+	schemes *schemeRegistry
+	// checkOverlaps rejects overlapping destination paths, see WithOverlapCheck
+	checkOverlaps bool
 }
 
-func NewDbManager() (*DbManager, error) {
-	db, err := sql.Open("sqlite3", ":memory:")
+// Option configures a DbManager
+type Option func(*DbManager)
+
+// WithClock replaces time.Now as the source of the current time, e.g. to
+// decide whether a grant has expired
+func WithClock(now func() time.Time) Option {
+	return func(dm *DbManager) {
+		dm.now = now
+	}
+}
+
+// querier is implemented by *sql.DB and *sql.Tx, so queries can run inside and outside a transaction
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// NewDbManager creates a DbManager backed by an in-memory database
+func NewDbManager(opts ...Option) (*DbManager, error) {
+	return OpenDbManager(":memory:", opts...)
+}
+
+// OpenDbManager creates a DbManager backed by the SQLite database file at path.
+// The file is created when it does not exist yet.
+func OpenDbManager(path string, opts ...Option) (*DbManager, error) {
+	db, err := sql.Open("sqlite3", path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	manager := &DbManager{db: db}
+	// SQLite allows a single writer and every connection to :memory: opens a
+	// new, empty database, so all queries share one connection
+	db.SetMaxOpenConns(1)
+
+	manager := &DbManager{db: db, changes: newChangeHub(), now: time.Now, schemes: newSchemeRegistry()}
+	for _, opt := range opts {
+		opt(manager)
+	}
 	if err := manager.initDB(); err != nil {
 		return nil, err
 	}
+	if err := manager.pruneHistory(manager.db, historyTables...); err != nil {
+		return nil, err
+	}
 
 	return manager, nil
 }
@@ -31,6 +77,7 @@ func (dm *DbManager) initDB() error {
 	CREATE TABLE IF NOT EXISTS requested (
 		locator TEXT PRIMARY KEY,
 		description TEXT,
+		host TEXT,
 		expose_path TEXT,
 		scheme TEXT,
 		action TEXT,
@@ -60,6 +107,7 @@ func (dm *DbManager) initDB() error {
 	CREATE TABLE IF NOT EXISTS granted (
 		locator TEXT PRIMARY KEY,
 		description TEXT,
+		host TEXT,
 		expose_path TEXT,
 		scheme TEXT,
 		action TEXT,
@@ -76,14 +124,289 @@ func (dm *DbManager) initDB() error {
 		grand_umbrella_organization TEXT,
 		grand_umbrella_repository TEXT,
 		grand_container_name TEXT,
-		grand_target TEXT
+		grand_target TEXT,
+		not_before INTEGER,
+		expires_at INTEGER
 	);`
 
 	_, err = dm.db.Exec(query)
+	if err != nil {
+		return fmt.Errorf("failed to create granted table: %w", err)
+	}
+
+	// Create the append-only audit log, the filter columns are copied from the record
+	query = `
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		actor TEXT,
+		timestamp INTEGER,
+		operation TEXT,
+		kind TEXT,
+		locator TEXT,
+		host TEXT,
+		container_name TEXT,
+		target TEXT,
+		source_organization TEXT,
+		umbrella_organization TEXT,
+		before_value TEXT,
+		after_value TEXT
+	);`
+
+	_, err = dm.db.Exec(query)
+	if err != nil {
+		return fmt.Errorf("failed to create audit_log table: %w", err)
+	}
+
+	// Add the columns that were introduced after the table was created
+	if err := dm.addColumnIfMissing("granted", "not_before", "INTEGER"); err != nil {
+		return err
+	}
+	if err := dm.addColumnIfMissing("granted", "expires_at", "INTEGER"); err != nil {
+		return err
+	}
+
+	if err := dm.initHistory(); err != nil {
+		return err
+	}
+
+	if err := dm.initRoles(); err != nil {
+		return err
+	}
+
+	if err := dm.initOwners(); err != nil {
+		return err
+	}
+
+	return dm.initActions()
+}
+
+// initHistory creates the tables with the versions of the requested and
+// granted records. A version is valid from valid_from until valid_to, the
+// current version has no valid_to.
+func (dm *DbManager) initHistory() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS requested_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		locator TEXT,
+		description TEXT,
+		host TEXT,
+		expose_path TEXT,
+		source_organization TEXT,
+		source_repository TEXT,
+		umbrella_organization TEXT,
+		umbrella_repository TEXT,
+		container_name TEXT,
+		target TEXT,
+		request_scheme TEXT,
+		request_action TEXT,
+		request_source_organization TEXT,
+		request_source_repository TEXT,
+		request_umbrella_organization TEXT,
+		request_umbrella_repository TEXT,
+		request_container_name TEXT,
+		request_target TEXT,
+		valid_from INTEGER,
+		valid_to INTEGER
+	);
+	CREATE INDEX IF NOT EXISTS requested_history_locator ON requested_history (locator, valid_to);`
+
+	_, err := dm.db.Exec(query)
+	if err != nil {
+		return fmt.Errorf("failed to create requested_history table: %w", err)
+	}
+
+	query = `
+	CREATE TABLE IF NOT EXISTS granted_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		locator TEXT,
+		description TEXT,
+		host TEXT,
+		expose_path TEXT,
+		source_organization TEXT,
+		source_repository TEXT,
+		umbrella_organization TEXT,
+		umbrella_repository TEXT,
+		container_name TEXT,
+		target TEXT,
+		grand_scheme TEXT,
+		grand_action TEXT,
+		grand_source_organization TEXT,
+		grand_source_repository TEXT,
+		grand_umbrella_organization TEXT,
+		grand_umbrella_repository TEXT,
+		grand_container_name TEXT,
+		grand_target TEXT,
+		not_before INTEGER,
+		expires_at INTEGER,
+		valid_from INTEGER,
+		valid_to INTEGER
+	);
+	CREATE INDEX IF NOT EXISTS granted_history_locator ON granted_history (locator, valid_to);`
+
+	_, err = dm.db.Exec(query)
+	if err != nil {
+		return fmt.Errorf("failed to create granted_history table: %w", err)
+	}
+
+	// Records stored before the history existed are valid from now on
+	now := dm.now().UnixNano()
+	for _, table := range []struct{ name, columns string }{
+		{"requested", requestedColumns},
+		{"granted", grantedColumns},
+	} {
+		_, err := dm.db.Exec(fmt.Sprintf(`INSERT INTO %[1]s_history (locator, %[2]s, valid_from)
+			SELECT locator, %[2]s, ? FROM %[1]s
+			WHERE locator NOT IN (SELECT locator FROM %[1]s_history WHERE valid_to IS NULL)`, table.name, table.columns), now)
+		if err != nil {
+			return fmt.Errorf("failed to fill %s history: %w", table.name, err)
+		}
+	}
+
+	return nil
+}
+
+// initRoles creates the tables of the roles and their assignments. An
+// assignment holds the grants of its role, created marks the grants it saved.
+func (dm *DbManager) initRoles() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS roles (
+		name TEXT PRIMARY KEY,
+		grants TEXT
+	);
+	CREATE TABLE IF NOT EXISTS role_assignments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		role TEXT,
+		subject TEXT,
+		UNIQUE (role, subject)
+	);
+	CREATE TABLE IF NOT EXISTS role_grants (
+		assignment_id INTEGER,
+		locator TEXT,
+		created INTEGER
+	);
+	CREATE INDEX IF NOT EXISTS role_grants_locator ON role_grants (locator);`
+
+	_, err := dm.db.Exec(query)
+	if err != nil {
+		return fmt.Errorf("failed to create role tables: %w", err)
+	}
+
+	return nil
+}
+
+// initOwners creates the registry of the parents of source and umbrella
+// owners, with its versions like the ones of initHistory
+func (dm *DbManager) initOwners() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS owner_parents (
+		organization TEXT,
+		repository TEXT,
+		parent_organization TEXT,
+		parent_repository TEXT,
+		PRIMARY KEY (organization, repository)
+	);
+	CREATE TABLE IF NOT EXISTS owner_parents_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		organization TEXT,
+		repository TEXT,
+		parent_organization TEXT,
+		parent_repository TEXT,
+		valid_from INTEGER,
+		valid_to INTEGER
+	);
+	CREATE INDEX IF NOT EXISTS owner_parents_history_owner ON owner_parents_history (organization, repository, valid_to);`
+
+	_, err := dm.db.Exec(query)
+	if err != nil {
+		return fmt.Errorf("failed to create owner_parents table: %w", err)
+	}
+
+	// Parents registered before the history existed are valid from now on
+	_, err = dm.db.Exec(`INSERT INTO owner_parents_history (`+ownerParentColumns+`, valid_from)
+		SELECT `+ownerParentColumns+`, ? FROM owner_parents p
+		WHERE NOT EXISTS (SELECT 1 FROM owner_parents_history h
+			WHERE h.organization = p.organization AND h.repository = p.repository AND h.valid_to IS NULL)`, dm.now().UnixNano())
+	if err != nil {
+		return fmt.Errorf("failed to fill owner_parents history: %w", err)
+	}
+
+	return nil
+}
+
+// initActions creates the table of the actions that imply other actions
+// within a scheme, with its versions like the ones of initHistory
+func (dm *DbManager) initActions() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS action_implications (
+		scheme TEXT,
+		action TEXT,
+		implied TEXT,
+		PRIMARY KEY (scheme, action, implied)
+	);
+	CREATE TABLE IF NOT EXISTS action_implications_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		scheme TEXT,
+		action TEXT,
+		implied TEXT,
+		valid_from INTEGER,
+		valid_to INTEGER
+	);
+	CREATE INDEX IF NOT EXISTS action_implications_history_key ON action_implications_history (scheme, action, implied, valid_to);`
+
+	_, err := dm.db.Exec(query)
+	if err != nil {
+		return fmt.Errorf("failed to create action_implications table: %w", err)
+	}
+
+	// Implications added before the history existed are valid from now on
+	_, err = dm.db.Exec(`INSERT INTO action_implications_history (`+actionImplicationColumns+`, valid_from)
+		SELECT `+actionImplicationColumns+`, ? FROM action_implications a
+		WHERE NOT EXISTS (SELECT 1 FROM action_implications_history h
+			WHERE h.scheme = a.scheme AND h.action = a.action AND h.implied = a.implied AND h.valid_to IS NULL)`, dm.now().UnixNano())
+	if err != nil {
+		return fmt.Errorf("failed to fill action_implications history: %w", err)
+	}
+
+	return nil
+}
+
+// addColumnIfMissing adds a column to a table of a database created by an older version
+func (dm *DbManager) addColumnIfMissing(table, column, definition string) error {
+	rows, err := dm.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid          int
+			name         string
+			columnType   string
+			notNull      int
+			defaultValue sql.NullString
+			primaryKey   int
+		)
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &primaryKey); err != nil {
+			return fmt.Errorf("failed to read columns of %s: %w", table, err)
+		}
+		if name == column {
+			return rows.Close()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	rows.Close()
+
+	if _, err := dm.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add column %s to %s: %w", column, table, err)
+	}
 
-	return err
+	return nil
 }
 
 func (dm *DbManager) Close() error {
+	dm.changes.close()
 	return dm.db.Close()
 }
@@ -1,6 +1,13 @@
 package syncer
 
-import "testing"
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
 
 func TestSaveRequested_TransactionBeginFailure(t *testing.T) {
 	// This test is hard to trigger with the current setup since we use in-memory SQLite
@@ -72,3 +79,67 @@ func TestDatabaseConnectionError(t *testing.T) {
 		}
 	}
 }
+
+func TestOpenDbManager_file_persists_between_managers(t *testing.T) {
+	// Given
+	is := is.New(t)
+	path := filepath.Join(t.TempDir(), "syncer.db")
+
+	dbManager, err := OpenDbManager(path)
+	is.NoErr(err)
+	is.NoErr(dbManager.SaveGranted(Granted{ContainerName: "image/container", GrandScheme: "image"}))
+	is.NoErr(dbManager.Close())
+
+	// When
+	dbManager, err = OpenDbManager(path)
+	is.NoErr(err)
+	defer dbManager.Close()
+	result, err := dbManager.ListGranted()
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(result), 1)
+	is.Equal(result[0].ContainerName, "image/container")
+}
+
+func TestOpenDbManager_invalid_path(t *testing.T) {
+	is := is.New(t)
+
+	_, err := OpenDbManager(filepath.Join(t.TempDir(), "missing", "syncer.db"))
+
+	is.True(err != nil)
+}
+
+func TestOpenDbManager_migrates_older_database(t *testing.T) {
+	// Given a granted table without the time window columns
+	is := is.New(t)
+	path := filepath.Join(t.TempDir(), "syncer.db")
+	db, err := sql.Open("sqlite3", path)
+	is.NoErr(err)
+	_, err = db.Exec(`CREATE TABLE granted (
+		locator TEXT PRIMARY KEY, description TEXT, host TEXT, expose_path TEXT, scheme TEXT, action TEXT,
+		source_organization TEXT, source_repository TEXT, umbrella_organization TEXT, umbrella_repository TEXT,
+		container_name TEXT, target TEXT, grand_scheme TEXT, grand_action TEXT, grand_source_organization TEXT,
+		grand_source_repository TEXT, grand_umbrella_organization TEXT, grand_umbrella_repository TEXT,
+		grand_container_name TEXT, grand_target TEXT)`)
+	is.NoErr(err)
+	_, err = db.Exec(`INSERT INTO granted VALUES ('old', '', '', '', '', '', '', '', '', '', '', '', 'image', '', '', '', '', '', '', '')`)
+	is.NoErr(err)
+	is.NoErr(db.Close())
+
+	// When
+	dbManager, err := OpenDbManager(path)
+	is.NoErr(err)
+	defer dbManager.Close()
+
+	// Then
+	result, err := dbManager.FindGranted([]Requested{{RequestScheme: "image"}})
+	is.NoErr(err)
+	is.Equal(len(result), 1)
+	is.True(result[0].ExpiresAt.IsZero())
+
+	// The stored records are part of the history from now on
+	result, err = dbManager.FindGranted([]Requested{{RequestScheme: "image"}}, AsOf(time.Now()))
+	is.NoErr(err)
+	is.Equal(len(result), 1)
+}
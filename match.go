@@ -0,0 +1,226 @@
+package syncer
+
+// Dimension is one of the fields that are compared when a requested permission
+// is matched against a granted permission
+type Dimension string
+
+const (
+	DimensionScheme               Dimension = "scheme"
+	DimensionAction               Dimension = "action"
+	DimensionSourceOrganization   Dimension = "source_organization"
+	DimensionSourceRepository     Dimension = "source_repository"
+	DimensionUmbrellaOrganization Dimension = "umbrella_organization"
+	DimensionUmbrellaRepository   Dimension = "umbrella_repository"
+	DimensionContainerName        Dimension = "container_name"
+	DimensionTarget               Dimension = "target"
+	DimensionPath                 Dimension = "path"
+)
+
+// Dimensions lists all matching dimensions in the order they are compared
+var Dimensions = []Dimension{
+	DimensionScheme,
+	DimensionAction,
+	DimensionSourceOrganization,
+	DimensionSourceRepository,
+	DimensionUmbrellaOrganization,
+	DimensionUmbrellaRepository,
+	DimensionContainerName,
+	DimensionTarget,
+	DimensionPath,
+}
+
+// Reasons used in DimensionMatch
+const (
+	ReasonExact             = "exact match"
+	ReasonRequestedWildcard = "requested wildcard"
+	ReasonGrantedWildcard   = "granted wildcard"
+	ReasonResourceMismatch  = "resource mismatch"
+	ReasonValueMismatch     = "value mismatch"
+	ReasonImpliedAction     = "implied action"
+	ReasonPathCovered       = "path covered"
+)
+
+// DimensionMatch describes how a single dimension of a requested permission
+// compares to the same dimension of a granted permission. Scheme, action and
+// path have no resource, all other dimensions need an equal resource
+// (e.g. SourceOrganization) before the values (e.g. RequestSourceOrganization
+// and GrandSourceOrganization) are compared. The path compares the requested
+// destination path with the granted expose path, see PathCovers.
+type DimensionMatch struct {
+	Dimension         Dimension `json:"dimension"`
+	RequestedResource string    `json:"requested_resource,omitempty"`
+	GrantedResource   string    `json:"granted_resource,omitempty"`
+	Requested         string    `json:"requested"`
+	Granted           string    `json:"granted"`
+	Matched           bool      `json:"matched"`
+	Reason            string    `json:"reason"`
+}
+
+// dimensionValues holds the values of one dimension of a requested and a granted permission
+type dimensionValues struct {
+	dimension         Dimension
+	hasResource       bool
+	requestedResource string
+	grantedResource   string
+	requested         string
+	granted           string
+}
+
+// pairValues returns the values of all dimensions, in the order of Dimensions
+func pairValues(r Requested, g Granted) []dimensionValues {
+	return []dimensionValues{
+		{dimension: DimensionScheme, requested: r.RequestScheme, granted: g.GrandScheme},
+		{dimension: DimensionAction, requested: r.RequestAction, granted: g.GrandAction},
+		{DimensionSourceOrganization, true, r.SourceOrganization, g.SourceOrganization, r.RequestSourceOrganization, g.GrandSourceOrganization},
+		{DimensionSourceRepository, true, r.SourceRepository, g.SourceRepository, r.RequestSourceRepository, g.GrandSourceRepository},
+		{DimensionUmbrellaOrganization, true, r.UmbrellaOrganization, g.UmbrellaOrganization, r.RequestUmbrellaOrganization, g.GrandUmbrellaOrganization},
+		{DimensionUmbrellaRepository, true, r.UmbrellaRepository, g.UmbrellaRepository, r.RequestUmbrellaRepository, g.GrandUmbrellaRepository},
+		{DimensionContainerName, true, r.ContainerName, g.ContainerName, r.RequestContainerName, g.GrandContainerName},
+		{DimensionTarget, true, r.Target, g.Target, r.RequestTarget, g.GrandTarget},
+		{dimension: DimensionPath, requested: r.DestinationPath, granted: g.ExposePath},
+	}
+}
+
+// compare applies the same rules as FindGranted and FindRequested
+func (v dimensionValues) compare() DimensionMatch {
+	match := DimensionMatch{
+		Dimension:         v.dimension,
+		RequestedResource: v.requestedResource,
+		GrantedResource:   v.grantedResource,
+		Requested:         v.requested,
+		Granted:           v.granted,
+	}
+
+	if v.dimension == DimensionPath {
+		return v.comparePath(match)
+	}
+
+	switch {
+	case v.hasResource && v.requestedResource != v.grantedResource:
+		match.Reason = ReasonResourceMismatch
+	case v.requested == "*":
+		match.Matched, match.Reason = true, ReasonRequestedWildcard
+	case v.granted == "*":
+		match.Matched, match.Reason = true, ReasonGrantedWildcard
+	case v.requested == v.granted:
+		match.Matched, match.Reason = true, ReasonExact
+	default:
+		match.Reason = ReasonValueMismatch
+	}
+
+	return match
+}
+
+// comparePath compares the requested destination path with the granted
+// expose path, an empty path is as unrestricted as the wildcard
+func (v dimensionValues) comparePath(match DimensionMatch) DimensionMatch {
+	switch {
+	case v.requested == "" || v.requested == "*":
+		match.Matched, match.Reason = true, ReasonRequestedWildcard
+	case v.granted == "" || v.granted == "*":
+		match.Matched, match.Reason = true, ReasonGrantedWildcard
+	case NormalizePath(v.requested) == NormalizePath(v.granted):
+		match.Matched, match.Reason = true, ReasonExact
+	case PathCovers(v.granted, v.requested):
+		match.Matched, match.Reason = true, ReasonPathCovered
+	default:
+		match.Reason = ReasonValueMismatch
+	}
+
+	return match
+}
+
+// Explain compares a requested permission with a granted permission dimension
+// by dimension, using the same rules as FindGranted and FindRequested apart
+// from the rules stored in a DbManager, see DbManager.Explain
+func Explain(requested Requested, granted Granted) []DimensionMatch {
+	return explain(requested, granted, nil)
+}
+
+// Matches reports whether the granted permission satisfies the requested permission
+func Matches(requested Requested, granted Granted) bool {
+	return matches(requested, granted, nil)
+}
+
+// Explain is like the package-level Explain, but also matches actions that
+// are implied by the granted action within the scheme
+func (dm *DbManager) Explain(requested Requested, granted Granted) ([]DimensionMatch, error) {
+	actions, err := loadActionLattice(dm.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return explain(requested, granted, actions), nil
+}
+
+func explain(requested Requested, granted Granted, actions actionLattice) []DimensionMatch {
+	values := pairValues(requested, granted)
+	matches := make([]DimensionMatch, 0, len(values))
+	for _, v := range values {
+		match := v.compare()
+		if v.dimension == DimensionAction && match.Reason == ReasonValueMismatch &&
+			actions.implies(actionScheme(requested, granted), v.granted, v.requested) {
+			match.Matched, match.Reason = true, ReasonImpliedAction
+		}
+		matches = append(matches, match)
+	}
+
+	return matches
+}
+
+func matches(requested Requested, granted Granted, actions actionLattice) bool {
+	for _, match := range explain(requested, granted, actions) {
+		if !match.Matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// actionScheme is the scheme whose action hierarchy applies to a pair, the
+// requested scheme or the granted scheme when the request has a wildcard
+func actionScheme(requested Requested, granted Granted) string {
+	if requested.RequestScheme != "*" {
+		return requested.RequestScheme
+	}
+
+	return granted.GrandScheme
+}
+
+// matchRules are the rules stored in a DbManager that extend Matches: the
+// owner parents and the implied actions
+type matchRules struct {
+	owners  ownerTree
+	actions actionLattice
+}
+
+func loadMatchRules(q querier) (matchRules, error) {
+	return loadMatchRulesIn(q, snapshot{})
+}
+
+// loadMatchRulesIn loads the rules at the point in time of a snapshot
+func loadMatchRulesIn(q querier, at snapshot) (matchRules, error) {
+	owners, err := loadOwnerTreeIn(q, at.of("owner_parents"))
+	if err != nil {
+		return matchRules{}, err
+	}
+	actions, err := loadActionLatticeIn(q, at.of("action_implications"))
+	if err != nil {
+		return matchRules{}, err
+	}
+
+	return matchRules{owners: owners, actions: actions}, nil
+}
+
+// matches reports whether the grant satisfies the request or one of its
+// variants lifted to the ancestors of its owners
+func (m matchRules) matches(r Requested, g Granted) bool {
+	for _, v := range m.owners.liftRequested(r) {
+		if matches(v.Requested, g, m.actions) {
+			return true
+		}
+	}
+
+	return false
+}
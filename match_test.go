@@ -0,0 +1,114 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestMatch_agrees_with_FindGranted(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested Requested
+		granted   Granted
+		expected  bool
+	}{
+		{
+			name:      "exact scheme match",
+			requested: Requested{RequestScheme: "image"},
+			granted:   Granted{GrandScheme: "image"},
+			expected:  true,
+		},
+		{
+			name:      "scheme mismatch",
+			requested: Requested{RequestScheme: "image"},
+			granted:   Granted{GrandScheme: "json"},
+			expected:  false,
+		},
+		{
+			name:      "wildcard in request action",
+			requested: Requested{RequestAction: "*"},
+			granted:   Granted{GrandAction: "push"},
+			expected:  true,
+		},
+		{
+			name:      "wildcard in grand container name",
+			requested: Requested{ContainerName: "image/container", RequestContainerName: "image/container"},
+			granted:   Granted{ContainerName: "image/container", GrandContainerName: "*"},
+			expected:  true,
+		},
+		{
+			name:      "wildcard does not cross resources",
+			requested: Requested{ContainerName: "image/container", RequestContainerName: "*"},
+			granted:   Granted{ContainerName: "image/other", GrandContainerName: "*"},
+			expected:  false,
+		},
+		{
+			name:      "target mismatch",
+			requested: Requested{Target: "cmd", RequestTarget: "cmd"},
+			granted:   Granted{Target: "cmd", GrandTarget: "web"},
+			expected:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			is, db := setupTestDB(t)
+			mockGranted(db, tt.granted)
+
+			// When
+			result, err := db.FindGranted([]Requested{tt.requested})
+
+			// Then
+			is.NoErr(err)
+			is.Equal(len(result) == 1, tt.expected)
+			is.Equal(Matches(tt.requested, tt.granted), tt.expected)
+		})
+	}
+}
+
+func TestMatch_explain(t *testing.T) {
+	// Given
+	requested := Requested{
+		RequestScheme:             "image",
+		RequestAction:             "*",
+		SourceOrganization:        "confetti-sites",
+		RequestSourceOrganization: "confetti-sites",
+		ContainerName:             "image/container",
+		RequestContainerName:      "image/container",
+		Target:                    "cmd",
+		RequestTarget:             "cmd",
+	}
+	granted := Granted{
+		GrandScheme:             "*",
+		GrandAction:             "pull",
+		SourceOrganization:      "confetti-sites",
+		GrandSourceOrganization: "confetti-sites",
+		ContainerName:           "image/other",
+		GrandContainerName:      "image/other",
+		Target:                  "cmd",
+		GrandTarget:             "web",
+	}
+
+	// When
+	result := Explain(requested, granted)
+
+	// Then
+	is := is.New(t)
+	is.Equal(len(result), len(Dimensions))
+	reasons := map[Dimension]string{}
+	for i, match := range result {
+		is.Equal(match.Dimension, Dimensions[i])
+		reasons[match.Dimension] = match.Reason
+	}
+	is.Equal(reasons[DimensionScheme], ReasonGrantedWildcard)
+	is.Equal(reasons[DimensionAction], ReasonRequestedWildcard)
+	is.Equal(reasons[DimensionSourceOrganization], ReasonExact)
+	is.Equal(reasons[DimensionSourceRepository], ReasonExact)
+	is.Equal(reasons[DimensionContainerName], ReasonResourceMismatch)
+	is.Equal(reasons[DimensionTarget], ReasonValueMismatch)
+	is.Equal(result[6].RequestedResource, "image/container")
+	is.Equal(result[6].GrantedResource, "image/other")
+	is.True(!Matches(requested, granted))
+}
@@ -0,0 +1,204 @@
+package syncer
+
+import (
+	"sort"
+	"strings"
+)
+
+// Mount is an instruction to copy the data at SourcePath on SourceHost to
+// DestinationPath, for a requested permission that Granted satisfies. A
+// destination path lies within the expose path of the grant, so a request
+// with a destination path receives that part of the exposed data and a
+// request without one receives everything the grant exposes.
+type Mount struct {
+	SourceHost string `json:"source_host"`
+	// ExposePath is the normalized expose path of the grant, it may be a pattern
+	ExposePath string `json:"expose_path"`
+	// SourcePath is the part of ExposePath that is copied: the expose path
+	// itself, or the path of the requested sub-path of it
+	SourcePath      string  `json:"source_path"`
+	DestinationPath string  `json:"destination_path"`
+	Granted         Granted `json:"granted"`
+}
+
+// RequestMounts are the mounts of a satisfied requested permission
+type RequestMounts struct {
+	Requested Requested `json:"requested"`
+	Mounts    []Mount   `json:"mounts"`
+}
+
+// MountConflict is a destination of a container name and target that
+// mounts with different sources would write to
+type MountConflict struct {
+	ContainerName   string      `json:"container_name"`
+	Target          string      `json:"target"`
+	DestinationPath string      `json:"destination_path"`
+	Requested       []Requested `json:"requested"`
+	Mounts          []Mount     `json:"mounts"`
+}
+
+// SkippedMount is a grant that satisfies a requested permission without a
+// concrete path to copy
+type SkippedMount struct {
+	Requested Requested `json:"requested"`
+	Granted   Granted   `json:"granted"`
+	Reason    string    `json:"reason"`
+}
+
+// Reasons used in SkippedMount
+const (
+	SkipNoPath      = "neither the grant nor the request has a path"
+	SkipPathPattern = "the expose path is a pattern and the request has no destination path"
+)
+
+// MountResolution is the result of ResolveMounts
+type MountResolution struct {
+	Resolved  []RequestMounts `json:"resolved"`
+	Conflicts []MountConflict `json:"conflicts"`
+	Skipped   []SkippedMount  `json:"skipped"`
+}
+
+// ResolveMounts finds the grants of every requested permission with the
+// same rules as FindGranted and turns them into mounts. Requests that no
+// grant satisfies are left out. Grants with the same source host and
+// source path result in one mount; destinations of a container name and
+// target that different sources would write to are reported as conflicts.
+func (dm *DbManager) ResolveMounts(requested []Requested, opts ...QueryOption) (MountResolution, error) {
+	now, from, err := dm.resolveQuery("granted", opts)
+	if err != nil {
+		return MountResolution{}, err
+	}
+
+	resolution := MountResolution{Resolved: []RequestMounts{}, Conflicts: []MountConflict{}, Skipped: []SkippedMount{}}
+	for _, r := range requested {
+		granted, err := findGrantedIn(dm.db, from, now, []Requested{r})
+		if err != nil {
+			return MountResolution{}, err
+		}
+		if len(granted) == 0 {
+			continue
+		}
+
+		mounts := []Mount{}
+		seen := map[[2]string]bool{}
+		for _, g := range granted {
+			mount, reason := resolveMount(r, g)
+			if reason != "" {
+				resolution.Skipped = append(resolution.Skipped, SkippedMount{Requested: r, Granted: g, Reason: reason})
+				continue
+			}
+			source := [2]string{mount.SourceHost, mount.SourcePath}
+			if seen[source] {
+				continue
+			}
+			seen[source] = true
+			mounts = append(mounts, mount)
+		}
+		sortMounts(mounts)
+		resolution.Resolved = append(resolution.Resolved, RequestMounts{Requested: r, Mounts: mounts})
+	}
+	resolution.Conflicts = mountConflicts(resolution.Resolved)
+
+	return resolution, nil
+}
+
+// resolveMount returns the mount of a grant for a request, or the reason there is none
+func resolveMount(r Requested, g Granted) (Mount, string) {
+	expose, destination := NormalizePath(g.ExposePath), NormalizePath(r.DestinationPath)
+	if expose == "*" {
+		expose = ""
+	}
+	if destination == "*" {
+		destination = ""
+	}
+
+	// The grant covers the destination path, so the requested part of the
+	// exposed data has the same path in the source container
+	source := destination
+	switch {
+	case expose == "" && destination == "":
+		return Mount{}, SkipNoPath
+	case destination == "" && isPathPattern(expose):
+		return Mount{}, SkipPathPattern
+	case destination == "":
+		source, destination = expose, expose
+	}
+
+	return Mount{SourceHost: g.Host, ExposePath: expose, SourcePath: source, DestinationPath: destination, Granted: g}, ""
+}
+
+// isPathPattern reports whether a path has a * or ** segment
+func isPathPattern(p string) bool {
+	for _, segment := range pathSegments(p) {
+		if segment == "*" || segment == "**" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mountConflicts groups the mounts by container name, target and destination
+// and reports the destinations with more than one source
+func mountConflicts(resolved []RequestMounts) []MountConflict {
+	index := map[[3]string]int{}
+	var groups []MountConflict
+	for _, rm := range resolved {
+		for _, m := range rm.Mounts {
+			key := [3]string{rm.Requested.ContainerName, rm.Requested.Target, m.DestinationPath}
+			i, ok := index[key]
+			if !ok {
+				i = len(groups)
+				index[key] = i
+				groups = append(groups, MountConflict{ContainerName: key[0], Target: key[1], DestinationPath: key[2]})
+			}
+			groups[i].Requested = appendRequested(groups[i].Requested, rm.Requested)
+			groups[i].Mounts = append(groups[i].Mounts, m)
+		}
+	}
+
+	conflicts := []MountConflict{}
+	for _, group := range groups {
+		sources := map[[2]string]bool{}
+		for _, m := range group.Mounts {
+			sources[[2]string{m.SourceHost, m.SourcePath}] = true
+		}
+		if len(sources) > 1 {
+			sortMounts(group.Mounts)
+			conflicts = append(conflicts, group)
+		}
+	}
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		a, b := conflicts[i], conflicts[j]
+		return strings.Join([]string{a.ContainerName, a.Target, a.DestinationPath}, "\x00") <
+			strings.Join([]string{b.ContainerName, b.Target, b.DestinationPath}, "\x00")
+	})
+
+	return conflicts
+}
+
+// appendRequested appends a requested permission that is not in the list yet
+func appendRequested(list []Requested, r Requested) []Requested {
+	locator := requestedLocator(r)
+	for _, other := range list {
+		if requestedLocator(other) == locator {
+			return list
+		}
+	}
+
+	return append(list, r)
+}
+
+// sortMounts orders mounts by destination path, source host and source path
+func sortMounts(mounts []Mount) {
+	sort.SliceStable(mounts, func(i, j int) bool {
+		a, b := mounts[i], mounts[j]
+		if a.DestinationPath != b.DestinationPath {
+			return a.DestinationPath < b.DestinationPath
+		}
+		if a.SourceHost != b.SourceHost {
+			return a.SourceHost < b.SourceHost
+		}
+		return a.SourcePath < b.SourcePath
+	})
+}
@@ -0,0 +1,79 @@
+package syncer
+
+import (
+	"testing"
+)
+
+func TestResolveMounts(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	public := Granted{Host: "assets", ContainerName: "cms/uploads", GrandScheme: "hive", GrandAction: "sync", ExposePath: "/public"}
+	images := Granted{Host: "assets", ContainerName: "cms/uploads", GrandScheme: "hive", GrandAction: "*", ExposePath: "/public/images"}
+	sites := Granted{Host: "sites", ContainerName: "cms/uploads", GrandScheme: "hive", GrandAction: "sync", ExposePath: "/sites/**/public"}
+	for _, g := range []Granted{public, images, sites} {
+		mockGranted(dbManager, g)
+	}
+	logo := Requested{ContainerName: "cms/uploads", RequestScheme: "hive", RequestAction: "sync", DestinationPath: "/public/images//logo.png"}
+	everything := Requested{ContainerName: "cms/uploads", RequestScheme: "hive", RequestAction: "sync"}
+	unsatisfied := Requested{ContainerName: "cms/other", RequestScheme: "hive", RequestAction: "sync"}
+
+	// When
+	resolution, err := dbManager.ResolveMounts([]Requested{logo, everything, unsatisfied})
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(resolution.Resolved), 2) // The unsatisfied request has no mounts
+	is.Equal(resolution.Resolved[0].Requested, logo)
+	is.Equal(resolution.Resolved[0].Mounts, []Mount{
+		{SourceHost: "assets", ExposePath: "/public", SourcePath: "/public/images/logo.png", DestinationPath: "/public/images/logo.png", Granted: public},
+	})
+	is.Equal(resolution.Resolved[1].Mounts, []Mount{
+		{SourceHost: "assets", ExposePath: "/public", SourcePath: "/public", DestinationPath: "/public", Granted: public},
+		{SourceHost: "assets", ExposePath: "/public/images", SourcePath: "/public/images", DestinationPath: "/public/images", Granted: images},
+	})
+	is.Equal(resolution.Skipped, []SkippedMount{{Requested: everything, Granted: sites, Reason: SkipPathPattern}})
+	is.Equal(len(resolution.Conflicts), 0)
+}
+
+func TestResolveMounts_keeps_expose_path_pattern(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	sites := Granted{Host: "sites", ContainerName: "cms/uploads", GrandScheme: "hive", GrandAction: "sync", ExposePath: "/sites/**/public/"}
+	mockGranted(dbManager, sites)
+	index := Requested{ContainerName: "cms/uploads", RequestScheme: "hive", RequestAction: "sync", DestinationPath: "/sites/a/b/public/index.html"}
+
+	// When
+	resolution, err := dbManager.ResolveMounts([]Requested{index})
+
+	// Then
+	is.NoErr(err)
+	is.Equal(resolution.Resolved[0].Mounts, []Mount{
+		{SourceHost: "sites", ExposePath: "/sites/**/public", SourcePath: "/sites/a/b/public/index.html", DestinationPath: "/sites/a/b/public/index.html", Granted: sites},
+	})
+}
+
+func TestResolveMounts_conflicts(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	assets := Granted{Host: "assets", ContainerName: "cms/uploads", Target: "web", GrandScheme: "hive", GrandAction: "sync", ExposePath: "/public"}
+	mirror := Granted{Host: "mirror", ContainerName: "cms/uploads", Target: "web", GrandScheme: "hive", GrandAction: "*", ExposePath: "/public/images"}
+	mockGranted(dbManager, assets)
+	mockGranted(dbManager, mirror)
+	images := Requested{ContainerName: "cms/uploads", Target: "web", RequestScheme: "hive", RequestAction: "sync", DestinationPath: "/public/images"}
+	pull := Requested{ContainerName: "cms/uploads", Target: "web", RequestScheme: "hive", RequestAction: "pull", DestinationPath: "/public/images/"}
+
+	// When
+	resolution, err := dbManager.ResolveMounts([]Requested{images, pull})
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(resolution.Conflicts), 1)
+	conflict := resolution.Conflicts[0]
+	is.Equal(conflict.ContainerName, "cms/uploads")
+	is.Equal(conflict.Target, "web")
+	is.Equal(conflict.DestinationPath, "/public/images")
+	is.Equal(conflict.Requested, []Requested{images, pull})
+	is.Equal(len(conflict.Mounts), 3) // Two sources for images, one for pull
+	is.Equal(conflict.Mounts[0].SourceHost, "assets")
+	is.Equal(conflict.Mounts[2].SourceHost, "mirror")
+}
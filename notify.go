@@ -0,0 +1,274 @@
+package syncer
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// ChangeOperation is the kind of mutation that caused a ChangeEvent
+type ChangeOperation string
+
+const (
+	ChangeSaved   ChangeOperation = "saved"
+	ChangeDeleted ChangeOperation = "deleted"
+)
+
+// ChangeEvent describes a committed change of one granted or requested record.
+// When a granted record changes, Requested holds the stored requested records
+// it matches. When a requested record changes, Granted holds the stored
+// granted records that match it.
+type ChangeEvent struct {
+	Operation        ChangeOperation `json:"operation"`
+	ChangedGranted   *Granted        `json:"changed_granted,omitempty"`
+	ChangedRequested *Requested      `json:"changed_requested,omitempty"`
+	Requested        []Requested     `json:"requested,omitempty"`
+	Granted          []Granted       `json:"granted,omitempty"`
+}
+
+// ChangeFilter selects the events a subscriber receives. Empty fields match
+// everything, so the zero value receives all events. An event passes when the
+// changed record or one of the affected records has the given container name
+// and target.
+type ChangeFilter struct {
+	ContainerName string
+	Target        string
+}
+
+func (f ChangeFilter) matches(event ChangeEvent) bool {
+	if f.ContainerName == "" && f.Target == "" {
+		return true
+	}
+	if event.ChangedGranted != nil && f.matchesResource(event.ChangedGranted.ContainerName, event.ChangedGranted.Target) {
+		return true
+	}
+	if event.ChangedRequested != nil && f.matchesResource(event.ChangedRequested.ContainerName, event.ChangedRequested.Target) {
+		return true
+	}
+	for _, r := range event.Requested {
+		if f.matchesResource(r.ContainerName, r.Target) {
+			return true
+		}
+	}
+	for _, g := range event.Granted {
+		if f.matchesResource(g.ContainerName, g.Target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f ChangeFilter) matchesResource(containerName, target string) bool {
+	return (f.ContainerName == "" || f.ContainerName == containerName) &&
+		(f.Target == "" || f.Target == target)
+}
+
+// maxQueuedChanges is the number of events a subscriber can fall behind
+// before it is dropped
+const maxQueuedChanges = 1024
+
+// Subscribe returns a channel that receives an event for every committed
+// change that passes the filter. Events are delivered in commit order and a
+// slow subscriber never blocks a save or delete. A subscriber that falls more
+// than 1024 events behind is dropped: its channel is closed without the
+// pending events, subscribe again and reload the state to recover. Call the
+// returned function to unsubscribe, this closes the channel. Closing the
+// DbManager closes all channels.
+func (dm *DbManager) Subscribe(filter ChangeFilter) (<-chan ChangeEvent, func()) {
+	return dm.changes.subscribe(filter)
+}
+
+// commit commits the transaction of a change and publishes its events. Both
+// happen under one lock, so the events of concurrent changes are published in
+// the order of their commits.
+func (dm *DbManager) commit(tx *sql.Tx, events []ChangeEvent) error {
+	dm.changes.commitMu.Lock()
+	defer dm.changes.commitMu.Unlock()
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	dm.changes.publish(events)
+
+	return nil
+}
+
+// grantedChanges builds the events for changed granted records, it runs inside
+// the transaction of the change so the affected records are consistent with it
+func (dm *DbManager) grantedChanges(q querier, operation ChangeOperation, granted []Granted) ([]ChangeEvent, error) {
+	if !dm.changes.active() {
+		return nil, nil
+	}
+
+	events := make([]ChangeEvent, 0, len(granted))
+	for _, g := range granted {
+		affected, err := findRequested(q, dm.now(), []Granted{g})
+		if err != nil {
+			return nil, err
+		}
+		changed := g
+		events = append(events, ChangeEvent{Operation: operation, ChangedGranted: &changed, Requested: affected})
+	}
+
+	return events, nil
+}
+
+// requestedChanges builds the events for changed requested records, it runs
+// inside the transaction of the change so the affected records are consistent with it
+func (dm *DbManager) requestedChanges(q querier, operation ChangeOperation, requested []Requested) ([]ChangeEvent, error) {
+	if !dm.changes.active() {
+		return nil, nil
+	}
+
+	events := make([]ChangeEvent, 0, len(requested))
+	for _, r := range requested {
+		affected, err := findGranted(q, dm.now(), []Requested{r})
+		if err != nil {
+			return nil, err
+		}
+		changed := r
+		events = append(events, ChangeEvent{Operation: operation, ChangedRequested: &changed, Granted: affected})
+	}
+
+	return events, nil
+}
+
+// changeHub fans committed events out to the subscribers
+type changeHub struct {
+	// commitMu orders the commits and publishes of changes
+	commitMu sync.Mutex
+
+	mu          sync.Mutex
+	subscribers map[*subscription]struct{}
+	closed      bool
+}
+
+func newChangeHub() *changeHub {
+	return &changeHub{subscribers: map[*subscription]struct{}{}}
+}
+
+func (h *changeHub) active() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.subscribers) > 0
+}
+
+func (h *changeHub) subscribe(filter ChangeFilter) (<-chan ChangeEvent, func()) {
+	s := &subscription{
+		filter: filter,
+		out:    make(chan ChangeEvent),
+		ready:  make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		close(s.out)
+		return s.out, func() {}
+	}
+	h.subscribers[s] = struct{}{}
+	h.mu.Unlock()
+
+	go s.deliver()
+
+	return s.out, func() { h.unsubscribe(s) }
+}
+
+func (h *changeHub) unsubscribe(s *subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscribers[s]; ok {
+		delete(h.subscribers, s)
+		close(s.done)
+	}
+}
+
+func (h *changeHub) publish(events []ChangeEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for s := range h.subscribers {
+		for _, event := range events {
+			if s.filter.matches(event) && !s.enqueue(event) {
+				delete(h.subscribers, s)
+				close(s.done)
+				break
+			}
+		}
+	}
+}
+
+func (h *changeHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for s := range h.subscribers {
+		delete(h.subscribers, s)
+		close(s.done)
+	}
+}
+
+// subscription buffers the events of one subscriber, so publishing never
+// waits for the receiver. The buffer holds at most maxQueuedChanges events.
+type subscription struct {
+	filter ChangeFilter
+	out    chan ChangeEvent
+	ready  chan struct{}
+	done   chan struct{}
+
+	mu    sync.Mutex
+	queue []ChangeEvent
+}
+
+// enqueue buffers an event, it returns false when the buffer is full
+func (s *subscription) enqueue(event ChangeEvent) bool {
+	s.mu.Lock()
+	if len(s.queue) >= maxQueuedChanges {
+		s.mu.Unlock()
+		return false
+	}
+	s.queue = append(s.queue, event)
+	s.mu.Unlock()
+
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+
+	return true
+}
+
+func (s *subscription) deliver() {
+	defer close(s.out)
+
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			s.mu.Unlock()
+			select {
+			case <-s.ready:
+				continue
+			case <-s.done:
+				return
+			}
+		}
+		event := s.queue[0]
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+
+		select {
+		case s.out <- event:
+		case <-s.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package syncer
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func receiveEvent(t *testing.T, events <-chan ChangeEvent) ChangeEvent {
+	t.Helper()
+
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("events channel is closed")
+		}
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+	}
+
+	return ChangeEvent{}
+}
+
+func expectNoEvent(t *testing.T, events <-chan ChangeEvent) {
+	t.Helper()
+
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event: %+v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestNotify_save_granted_carries_affected_requested(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	mockRequested(dbManager, Requested{RequestScheme: "image", ContainerName: "image/container"})
+	mockRequested(dbManager, Requested{RequestScheme: "json", ContainerName: "image/container"})
+	events, unsubscribe := dbManager.Subscribe(ChangeFilter{})
+	defer unsubscribe()
+
+	// When
+	err := dbManager.SaveGranted(Granted{GrandScheme: "image", ContainerName: "image/container"})
+
+	// Then
+	is.NoErr(err)
+	event := receiveEvent(t, events)
+	is.Equal(event.Operation, ChangeSaved)
+	is.Equal(event.ChangedGranted.GrandScheme, "image")
+	is.True(event.ChangedRequested == nil)
+	is.Equal(len(event.Requested), 1)
+	is.Equal(event.Requested[0].RequestScheme, "image")
+}
+
+func TestNotify_delete_granted(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	granted := Granted{GrandScheme: "image"}
+	mockGranted(dbManager, granted)
+	mockRequested(dbManager, Requested{RequestScheme: "image"})
+	events, unsubscribe := dbManager.Subscribe(ChangeFilter{})
+	defer unsubscribe()
+
+	// When
+	is.NoErr(dbManager.DeleteGranted(Granted{GrandScheme: "json"})) // Not stored, no event
+	is.NoErr(dbManager.DeleteGranted(granted))
+
+	// Then
+	event := receiveEvent(t, events)
+	is.Equal(event.Operation, ChangeDeleted)
+	is.Equal(*event.ChangedGranted, granted)
+	is.Equal(len(event.Requested), 1)
+	expectNoEvent(t, events)
+}
+
+func TestNotify_save_and_delete_requested_carry_affected_granted(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	mockGranted(dbManager, Granted{GrandScheme: "image"})
+	mockGranted(dbManager, Granted{GrandScheme: "*"})
+	events, unsubscribe := dbManager.Subscribe(ChangeFilter{})
+	defer unsubscribe()
+	requested := []Requested{{RequestScheme: "image"}, {RequestScheme: "json"}}
+
+	// When
+	is.NoErr(dbManager.SaveRequested(requested))
+	is.NoErr(dbManager.DeleteRequested(requested[:1]))
+
+	// Then
+	event := receiveEvent(t, events)
+	is.Equal(event.Operation, ChangeSaved)
+	is.Equal(event.ChangedRequested.RequestScheme, "image")
+	is.Equal(len(event.Granted), 2)
+
+	event = receiveEvent(t, events)
+	is.Equal(event.Operation, ChangeSaved)
+	is.Equal(event.ChangedRequested.RequestScheme, "json")
+	is.Equal(len(event.Granted), 1)
+
+	event = receiveEvent(t, events)
+	is.Equal(event.Operation, ChangeDeleted)
+	is.Equal(event.ChangedRequested.RequestScheme, "image")
+	is.Equal(len(event.Granted), 2)
+}
+
+func TestNotify_filter(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	mockRequested(dbManager, Requested{RequestScheme: "image", ContainerName: "image/container", Target: "cmd"})
+	events, unsubscribe := dbManager.Subscribe(ChangeFilter{ContainerName: "image/container", Target: "cmd"})
+	defer unsubscribe()
+
+	// When
+	is.NoErr(dbManager.SaveGranted(Granted{GrandScheme: "image", ContainerName: "image/other"}))
+	is.NoErr(dbManager.SaveGranted(Granted{GrandScheme: "image", ContainerName: "image/container", Target: "web"}))
+	is.NoErr(dbManager.SaveGranted(Granted{GrandScheme: "image", ContainerName: "image/container", Target: "cmd"}))
+
+	// Then
+	event := receiveEvent(t, events)
+	is.Equal(event.ChangedGranted.Target, "cmd")
+	expectNoEvent(t, events)
+}
+
+func TestNotify_slow_subscriber_does_not_block_and_keeps_order(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	events, unsubscribe := dbManager.Subscribe(ChangeFilter{})
+	defer unsubscribe()
+
+	// When
+	for _, scheme := range []string{"a", "b", "c", "d"} {
+		is.NoErr(dbManager.SaveGranted(Granted{GrandScheme: scheme}))
+	}
+
+	// Then
+	for _, scheme := range []string{"a", "b", "c", "d"} {
+		is.Equal(receiveEvent(t, events).ChangedGranted.GrandScheme, scheme)
+	}
+}
+
+func TestNotify_concurrent_writers_keep_commit_order(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	events, unsubscribe := dbManager.Subscribe(ChangeFilter{})
+	defer unsubscribe()
+
+	// When
+	var wg sync.WaitGroup
+	for writer := 0; writer < 4; writer++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				is.NoErr(dbManager.SaveGranted(Granted{GrandScheme: fmt.Sprintf("%d-%d", writer, i)}))
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Then
+	entries, err := dbManager.AuditLog(AuditFilter{})
+	is.NoErr(err)
+	is.Equal(len(entries), 40)
+	for _, entry := range entries {
+		is.Equal(receiveEvent(t, events).ChangedGranted.GrandScheme, entry.AfterGranted.GrandScheme)
+	}
+}
+
+func TestNotify_subscriber_that_falls_behind_is_dropped(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	events, unsubscribe := dbManager.Subscribe(ChangeFilter{})
+	defer unsubscribe()
+	other, unsubscribeOther := dbManager.Subscribe(ChangeFilter{ContainerName: "other"})
+	defer unsubscribeOther()
+
+	// When
+	overflow := make([]ChangeEvent, maxQueuedChanges+2)
+	for i := range overflow {
+		overflow[i] = ChangeEvent{Operation: ChangeSaved, ChangedGranted: &Granted{}}
+	}
+	dbManager.changes.publish(overflow)
+
+	// Then
+	received := 0
+	for range events {
+		received++
+	}
+	is.True(received <= maxQueuedChanges+1) // The channel is closed without the pending events
+	is.True(dbManager.changes.active())     // Subscribers that keep up stay subscribed
+	is.NoErr(dbManager.SaveGranted(Granted{ContainerName: "other"}))
+	is.Equal(receiveEvent(t, other).ChangedGranted.ContainerName, "other")
+}
+
+func TestNotify_unsubscribe_and_close(t *testing.T) {
+	is := is.New(t)
+	dbManager, err := NewDbManager()
+	is.NoErr(err)
+
+	first, unsubscribe := dbManager.Subscribe(ChangeFilter{})
+	second, _ := dbManager.Subscribe(ChangeFilter{})
+
+	unsubscribe()
+	unsubscribe() // Unsubscribing twice is harmless
+	_, ok := <-first
+	is.True(!ok)
+
+	is.NoErr(dbManager.Close())
+	_, ok = <-second
+	is.True(!ok)
+
+	closed, _ := dbManager.Subscribe(ChangeFilter{})
+	_, ok = <-closed
+	is.True(!ok)
+}
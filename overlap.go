@@ -0,0 +1,153 @@
+package syncer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DestinationOverlap is a pair of requested permissions of one container
+// name and target that sync into the same or nested destination paths, so
+// one can overwrite the data of the other. The destination of First is the
+// shorter one.
+type DestinationOverlap struct {
+	ContainerName string    `json:"container_name"`
+	Target        string    `json:"target"`
+	First         Requested `json:"first"`
+	Second        Requested `json:"second"`
+	// Nested is true when the destination of Second lies within the one of
+	// First and false when both are the same
+	Nested bool `json:"nested"`
+}
+
+// OverlapError is returned by saves of requested permissions that overlap
+// with each other or with stored ones, see WithOverlapCheck
+type OverlapError struct {
+	Overlaps []DestinationOverlap
+}
+
+func (e *OverlapError) Error() string {
+	overlaps := make([]string, 0, len(e.Overlaps))
+	for _, o := range e.Overlaps {
+		overlaps = append(overlaps, fmt.Sprintf("%s and %s in %s target %s",
+			NormalizePath(o.First.DestinationPath), NormalizePath(o.Second.DestinationPath), o.ContainerName, o.Target))
+	}
+
+	return "overlapping destination paths: " + strings.Join(overlaps, "; ")
+}
+
+// WithOverlapCheck makes saves of requested permissions fail with an
+// OverlapError when a saved permission overlaps with another one, saved or
+// stored. Overlaps between stored permissions do not fail a save.
+func WithOverlapCheck() Option {
+	return func(dm *DbManager) {
+		dm.checkOverlaps = true
+	}
+}
+
+// FindDestinationOverlaps reports the requested permissions with the same
+// container name and target whose destination paths are the same or nested,
+// after normalization. Permissions without a destination path or with the
+// wildcard are not compared. Overlaps are ordered by container name, target
+// and the destination paths.
+func FindDestinationOverlaps(requested []Requested) []DestinationOverlap {
+	groups := map[[2]string][]Requested{}
+	for _, r := range requested {
+		if p := NormalizePath(r.DestinationPath); p == "" || p == "*" {
+			continue
+		}
+		key := [2]string{r.ContainerName, r.Target}
+		groups[key] = append(groups[key], r)
+	}
+
+	overlaps := []DestinationOverlap{}
+	for key, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool {
+			a, b := NormalizePath(group[i].DestinationPath), NormalizePath(group[j].DestinationPath)
+			if a != b {
+				return a < b
+			}
+			return requestedLocator(group[i]) < requestedLocator(group[j])
+		})
+		for i := range group {
+			for j := i + 1; j < len(group); j++ {
+				first, second := group[i], group[j]
+				if requestedLocator(first) == requestedLocator(second) {
+					continue
+				}
+				a, b := NormalizePath(first.DestinationPath), NormalizePath(second.DestinationPath)
+				if len(pathSegments(b)) < len(pathSegments(a)) {
+					first, second, a, b = second, first, b, a
+				}
+				if !coversSegments(a, b, true) {
+					continue
+				}
+				overlaps = append(overlaps, DestinationOverlap{
+					ContainerName: key[0],
+					Target:        key[1],
+					First:         first,
+					Second:        second,
+					Nested:        a != b,
+				})
+			}
+		}
+	}
+	sort.SliceStable(overlaps, func(i, j int) bool {
+		a, b := overlaps[i], overlaps[j]
+		if a.ContainerName != b.ContainerName {
+			return a.ContainerName < b.ContainerName
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		if pa, pb := NormalizePath(a.First.DestinationPath), NormalizePath(b.First.DestinationPath); pa != pb {
+			return pa < pb
+		}
+		return NormalizePath(a.Second.DestinationPath) < NormalizePath(b.Second.DestinationPath)
+	})
+
+	return overlaps
+}
+
+// DestinationOverlaps checks all stored requested permissions with FindDestinationOverlaps
+func (dm *DbManager) DestinationOverlaps() ([]DestinationOverlap, error) {
+	requested, err := dm.ListRequested()
+	if err != nil {
+		return nil, err
+	}
+
+	return FindDestinationOverlaps(requested), nil
+}
+
+// overlapsOf returns an OverlapError for the overlaps that involve saved
+// permissions, when the DbManager checks overlaps
+func (dm *DbManager) overlapsOf(q querier, saved []Requested) error {
+	if !dm.checkOverlaps || len(saved) == 0 {
+		return nil
+	}
+
+	locators := map[string]bool{}
+	for _, r := range saved {
+		locators[requestedLocator(r)] = true
+	}
+	rows, err := q.Query(fmt.Sprintf(`SELECT %s FROM requested`, requestedColumns))
+	if err != nil {
+		return fmt.Errorf("failed to query requested records: %w", err)
+	}
+	requested, err := scanRequested(rows)
+	if err != nil {
+		return err
+	}
+
+	var overlaps []DestinationOverlap
+	for _, o := range FindDestinationOverlaps(requested) {
+		if locators[requestedLocator(o.First)] || locators[requestedLocator(o.Second)] {
+			overlaps = append(overlaps, o)
+		}
+	}
+	if len(overlaps) > 0 {
+		return &OverlapError{Overlaps: overlaps}
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package syncer
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+// syncTo is a request of the cms/uploads container to sync into path
+func syncTo(path, scheme string) Requested {
+	return Requested{ContainerName: "cms/uploads", Target: "web", RequestScheme: scheme, RequestAction: "sync", DestinationPath: path}
+}
+
+func TestFindDestinationOverlaps(t *testing.T) {
+	// Given
+	public := syncTo("/public", "hive")
+	images := syncTo("/public//images/", "image")
+	sameImages := syncTo("/public/images", "json")
+	sibling := syncTo("/public-images", "hive")
+	otherTarget := Requested{ContainerName: "cms/uploads", Target: "cmd", RequestScheme: "hive", DestinationPath: "/public"}
+	noPath := syncTo("", "hive")
+
+	// When
+	overlaps := FindDestinationOverlaps([]Requested{images, sibling, public, sameImages, otherTarget, noPath})
+
+	// Then
+	is := is.New(t)
+	is.Equal(overlaps, []DestinationOverlap{
+		{ContainerName: "cms/uploads", Target: "web", First: public, Second: images, Nested: true},
+		{ContainerName: "cms/uploads", Target: "web", First: public, Second: sameImages, Nested: true},
+		{ContainerName: "cms/uploads", Target: "web", First: images, Second: sameImages, Nested: false},
+	})
+}
+
+func TestSaveRequested_overlap_check(t *testing.T) {
+	// Given
+	is, _ := setupTestDB(t)
+	dbManager, err := NewDbManager(WithOverlapCheck())
+	is.NoErr(err)
+	defer dbManager.Close()
+	public := syncTo("/public", "hive")
+	is.NoErr(dbManager.SaveRequested([]Requested{public, syncTo("/private", "hive")}))
+
+	// When
+	err = dbManager.SaveRequested([]Requested{syncTo("/data", "hive"), syncTo("/public/images", "image")})
+
+	// Then
+	var overlapErr *OverlapError
+	is.True(errors.As(err, &overlapErr))
+	is.Equal(overlapErr.Overlaps, []DestinationOverlap{
+		{ContainerName: "cms/uploads", Target: "web", First: public, Second: syncTo("/public/images", "image"), Nested: true},
+	})
+	is.Equal(err.Error(), "overlapping destination paths: /public and /public/images in cms/uploads target web")
+	requested, err := dbManager.ListRequested()
+	is.NoErr(err)
+	is.Equal(len(requested), 2) // Nothing of the batch is saved
+}
+
+func TestSaveRequested_overlap_check_ignores_stored_overlaps(t *testing.T) {
+	// Given
+	is := is.New(t)
+	path := filepath.Join(t.TempDir(), "syncer.db")
+	unchecked, err := OpenDbManager(path)
+	is.NoErr(err)
+	defer unchecked.Close()
+	is.NoErr(unchecked.SaveRequested([]Requested{syncTo("/public", "hive"), syncTo("/public", "image")})) // Without the option overlaps are saved
+	checked, err := OpenDbManager(path, WithOverlapCheck())
+	is.NoErr(err)
+	defer checked.Close()
+
+	// When
+	document := `{"requested": [{"ContainerName": "cms/uploads", "Target": "web", "scheme": "json", "destination_path": "/data"}]}`
+	_, importErr := checked.Import(strings.NewReader(document), FormatJSON)
+	_, nestedErr := checked.Import(strings.NewReader(strings.ReplaceAll(document, "/data", "/public/images")), FormatJSON)
+
+	// Then
+	is.NoErr(importErr)
+	var overlapErr *OverlapError
+	is.True(errors.As(nestedErr, &overlapErr))
+	is.Equal(len(overlapErr.Overlaps), 2) // With both stored requests
+	overlaps, err := checked.DestinationOverlaps()
+	is.NoErr(err)
+	is.Equal(len(overlaps), 1)
+}
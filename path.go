@@ -0,0 +1,94 @@
+package syncer
+
+import (
+	"path"
+	"strings"
+)
+
+// NormalizePath returns the canonical form of a destination or expose path.
+// Duplicate slashes are collapsed, . and .. segments are resolved and the
+// trailing slash is removed. A .. segment cannot leave the root of an
+// absolute path. The wildcard and the empty path are returned as they are.
+func NormalizePath(p string) string {
+	if p == "" || p == "*" {
+		return p
+	}
+
+	return path.Clean(p)
+}
+
+// PathCovers reports whether a granted expose path covers a requested
+// destination path. Paths are compared by segment after normalization, so
+// /public/images covers /public/images and /public/images/logo.png but not
+// /public/images-private or /public/images/../../private/keys. In the expose
+// path a * segment matches one segment and a ** segment matches any number
+// of segments, e.g. /sites/**/public covers /sites/a/b/public/index.html.
+// An empty path or the wildcard on either side leaves the path unrestricted.
+func PathCovers(exposePath, destinationPath string) bool {
+	if exposePath == "" || exposePath == "*" || destinationPath == "" || destinationPath == "*" {
+		return true
+	}
+
+	return coversSegments(exposePath, destinationPath, false)
+}
+
+// pathSubsumes reports whether expose path a covers every path that expose
+// path b covers. Globs in b only match the same glob or ** in a.
+func pathSubsumes(a, b string) bool {
+	if a == "" || a == "*" {
+		return true
+	}
+	if b == "" || b == "*" {
+		return false
+	}
+
+	return coversSegments(a, b, true)
+}
+
+func coversSegments(pattern, p string, literalGlobs bool) bool {
+	pattern, p = NormalizePath(pattern), NormalizePath(p)
+	if path.IsAbs(pattern) != path.IsAbs(p) {
+		return false
+	}
+
+	return matchSegments(pathSegments(pattern), pathSegments(p), literalGlobs)
+}
+
+// matchSegments matches the segments of a path against the segments of a
+// pattern, the path may continue below the end of the pattern. With
+// literalGlobs a * pattern segment does not match a ** path segment.
+func matchSegments(pattern, segments []string, literalGlobs bool) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:], literalGlobs) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+
+	switch {
+	case pattern[0] == "*" && !(literalGlobs && segments[0] == "**"):
+	case pattern[0] == segments[0]:
+	default:
+		return false
+	}
+
+	return matchSegments(pattern[1:], segments[1:], literalGlobs)
+}
+
+// pathSegments splits a normalized path, the root has no segments
+func pathSegments(p string) []string {
+	p = strings.TrimPrefix(p, "/")
+	if p == "" || p == "." {
+		return nil
+	}
+
+	return strings.Split(p, "/")
+}
@@ -0,0 +1,118 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "/public//images/", expected: "/public/images"},
+		{path: "/public/./images/../keys", expected: "/public/keys"},
+		{path: "/../../etc", expected: "/etc"},
+		{path: "data/", expected: "data"},
+		{path: "*", expected: "*"},
+		{path: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			is := is.New(t)
+
+			is.Equal(NormalizePath(tt.path), tt.expected)
+		})
+	}
+}
+
+func TestPathCovers(t *testing.T) {
+	tests := []struct {
+		name        string
+		exposePath  string
+		destination string
+		expected    bool
+	}{
+		{name: "same path", exposePath: "/public/images", destination: "/public/images", expected: true},
+		{name: "nested path", exposePath: "/public/images", destination: "/public/images/logo.png", expected: true},
+		{name: "other path", exposePath: "/public/images", destination: "/private/keys", expected: false},
+		{name: "prefix within a segment", exposePath: "/public/images", destination: "/public/images-private", expected: false},
+		{name: "parent path", exposePath: "/public/images", destination: "/public", expected: false},
+		{name: "escape with dot dot", exposePath: "/public/images", destination: "/public/images/../../private/keys", expected: false},
+		{name: "duplicate slashes", exposePath: "//public/images/", destination: "/public//images/logo.png", expected: true},
+		{name: "root", exposePath: "/", destination: "/private/keys", expected: true},
+		{name: "relative and absolute", exposePath: "public", destination: "/public", expected: false},
+		{name: "single segment glob", exposePath: "/sites/*/public", destination: "/sites/cms/public/index.html", expected: true},
+		{name: "single segment glob needs a segment", exposePath: "/sites/*/public", destination: "/sites/public", expected: false},
+		{name: "any segments glob", exposePath: "/sites/**/public", destination: "/sites/a/b/public/index.html", expected: true},
+		{name: "any segments glob matches none", exposePath: "/sites/**/public", destination: "/sites/public", expected: true},
+		{name: "any segments glob needs the rest", exposePath: "/sites/**/public", destination: "/sites/a/private", expected: false},
+		{name: "unrestricted expose path", exposePath: "", destination: "/private/keys", expected: true},
+		{name: "wildcard expose path", exposePath: "*", destination: "/private/keys", expected: true},
+		{name: "unrestricted destination", exposePath: "/public", destination: "", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			is.Equal(PathCovers(tt.exposePath, tt.destination), tt.expected)
+		})
+	}
+}
+
+func TestFindGranted_path(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	images := Granted{ContainerName: "image/container", GrandScheme: "image", GrandAction: "pull", ExposePath: "/public/images"}
+	sites := Granted{ContainerName: "image/container", GrandScheme: "image", GrandAction: "pull", ExposePath: "/sites/**/public"}
+	mockGranted(dbManager, images)
+	mockGranted(dbManager, sites)
+	request := func(path string) Requested {
+		return Requested{ContainerName: "image/container", RequestScheme: "image", RequestAction: "pull", DestinationPath: path}
+	}
+
+	// When
+	logo, err := dbManager.FindGranted([]Requested{request("/public/images/logo.png")})
+	is.NoErr(err)
+	keys, err := dbManager.FindGranted([]Requested{request("/private/keys")})
+	is.NoErr(err)
+	escape, err := dbManager.FindGranted([]Requested{request("/public/images/../../private/keys")})
+	is.NoErr(err)
+	both, err := dbManager.FindGranted([]Requested{request("/public/images"), request("/sites/cms/public")})
+	is.NoErr(err)
+
+	// Then
+	is.Equal(logo, []Granted{images})
+	is.Equal(len(keys), 0)
+	is.Equal(len(escape), 0)
+	is.Equal(len(both), 2)
+}
+
+func TestFindRequested_path(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	logo := Requested{ContainerName: "image/container", RequestScheme: "image", RequestAction: "pull", DestinationPath: "/public/images/logo.png"}
+	keys := Requested{ContainerName: "image/container", RequestScheme: "image", RequestAction: "pull", DestinationPath: "/private/keys"}
+	mockRequested(dbManager, logo)
+	mockRequested(dbManager, keys)
+
+	// When
+	requested, err := dbManager.FindRequested([]Granted{{ContainerName: "image/container", GrandScheme: "image", GrandAction: "pull", ExposePath: "/public"}})
+	is.NoErr(err)
+
+	// Then
+	is.Equal(requested, []Requested{logo})
+}
+
+func TestExplain_path(t *testing.T) {
+	is := is.New(t)
+	requested := Requested{DestinationPath: "/public/images/logo.png"}
+
+	is.Equal(Explain(requested, Granted{ExposePath: "/public"})[8].Reason, ReasonPathCovered)
+	is.Equal(Explain(requested, Granted{ExposePath: "/public/images//logo.png"})[8].Reason, ReasonExact)
+	is.Equal(Explain(requested, Granted{ExposePath: "/private"})[8].Reason, ReasonValueMismatch)
+	is.Equal(Explain(requested, Granted{})[8].Reason, ReasonGrantedWildcard)
+}
@@ -0,0 +1,395 @@
+package syncer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrStalePlan is returned by Apply when the store changed after the plan was made
+var ErrStalePlan = errors.New("the store changed since the plan was made")
+
+// PermissionFile declares the desired requested and granted records of one
+// umbrella repository. Records without an umbrella organization and
+// repository get the ones of the file.
+type PermissionFile struct {
+	// Path is the file the declaration was loaded from
+	Path                 string      `json:"-"`
+	UmbrellaOrganization string      `json:"umbrella_organization"`
+	UmbrellaRepository   string      `json:"umbrella_repository"`
+	Requested            []Requested `json:"requested"`
+	Granted              []Granted   `json:"granted"`
+}
+
+// LoadPermissionFiles reads the .yaml and .yml files in dir and its
+// subdirectories. Every file must declare a different umbrella repository.
+func LoadPermissionFiles(dir string) ([]PermissionFile, error) {
+	var files []PermissionFile
+	var errs []error
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		extension := strings.ToLower(filepath.Ext(path))
+		if entry.IsDir() || (extension != ".yaml" && extension != ".yml") {
+			return nil
+		}
+
+		file, err := loadPermissionFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		for _, other := range files {
+			if other.declares(file.UmbrellaOrganization, file.UmbrellaRepository) {
+				errs = append(errs, fmt.Errorf("%s: umbrella repository %s/%s is already declared in %s",
+					path, file.UmbrellaOrganization, file.UmbrellaRepository, other.Path))
+				return nil
+			}
+		}
+		files = append(files, file)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read permission files: %w", err)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return files, nil
+}
+
+func loadPermissionFile(path string) (PermissionFile, error) {
+	file := PermissionFile{Path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return file, fmt.Errorf("failed to read permission file: %w", err)
+	}
+	if err := decodeStrict(data, FormatYAML, &file); err != nil {
+		return file, fmt.Errorf("%s: %w", path, err)
+	}
+	if file.UmbrellaOrganization == "" || file.UmbrellaRepository == "" {
+		return file, fmt.Errorf("%s: umbrella_organization and umbrella_repository are required", path)
+	}
+
+	var errs []error
+	locators := map[string]string{}
+	for i := range file.Requested {
+		r := &file.Requested[i]
+		name := fmt.Sprintf("requested[%d]", i)
+		if err := file.claim(&r.UmbrellaOrganization, &r.UmbrellaRepository); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", path, name, err))
+			continue
+		}
+		if err := ValidateRequested(*r); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", path, name, err))
+			continue
+		}
+		if other, ok := locators["requested"+requestedLocator(*r)]; ok {
+			errs = append(errs, fmt.Errorf("%s: %s: declares the same record as %s", path, name, other))
+		}
+		locators["requested"+requestedLocator(*r)] = name
+	}
+	for i := range file.Granted {
+		g := &file.Granted[i]
+		name := fmt.Sprintf("granted[%d]", i)
+		if err := file.claim(&g.UmbrellaOrganization, &g.UmbrellaRepository); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", path, name, err))
+			continue
+		}
+		if err := ValidateGranted(*g); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", path, name, err))
+			continue
+		}
+		if other, ok := locators["granted"+grantedLocator(*g)]; ok {
+			errs = append(errs, fmt.Errorf("%s: %s: declares the same record as %s", path, name, other))
+		}
+		locators["granted"+grantedLocator(*g)] = name
+	}
+
+	return file, errors.Join(errs...)
+}
+
+// claim fills an empty umbrella organization and repository of a record with
+// the ones of the file, other values are an error
+func (f PermissionFile) claim(organization, repository *string) error {
+	if *organization == "" && *repository == "" {
+		*organization, *repository = f.UmbrellaOrganization, f.UmbrellaRepository
+		return nil
+	}
+	if !f.declares(*organization, *repository) {
+		return fmt.Errorf("umbrella repository %s/%s does not belong in the file of %s/%s",
+			*organization, *repository, f.UmbrellaOrganization, f.UmbrellaRepository)
+	}
+
+	return nil
+}
+
+// declares reports whether the file manages the records of the umbrella repository
+func (f PermissionFile) declares(organization, repository string) bool {
+	return strings.EqualFold(f.UmbrellaOrganization, organization) && strings.EqualFold(f.UmbrellaRepository, repository)
+}
+
+// PlanAction is what Apply does with a record
+type PlanAction string
+
+const (
+	PlanCreate PlanAction = "create"
+	PlanUpdate PlanAction = "update"
+	PlanDelete PlanAction = "delete"
+)
+
+// PlannedChange is one change that brings the store in line with the
+// permission files. Depending on Kind either the Requested or the Granted
+// values are set, the before value is nil for a create and the after value is
+// nil for a delete.
+type PlannedChange struct {
+	Action          PlanAction `json:"action"`
+	Kind            RecordKind `json:"kind"`
+	Locator         string     `json:"locator"`
+	File            string     `json:"file"`
+	BeforeRequested *Requested `json:"before_requested,omitempty"`
+	AfterRequested  *Requested `json:"after_requested,omitempty"`
+	BeforeGranted   *Granted   `json:"before_granted,omitempty"`
+	AfterGranted    *Granted   `json:"after_granted,omitempty"`
+}
+
+// Plan is the difference between the permission files and the store
+type Plan struct {
+	Changes []PlannedChange `json:"changes"`
+}
+
+// Count returns the number of changes with the given action
+func (p Plan) Count(action PlanAction) int {
+	count := 0
+	for _, change := range p.Changes {
+		if change.Action == action {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Plan compares the permission files with the store. Within the umbrella
+// repository of a file, declared records that are missing are created,
+// records that differ in a value outside their locator are updated and stored
+// records that are not declared are deleted. Records of umbrella repositories
+// without a file are left alone.
+func (dm *DbManager) Plan(files []PermissionFile) (Plan, error) {
+	plan := Plan{Changes: []PlannedChange{}}
+
+	storedRequested, err := dm.ListRequested()
+	if err != nil {
+		return plan, err
+	}
+	storedGranted, err := dm.ListGranted()
+	if err != nil {
+		return plan, err
+	}
+
+	for _, file := range files {
+		stored := map[string]Requested{}
+		for _, r := range storedRequested {
+			if file.declares(r.UmbrellaOrganization, r.UmbrellaRepository) {
+				stored[requestedLocator(r)] = r
+			}
+		}
+		declared := map[string]bool{}
+		for _, r := range file.Requested {
+			locator := requestedLocator(r)
+			declared[locator] = true
+			after := r
+			before, ok := stored[locator]
+			switch {
+			case !ok:
+				plan.Changes = append(plan.Changes, PlannedChange{Action: PlanCreate, Kind: KindRequested, Locator: locator, File: file.Path, AfterRequested: &after})
+			case before != r:
+				plan.Changes = append(plan.Changes, PlannedChange{Action: PlanUpdate, Kind: KindRequested, Locator: locator, File: file.Path, BeforeRequested: &before, AfterRequested: &after})
+			}
+		}
+		for _, r := range storedRequested {
+			locator := requestedLocator(r)
+			if _, ok := stored[locator]; ok && !declared[locator] {
+				before := r
+				plan.Changes = append(plan.Changes, PlannedChange{Action: PlanDelete, Kind: KindRequested, Locator: locator, File: file.Path, BeforeRequested: &before})
+			}
+		}
+	}
+
+	for _, file := range files {
+		stored := map[string]Granted{}
+		for _, g := range storedGranted {
+			if file.declares(g.UmbrellaOrganization, g.UmbrellaRepository) {
+				stored[grantedLocator(g)] = g
+			}
+		}
+		declared := map[string]bool{}
+		for _, g := range file.Granted {
+			locator := grantedLocator(g)
+			declared[locator] = true
+			after := g
+			before, ok := stored[locator]
+			switch {
+			case !ok:
+				plan.Changes = append(plan.Changes, PlannedChange{Action: PlanCreate, Kind: KindGranted, Locator: locator, File: file.Path, AfterGranted: &after})
+			case !sameGranted(before, g):
+				plan.Changes = append(plan.Changes, PlannedChange{Action: PlanUpdate, Kind: KindGranted, Locator: locator, File: file.Path, BeforeGranted: &before, AfterGranted: &after})
+			}
+		}
+		for _, g := range storedGranted {
+			locator := grantedLocator(g)
+			if _, ok := stored[locator]; ok && !declared[locator] {
+				before := g
+				plan.Changes = append(plan.Changes, PlannedChange{Action: PlanDelete, Kind: KindGranted, Locator: locator, File: file.Path, BeforeGranted: &before})
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// Apply executes a plan in one transaction. It fails with ErrStalePlan when a
+// record no longer has the value the plan started from.
+func (dm *DbManager) Apply(plan Plan) error {
+	tx, err := dm.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var savedRequested, deletedRequested []Requested
+	var savedGranted, deletedGranted []Granted
+	for _, change := range plan.Changes {
+		switch change.Kind {
+		case KindRequested:
+			current, err := getRequested(tx, change.Locator)
+			if err != nil {
+				return err
+			}
+			if !sameRecord(current, change.BeforeRequested, func(a, b Requested) bool { return a == b }) {
+				return fmt.Errorf("%w: requested %s", ErrStalePlan, change.Locator)
+			}
+			if change.Action == PlanDelete {
+				if _, err := dm.deleteRequested(tx, change.Locator); err != nil {
+					return err
+				}
+				deletedRequested = append(deletedRequested, *change.BeforeRequested)
+				continue
+			}
+			if err := dm.saveRequested(tx, *change.AfterRequested); err != nil {
+				return err
+			}
+			savedRequested = append(savedRequested, *change.AfterRequested)
+		case KindGranted:
+			current, err := getGranted(tx, change.Locator)
+			if err != nil {
+				return err
+			}
+			if !sameRecord(current, change.BeforeGranted, sameGranted) {
+				return fmt.Errorf("%w: granted %s", ErrStalePlan, change.Locator)
+			}
+			if change.Action == PlanDelete {
+				if _, err := dm.deleteGranted(tx, change.Locator); err != nil {
+					return err
+				}
+				deletedGranted = append(deletedGranted, *change.BeforeGranted)
+				continue
+			}
+			if err := dm.saveGranted(tx, *change.AfterGranted); err != nil {
+				return err
+			}
+			savedGranted = append(savedGranted, *change.AfterGranted)
+		default:
+			return fmt.Errorf("unknown record kind %q in plan", change.Kind)
+		}
+	}
+
+	if err := dm.overlapsOf(tx, savedRequested); err != nil {
+		return err
+	}
+
+	events, err := dm.requestedChanges(tx, ChangeSaved, savedRequested)
+	if err != nil {
+		return err
+	}
+	deletedEvents, err := dm.requestedChanges(tx, ChangeDeleted, deletedRequested)
+	if err != nil {
+		return err
+	}
+	events = append(events, deletedEvents...)
+	grantedEvents, err := dm.grantedChanges(tx, ChangeSaved, savedGranted)
+	if err != nil {
+		return err
+	}
+	events = append(events, grantedEvents...)
+	deletedEvents, err = dm.grantedChanges(tx, ChangeDeleted, deletedGranted)
+	if err != nil {
+		return err
+	}
+	events = append(events, deletedEvents...)
+
+	if err := dm.commit(tx, events); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// sameRecord compares two optional records
+func sameRecord[T any](a, b *T, equal func(a, b T) bool) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return equal(*a, *b)
+}
+
+// DriftState tells how a record in the store differs from the permission files
+type DriftState string
+
+const (
+	// DriftMissing is a declared record that is not stored
+	DriftMissing DriftState = "missing"
+	// DriftChanged is a stored record with other values than declared
+	DriftChanged DriftState = "changed"
+	// DriftUnmanaged is a stored record of a declared umbrella repository that is not declared itself
+	DriftUnmanaged DriftState = "unmanaged"
+)
+
+// Drift is one record that differs between the store and the permission files
+type Drift struct {
+	State   DriftState `json:"state"`
+	Kind    RecordKind `json:"kind"`
+	Locator string     `json:"locator"`
+	File    string     `json:"file"`
+}
+
+// DetectDrift lists the records where the store deviates from the permission
+// files, for example after a change outside of Apply. No drift means an
+// empty plan.
+func (dm *DbManager) DetectDrift(files []PermissionFile) ([]Drift, error) {
+	plan, err := dm.Plan(files)
+	if err != nil {
+		return nil, err
+	}
+
+	states := map[PlanAction]DriftState{
+		PlanCreate: DriftMissing,
+		PlanUpdate: DriftChanged,
+		PlanDelete: DriftUnmanaged,
+	}
+	drift := []Drift{}
+	for _, change := range plan.Changes {
+		drift = append(drift, Drift{State: states[change.Action], Kind: change.Kind, Locator: change.Locator, File: change.File})
+	}
+
+	return drift, nil
+}
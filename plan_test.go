@@ -0,0 +1,208 @@
+package syncer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writePermissionFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const cmsPermissions = `
+umbrella_organization: confetti-sites
+umbrella_repository: confetti-cms
+granted:
+  - ContainerName: image/container
+    scheme: image
+    expires_at: 2100-01-01T00:00:00Z
+  - ContainerName: image/new
+    scheme: image
+requested:
+  - ContainerName: image/container
+    scheme: image
+`
+
+func TestLoadPermissionFiles(t *testing.T) {
+	// Given
+	is, _ := setupTestDB(t)
+	dir := t.TempDir()
+	writePermissionFile(t, dir, "confetti-sites/confetti-cms.yaml", cmsPermissions)
+	writePermissionFile(t, dir, "README.md", "not a permission file")
+
+	// When
+	files, err := LoadPermissionFiles(dir)
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(files), 1)
+	is.Equal(files[0].Path, filepath.Join(dir, "confetti-sites/confetti-cms.yaml"))
+	is.Equal(len(files[0].Granted), 2)
+	is.Equal(files[0].Granted[0].UmbrellaOrganization, "confetti-sites") // Taken from the file
+	is.Equal(files[0].Requested[0].UmbrellaRepository, "confetti-cms")
+}
+
+func TestLoadPermissionFiles_invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			"missing umbrella repository",
+			map[string]string{"a.yaml": "granted: []"},
+			"a.yaml: umbrella_organization and umbrella_repository are required",
+		},
+		{
+			"invalid YAML with line",
+			map[string]string{"a.yaml": "umbrella_organization: o\n  bad: [\n"},
+			"a.yaml: invalid YAML document: yaml: line",
+		},
+		{
+			"record of another umbrella repository",
+			map[string]string{"a.yaml": "umbrella_organization: o\numbrella_repository: r\ngranted:\n  - UmbrellaOrganization: other\n    UmbrellaRepository: r\n"},
+			"a.yaml: granted[0]: umbrella repository other/r does not belong in the file of o/r",
+		},
+		{
+			"invalid record",
+			map[string]string{"a.yaml": "umbrella_organization: o\numbrella_repository: r\nrequested:\n  - ContainerName: /image/\n"},
+			"a.yaml: requested[0]: container name",
+		},
+		{
+			"duplicate record",
+			map[string]string{"a.yaml": "umbrella_organization: o\numbrella_repository: r\ngranted:\n  - scheme: image\n  - scheme: image\n"},
+			"a.yaml: granted[1]: declares the same record as granted[0]",
+		},
+		{
+			"umbrella repository in two files",
+			map[string]string{"a.yaml": "umbrella_organization: o\numbrella_repository: r\n", "b.yml": "umbrella_organization: O\numbrella_repository: R\n"},
+			"b.yml: umbrella repository O/R is already declared in",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			is, _ := setupTestDB(t)
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writePermissionFile(t, dir, name, content)
+			}
+
+			// When
+			_, err := LoadPermissionFiles(dir)
+
+			// Then
+			is.True(err != nil)
+			is.True(strings.Contains(err.Error(), tt.want))
+		})
+	}
+}
+
+func TestPlan_and_Apply(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	dir := t.TempDir()
+	writePermissionFile(t, dir, "cms.yaml", cmsPermissions)
+	files, err := LoadPermissionFiles(dir)
+	is.NoErr(err)
+	changed := Granted{ContainerName: "image/container", UmbrellaOrganization: "confetti-sites", UmbrellaRepository: "confetti-cms", GrandScheme: "image"}
+	unmanaged := Granted{ContainerName: "image/old", UmbrellaOrganization: "Confetti-Sites", UmbrellaRepository: "confetti-cms", GrandScheme: "image"}
+	otherUmbrella := Granted{ContainerName: "image/container", UmbrellaOrganization: "other", UmbrellaRepository: "repo", GrandScheme: "image"}
+	mockGranted(dbManager, changed)
+	mockGranted(dbManager, unmanaged)
+	mockGranted(dbManager, otherUmbrella)
+
+	// When
+	plan, err := dbManager.Plan(files)
+
+	// Then
+	is.NoErr(err)
+	is.Equal(plan.Count(PlanCreate), 2) // The new grant and the request
+	is.Equal(plan.Count(PlanUpdate), 1)
+	is.Equal(plan.Count(PlanDelete), 1)
+	for _, change := range plan.Changes {
+		is.Equal(change.File, filepath.Join(dir, "cms.yaml"))
+		switch change.Action {
+		case PlanUpdate:
+			is.Equal(*change.BeforeGranted, changed)
+			is.Equal(change.AfterGranted.ExpiresAt, time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC))
+		case PlanDelete:
+			is.Equal(*change.BeforeGranted, unmanaged)
+		}
+	}
+
+	// When
+	is.NoErr(dbManager.Apply(plan))
+
+	// Then
+	granted, err := dbManager.ListGranted()
+	is.NoErr(err)
+	is.Equal(len(granted), 3) // Two declared grants and the grant of the other umbrella repository
+	requested, err := dbManager.ListRequested()
+	is.NoErr(err)
+	is.Equal(len(requested), 1)
+	replanned, err := dbManager.Plan(files)
+	is.NoErr(err)
+	is.Equal(len(replanned.Changes), 0)
+}
+
+func TestApply_stale_plan(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	dir := t.TempDir()
+	writePermissionFile(t, dir, "cms.yaml", cmsPermissions)
+	files, err := LoadPermissionFiles(dir)
+	is.NoErr(err)
+	plan, err := dbManager.Plan(files)
+	is.NoErr(err)
+	mockRequested(dbManager, files[0].Requested[0]) // Changed after planning
+
+	// When
+	err = dbManager.Apply(plan)
+
+	// Then
+	is.True(errors.Is(err, ErrStalePlan))
+	granted, err := dbManager.ListGranted()
+	is.NoErr(err)
+	is.Equal(len(granted), 0) // Nothing is applied
+}
+
+func TestDetectDrift(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	dir := t.TempDir()
+	writePermissionFile(t, dir, "cms.yaml", cmsPermissions)
+	files, err := LoadPermissionFiles(dir)
+	is.NoErr(err)
+	plan, err := dbManager.Plan(files)
+	is.NoErr(err)
+	is.NoErr(dbManager.Apply(plan))
+
+	// When
+	clean, err := dbManager.DetectDrift(files)
+	is.NoErr(err)
+	is.NoErr(dbManager.DeleteGranted(files[0].Granted[1]))
+	drift, err := dbManager.DetectDrift(files)
+	is.NoErr(err)
+
+	// Then
+	is.Equal(clean, []Drift{})
+	is.Equal(drift, []Drift{{
+		State:   DriftMissing,
+		Kind:    KindGranted,
+		Locator: grantedLocator(files[0].Granted[1]),
+		File:    filepath.Join(dir, "cms.yaml"),
+	}})
+}
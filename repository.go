@@ -1,10 +1,12 @@
 package syncer
 
 import (
+	"database/sql"
 	"encoding/hex"
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 )
 
 type Requested struct {
@@ -45,6 +47,18 @@ type Granted struct {
 	GrandUmbrellaRepository   string `json:"umbrella_repository,omitempty"`
 	GrandContainerName        string `json:"container_name,omitempty"`
 	GrandTarget               string `json:"target,omitempty"`
+	// NotBefore and ExpiresAt limit the time the grant is active, a zero value means no limit
+	NotBefore time.Time `json:"not_before,omitzero"`
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+}
+
+// ActiveAt reports whether the grant is active at the given time
+func (g Granted) ActiveAt(t time.Time) bool {
+	if !g.NotBefore.IsZero() && t.Before(g.NotBefore) {
+		return false
+	}
+
+	return g.ExpiresAt.IsZero() || t.Before(g.ExpiresAt)
 }
 
 func (dm *DbManager) SaveRequested(requested []Requested) error {
@@ -54,10 +68,44 @@ func (dm *DbManager) SaveRequested(requested []Requested) error {
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.Prepare(`
+	for _, req := range requested {
+		if err := dm.saveRequested(tx, req); err != nil {
+			return err
+		}
+	}
+	if err := dm.overlapsOf(tx, requested); err != nil {
+		return err
+	}
+
+	events, err := dm.requestedChanges(tx, ChangeSaved, requested)
+	if err != nil {
+		return err
+	}
+
+	if err := dm.commit(tx, events); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// saveRequested stores one requested record with its history and audit entry
+func (dm *DbManager) saveRequested(q querier, req Requested) error {
+	if err := dm.schemes.validateRequested(req); err != nil {
+		return err
+	}
+
+	locator := requestedLocator(req)
+	before, err := getRequested(q, locator)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(`
 	INSERT INTO requested (
 		locator,
 		description,
+		host,
 		expose_path,
 		source_organization,
 		source_repository,
@@ -73,9 +121,10 @@ func (dm *DbManager) SaveRequested(requested []Requested) error {
 		request_umbrella_repository,
 		request_container_name,
 		request_target
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	ON CONFLICT(locator) DO UPDATE SET
 			description=excluded.description,
+		host=excluded.host,
 		expose_path=excluded.expose_path,
 		source_organization=excluded.source_organization,
 		source_repository=excluded.source_repository,
@@ -91,93 +140,84 @@ func (dm *DbManager) SaveRequested(requested []Requested) error {
 		request_umbrella_repository=excluded.request_umbrella_repository,
 		request_container_name=excluded.request_container_name,
 		request_target=excluded.request_target;
-	`)
+	`,
+		locator,
+		req.Description,
+		req.Host,
+		req.DestinationPath,
+		req.SourceOrganization,
+		req.SourceRepository,
+		req.UmbrellaOrganization,
+		req.UmbrellaRepository,
+		req.ContainerName,
+		req.Target,
+		req.RequestScheme,
+		req.RequestAction,
+		req.RequestSourceOrganization,
+		req.RequestSourceRepository,
+		req.RequestUmbrellaOrganization,
+		req.RequestUmbrellaRepository,
+		req.RequestContainerName,
+		req.RequestTarget,
+	)
 	if err != nil {
-		return fmt.Errorf("failed to prepare statement: %w", err)
+		return fmt.Errorf("failed to save requested record: %w", err)
 	}
-	defer stmt.Close()
 
-	for _, req := range requested {
-		// Compute the locator hash
-		data := fmt.Sprintf("%s%s%s%s%s%s%s%s%s%s%s%s%s%s%s%s",
-			req.Description,
-			req.DestinationPath,
-			req.SourceOrganization,
-			req.SourceRepository,
-			req.UmbrellaOrganization,
-			req.UmbrellaRepository,
-			req.ContainerName,
-			req.Target,
-			req.RequestScheme,
-			req.RequestAction,
-			req.RequestSourceOrganization,
-			req.RequestSourceRepository,
-			req.RequestUmbrellaOrganization,
-			req.RequestUmbrellaRepository,
-			req.RequestContainerName,
-			req.RequestTarget,
-		)
+	if err := dm.recordHistory(q, "requested", requestedColumns, locator); err != nil {
+		return err
+	}
 
-		locator := hex.EncodeToString([]byte(data))
-
-		_, err := stmt.Exec(
-			locator,
-			req.Description,
-			req.DestinationPath,
-			req.SourceOrganization,
-			req.SourceRepository,
-			req.UmbrellaOrganization,
-			req.UmbrellaRepository,
-			req.ContainerName,
-			req.Target,
-			req.RequestScheme,
-			req.RequestAction,
-			req.RequestSourceOrganization,
-			req.RequestSourceRepository,
-			req.RequestUmbrellaOrganization,
-			req.RequestUmbrellaRepository,
-			req.RequestContainerName,
-			req.RequestTarget,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to execute statement: %w", err)
+	return dm.auditRequested(q, ChangeSaved, locator, before, &req)
+}
+
+func (dm *DbManager) SaveGranted(granted Granted) error {
+	return dm.SaveGrantedBatch([]Granted{granted})
+}
+
+// SaveGrantedBatch saves granted permissions in one transaction, none are saved when one fails
+func (dm *DbManager) SaveGrantedBatch(granted []Granted) error {
+	tx, err := dm.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, g := range granted {
+		if err := dm.saveGranted(tx, g); err != nil {
+			return err
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	events, err := dm.grantedChanges(tx, ChangeSaved, granted)
+	if err != nil {
+		return err
+	}
+
+	if err := dm.commit(tx, events); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-func (dm *DbManager) SaveGranted(granted Granted) error {
-	// Compute the locator hash
-	data := fmt.Sprintf("%s%s%s%s%s%s%s%s%s%s%s%s%s%s%s%s",
-		granted.Description,
-		granted.ExposePath,
-		granted.SourceOrganization,
-		granted.SourceRepository,
-		granted.UmbrellaOrganization,
-		granted.UmbrellaRepository,
-		granted.ContainerName,
-		granted.Target,
-		granted.GrandScheme,
-		granted.GrandAction,
-		granted.GrandSourceOrganization,
-		granted.GrandSourceRepository,
-		granted.GrandUmbrellaOrganization,
-		granted.GrandUmbrellaRepository,
-		granted.GrandContainerName,
-		granted.GrandTarget,
-	)
+// saveGranted stores one granted record with its history and audit entry
+func (dm *DbManager) saveGranted(q querier, granted Granted) error {
+	if err := dm.schemes.validateGranted(granted); err != nil {
+		return err
+	}
 
-	locator := hex.EncodeToString([]byte(data))
+	locator := grantedLocator(granted)
+	before, err := getGranted(q, locator)
+	if err != nil {
+		return err
+	}
 
 	query := `
 	INSERT INTO granted (
 		locator,
 		description,
+		host,
 		expose_path,
 		source_organization,
 		source_repository,
@@ -192,10 +232,13 @@ func (dm *DbManager) SaveGranted(granted Granted) error {
 		grand_umbrella_organization,
 		grand_umbrella_repository,
 		grand_container_name,
-		grand_target
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		grand_target,
+		not_before,
+		expires_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	 ON CONFLICT(locator) DO UPDATE SET
 	 	description=excluded.description,
+	 	host=excluded.host,
 	 	expose_path=excluded.expose_path,
 	 	source_organization=excluded.source_organization,
 			source_repository=excluded.source_repository,
@@ -210,12 +253,15 @@ func (dm *DbManager) SaveGranted(granted Granted) error {
 			grand_umbrella_organization=excluded.grand_umbrella_organization,
 			grand_umbrella_repository=excluded.grand_umbrella_repository,
 			grand_container_name=excluded.grand_container_name,
-			grand_target=excluded.grand_target;
+			grand_target=excluded.grand_target,
+			not_before=excluded.not_before,
+			expires_at=excluded.expires_at;
 	`
 
-	_, err := dm.db.Exec(query,
+	_, err = q.Exec(query,
 		locator,
 		granted.Description,
+		granted.Host,
 		granted.ExposePath,
 		granted.SourceOrganization,
 		granted.SourceRepository,
@@ -231,21 +277,219 @@ func (dm *DbManager) SaveGranted(granted Granted) error {
 		granted.GrandUmbrellaRepository,
 		granted.GrandContainerName,
 		granted.GrandTarget,
+		unixNano(granted.NotBefore),
+		unixNano(granted.ExpiresAt),
 	)
+	if err != nil {
+		return fmt.Errorf("failed to save granted record: %w", err)
+	}
+
+	if err := dm.recordHistory(q, "granted", grantedColumns, locator); err != nil {
+		return err
+	}
+
+	return dm.auditGranted(q, ChangeSaved, locator, before, &granted)
+}
 
-	return err
+// DeleteRequested removes the stored requested permissions with the same values as the given ones
+func (dm *DbManager) DeleteRequested(requested []Requested) error {
+	tx, err := dm.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var deleted []Requested
+	for _, req := range requested {
+		before, err := dm.deleteRequested(tx, requestedLocator(req))
+		if err != nil {
+			return err
+		}
+		if before != nil {
+			deleted = append(deleted, req)
+		}
+	}
+
+	events, err := dm.requestedChanges(tx, ChangeDeleted, deleted)
+	if err != nil {
+		return err
+	}
+
+	if err := dm.commit(tx, events); err != nil {
+		return err
+	}
+
+	return nil
 }
 
-// FindRequested finds requested permissions that match the granted permissions using database queries
-func (dm *DbManager) FindRequested(granted []Granted) ([]Requested, error) {
+// DeleteGranted removes the stored granted permission with the same values as the given one
+func (dm *DbManager) DeleteGranted(granted Granted) error {
+	return dm.deleteGrantedBatch([]Granted{granted})
+}
+
+// deleteGrantedBatch removes granted records in one transaction, none are removed when one fails
+func (dm *DbManager) deleteGrantedBatch(granted []Granted) error {
+	tx, err := dm.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var deleted []Granted
+	for _, g := range granted {
+		before, err := dm.deleteGranted(tx, grantedLocator(g))
+		if err != nil {
+			return err
+		}
+		if before != nil {
+			deleted = append(deleted, g)
+		}
+	}
+	if len(deleted) == 0 {
+		return nil
+	}
+
+	events, err := dm.grantedChanges(tx, ChangeDeleted, deleted)
+	if err != nil {
+		return err
+	}
+
+	if err := dm.commit(tx, events); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// deleteRequested removes one requested record with its history and audit
+// entry, it returns the removed record or nil when there was none
+func (dm *DbManager) deleteRequested(q querier, locator string) (*Requested, error) {
+	before, err := getRequested(q, locator)
+	if err != nil || before == nil {
+		return nil, err
+	}
+
+	if _, err := q.Exec(`DELETE FROM requested WHERE locator = ?`, locator); err != nil {
+		return nil, fmt.Errorf("failed to delete requested record: %w", err)
+	}
+	if err := dm.recordHistory(q, "requested", requestedColumns, locator); err != nil {
+		return nil, err
+	}
+	if err := dm.auditRequested(q, ChangeDeleted, locator, before, nil); err != nil {
+		return nil, err
+	}
+
+	return before, nil
+}
+
+// deleteGranted removes one granted record with its history and audit entry,
+// it returns the removed record or nil when there was none
+func (dm *DbManager) deleteGranted(q querier, locator string) (*Granted, error) {
+	before, err := getGranted(q, locator)
+	if err != nil || before == nil {
+		return nil, err
+	}
+
+	if _, err := q.Exec(`DELETE FROM granted WHERE locator = ?`, locator); err != nil {
+		return nil, fmt.Errorf("failed to delete granted record: %w", err)
+	}
+	if err := forgetRoleGrants(q, locator); err != nil {
+		return nil, err
+	}
+	if err := dm.recordHistory(q, "granted", grantedColumns, locator); err != nil {
+		return nil, err
+	}
+	if err := dm.auditGranted(q, ChangeDeleted, locator, before, nil); err != nil {
+		return nil, err
+	}
+
+	return before, nil
+}
+
+// requestedLocator computes the primary key of a requested record from its values
+func requestedLocator(req Requested) string {
+	data := fmt.Sprintf("%s%s%s%s%s%s%s%s%s%s%s%s%s%s%s%s",
+		req.Description,
+		req.DestinationPath,
+		req.SourceOrganization,
+		req.SourceRepository,
+		req.UmbrellaOrganization,
+		req.UmbrellaRepository,
+		req.ContainerName,
+		req.Target,
+		req.RequestScheme,
+		req.RequestAction,
+		req.RequestSourceOrganization,
+		req.RequestSourceRepository,
+		req.RequestUmbrellaOrganization,
+		req.RequestUmbrellaRepository,
+		req.RequestContainerName,
+		req.RequestTarget,
+	)
+
+	return hex.EncodeToString([]byte(data))
+}
+
+// grantedLocator computes the primary key of a granted record from its values
+func grantedLocator(granted Granted) string {
+	data := fmt.Sprintf("%s%s%s%s%s%s%s%s%s%s%s%s%s%s%s%s",
+		granted.Description,
+		granted.ExposePath,
+		granted.SourceOrganization,
+		granted.SourceRepository,
+		granted.UmbrellaOrganization,
+		granted.UmbrellaRepository,
+		granted.ContainerName,
+		granted.Target,
+		granted.GrandScheme,
+		granted.GrandAction,
+		granted.GrandSourceOrganization,
+		granted.GrandSourceRepository,
+		granted.GrandUmbrellaOrganization,
+		granted.GrandUmbrellaRepository,
+		granted.GrandContainerName,
+		granted.GrandTarget,
+	)
+
+	return hex.EncodeToString([]byte(data))
+}
+
+// FindRequested finds requested permissions that match the granted permissions using database queries.
+// Grants that are not active at the current time match nothing.
+func (dm *DbManager) FindRequested(granted []Granted, opts ...QueryOption) ([]Requested, error) {
+	now, from, err := dm.resolveQuery("requested", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return findRequestedIn(dm.db, from, now, granted)
+}
+
+func findRequested(q querier, now time.Time, granted []Granted) ([]Requested, error) {
+	return findRequestedIn(q, currentSnapshot("requested"), now, granted)
+}
+
+func findRequestedIn(q querier, from snapshot, now time.Time, granted []Granted) ([]Requested, error) {
+	granted = activeGranted(granted, now)
 	if len(granted) == 0 {
 		return []Requested{}, nil
 	}
 
+	// Grants on an owner also apply to the requests of its descendants
+	rules, err := loadMatchRulesIn(q, from)
+	if err != nil {
+		return nil, err
+	}
+	var inherited []Granted
+	for _, g := range granted {
+		inherited = append(inherited, rules.owners.lowerGranted(g)...)
+	}
+	granted = inherited
+
 	// Build query to find requested records where both scheme and action match
 	// We need to handle multiple granted items, so we'll build conditions for each
 	var conditions []string
-	var args []interface{}
+	args := append([]interface{}{}, from.args...)
 
 	for _, g := range granted {
 		// Each granted item needs both scheme and action to match
@@ -261,15 +505,9 @@ func (dm *DbManager) FindRequested(granted []Granted) ([]Requested, error) {
 			args = append(args, g.GrandScheme)
 		}
 
-		var actionCondition string
-		if g.GrandAction == "*" {
-			// If GrandAction is "*", match any RequestAction - no condition needed
-			actionCondition = "1=1" // Always true condition
-		} else {
-			// Match when request_action equals the grand_action OR request_action is "*"
-			actionCondition = "(request_action = ? OR request_action = '*')"
-			args = append(args, g.GrandAction)
-		}
+		// Match when request_action equals the grand_action, is "*" or is implied by the grand_action
+		actionCondition, actionArgs := rules.actions.requestedActionCondition(g.GrandScheme, g.GrandAction)
+		args = append(args, actionArgs...)
 
 		// Organization and Repository matching conditions
 		var sourceOrgCondition string
@@ -343,61 +581,100 @@ func (dm *DbManager) FindRequested(granted []Granted) ([]Requested, error) {
 			schemeCondition, actionCondition, sourceOrgCondition, sourceRepoCondition, umbrellaOrgCondition, umbrellaRepoCondition, containerNameCondition, targetCondition))
 	}
 
-	query := fmt.Sprintf(`SELECT description, expose_path, source_organization,
-		source_repository, umbrella_organization, umbrella_repository,
-		container_name, target, request_scheme, request_action, request_source_organization,
-		request_source_repository, request_umbrella_organization, request_umbrella_repository,
-		request_container_name, request_target FROM requested WHERE %s`,
-		strings.Join(conditions, " OR "))
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE %s`,
+		requestedColumns, from.from, strings.Join(conditions, " OR "))
 
-	rows, err := dm.db.Query(query, args...)
+	rows, err := q.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query requested records: %w", err)
 	}
-	defer rows.Close()
+	candidates, err := scanRequested(rows)
+	if err != nil {
+		return nil, err
+	}
 
-	var requested []Requested
-	for rows.Next() {
-		var r Requested
-		err := rows.Scan(
-			&r.Description,
-			&r.DestinationPath,
-			&r.SourceOrganization,
-			&r.SourceRepository,
-			&r.UmbrellaOrganization,
-			&r.UmbrellaRepository,
-			&r.ContainerName,
-			&r.Target,
-			&r.RequestScheme,
-			&r.RequestAction,
-			&r.RequestSourceOrganization,
-			&r.RequestSourceRepository,
-			&r.RequestUmbrellaOrganization,
-			&r.RequestUmbrellaRepository,
-			&r.RequestContainerName,
-			&r.RequestTarget,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan requested record: %w", err)
+	// Paths are compared by segment, which the query cannot express
+	result := []Requested{}
+	for _, r := range candidates {
+		for _, g := range granted {
+			if matches(r, g, rules.actions) {
+				result = append(result, r)
+				break
+			}
 		}
-		requested = append(requested, r)
 	}
 
-	return requested, rows.Err()
+	return result, nil
+}
+
+// FindGranted finds granted permissions that match the requested permissions using database queries.
+// Only grants that are active at the current time are returned. The decision
+// for every requested permission is passed to the DecisionLogger, if any.
+func (dm *DbManager) FindGranted(requested []Requested, opts ...QueryOption) ([]Granted, error) {
+	inherited, err := dm.findGrantedWithDepth(requested, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return inheritedGrants(inherited), nil
+}
+
+// findGrantedWithDepth is FindGranted with the inheritance depth of every grant
+func (dm *DbManager) findGrantedWithDepth(requested []Requested, opts []QueryOption) ([]InheritedGrant, error) {
+	now, from, err := dm.resolveQuery("granted", opts)
+	if err != nil {
+		return nil, err
+	}
+	inherited, err := findGrantedWithDepthIn(dm.db, from, now, requested)
+	if err != nil {
+		return nil, err
+	}
+	if !from.historic {
+		if err := dm.logDecisions(now, requested, inheritedGrants(inherited)); err != nil {
+			return nil, err
+		}
+	}
+
+	return inherited, nil
 }
 
-// FindGranted finds granted permissions that match the requested permissions using database queries
-func (dm *DbManager) FindGranted(requested []Requested) ([]Granted, error) {
+func findGranted(q querier, now time.Time, requested []Requested) ([]Granted, error) {
+	return findGrantedIn(q, currentSnapshot("granted"), now, requested)
+}
+
+func findGrantedIn(q querier, from snapshot, now time.Time, requested []Requested) ([]Granted, error) {
+	inherited, err := findGrantedWithDepthIn(q, from, now, requested)
+	if err != nil {
+		return nil, err
+	}
+
+	return inheritedGrants(inherited), nil
+}
+
+// findGrantedWithDepthIn finds the grants with the inheritance depth at which
+// they match the closest of the requests
+func findGrantedWithDepthIn(q querier, from snapshot, now time.Time, requested []Requested) ([]InheritedGrant, error) {
 	if len(requested) == 0 {
-		return []Granted{}, nil
+		return []InheritedGrant{}, nil
+	}
+
+	// Requests also match the grants on the ancestors of their owners
+	rules, err := loadMatchRulesIn(q, from)
+	if err != nil {
+		return nil, err
+	}
+	var variants []inheritedRequested
+	for _, r := range requested {
+		variants = append(variants, rules.owners.liftRequested(r)...)
 	}
 
 	// Build query to find granted records where both scheme and action match
 	// We need to handle multiple requested items, so we'll build conditions for each
 	var conditions []string
-	var args []interface{}
+	args := append([]interface{}{}, from.args...)
 
-	for _, req := range requested {
+	for _, v := range variants {
+		req := v.Requested
 		// Each requested item needs both scheme and action to match
 		// Handle wildcards in either RequestScheme/GrandScheme and RequestAction/GrandAction
 
@@ -411,15 +688,9 @@ func (dm *DbManager) FindGranted(requested []Requested) ([]Granted, error) {
 			args = append(args, req.RequestScheme)
 		}
 
-		var actionCondition string
-		if req.RequestAction == "*" {
-			// If RequestAction is "*", match any GrandAction - no condition needed
-			actionCondition = "1=1" // Always true condition
-		} else {
-			// Match when grand_action equals the request_action OR grand_action is "*"
-			actionCondition = "(grand_action = ? OR grand_action = '*')"
-			args = append(args, req.RequestAction)
-		}
+		// Match when grand_action equals the request_action, is "*" or implies the request_action
+		actionCondition, actionArgs := rules.actions.grantedActionCondition(req.RequestScheme, req.RequestAction)
+		args = append(args, actionArgs...)
 
 		// Organization and Repository matching conditions
 		var sourceOrgCondition string
@@ -493,24 +764,237 @@ func (dm *DbManager) FindGranted(requested []Requested) ([]Granted, error) {
 			schemeCondition, actionCondition, sourceOrgCondition, sourceRepoCondition, umbrellaOrgCondition, umbrellaRepoCondition, containerNameCondition, targetCondition))
 	}
 
-	query := fmt.Sprintf(`SELECT description, expose_path, source_organization,
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE (%s)
+		AND (not_before IS NULL OR not_before <= ?)
+		AND (expires_at IS NULL OR expires_at > ?)`,
+		grantedColumns, from.from, strings.Join(conditions, " OR "))
+	args = append(args, now.UnixNano(), now.UnixNano())
+
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query granted records: %w", err)
+	}
+	candidates, err := scanGranted(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// Paths are compared by segment, which the query cannot express
+	result := []InheritedGrant{}
+	for _, g := range candidates {
+		depth := -1
+		for _, v := range variants {
+			if (depth == -1 || v.depth < depth) && matches(v.Requested, g, rules.actions) {
+				depth = v.depth
+			}
+		}
+		if depth != -1 {
+			result = append(result, InheritedGrant{Granted: g, Depth: depth})
+		}
+	}
+
+	return result, nil
+}
+
+// inheritedGrants returns the grants without their inheritance depth
+func inheritedGrants(inherited []InheritedGrant) []Granted {
+	granted := make([]Granted, 0, len(inherited))
+	for _, i := range inherited {
+		granted = append(granted, i.Granted)
+	}
+
+	return granted
+}
+
+// ListRequested returns all stored requested permissions ordered by container name and target
+func (dm *DbManager) ListRequested() ([]Requested, error) {
+	rows, err := dm.db.Query(fmt.Sprintf(`SELECT %s FROM requested ORDER BY container_name, target, locator`, requestedColumns))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query requested records: %w", err)
+	}
+
+	return scanRequested(rows)
+}
+
+// ListGranted returns all stored granted permissions ordered by container name and target
+func (dm *DbManager) ListGranted() ([]Granted, error) {
+	rows, err := dm.db.Query(fmt.Sprintf(`SELECT %s FROM granted ORDER BY container_name, target, locator`, grantedColumns))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query granted records: %w", err)
+	}
+
+	return scanGranted(rows)
+}
+
+// PurgeExpired removes the grants that expired before the current time and
+// returns the number of removed grants
+func (dm *DbManager) PurgeExpired() (int, error) {
+	tx, err := dm.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := dm.now().UnixNano()
+	rows, err := tx.Query(fmt.Sprintf(`SELECT %s FROM granted WHERE expires_at <= ?`, grantedColumns), now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query expired granted records: %w", err)
+	}
+	expired, err := scanGranted(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM granted WHERE expires_at <= ?`, now); err != nil {
+		return 0, fmt.Errorf("failed to delete expired granted records: %w", err)
+	}
+	for _, g := range expired {
+		locator := grantedLocator(g)
+		if err := forgetRoleGrants(tx, locator); err != nil {
+			return 0, err
+		}
+		if err := dm.recordHistory(tx, "granted", grantedColumns, locator); err != nil {
+			return 0, err
+		}
+		before := g
+		if err := dm.auditGranted(tx, ChangeDeleted, locator, &before, nil); err != nil {
+			return 0, err
+		}
+	}
+	if err := dm.pruneHistory(tx, historyTables...); err != nil {
+		return 0, err
+	}
+
+	events, err := dm.grantedChanges(tx, ChangeDeleted, expired)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := dm.commit(tx, events); err != nil {
+		return 0, err
+	}
+
+	return len(expired), nil
+}
+
+// getRequested returns the stored requested record with the locator, or nil when there is none
+func getRequested(q querier, locator string) (*Requested, error) {
+	rows, err := q.Query(fmt.Sprintf(`SELECT %s FROM requested WHERE locator = ?`, requestedColumns), locator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query requested record: %w", err)
+	}
+	requested, err := scanRequested(rows)
+	if err != nil || len(requested) == 0 {
+		return nil, err
+	}
+
+	return &requested[0], nil
+}
+
+// getGranted returns the stored granted record with the locator, or nil when there is none
+func getGranted(q querier, locator string) (*Granted, error) {
+	rows, err := q.Query(fmt.Sprintf(`SELECT %s FROM granted WHERE locator = ?`, grantedColumns), locator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query granted record: %w", err)
+	}
+	granted, err := scanGranted(rows)
+	if err != nil || len(granted) == 0 {
+		return nil, err
+	}
+
+	return &granted[0], nil
+}
+
+// activeGranted returns the grants that are active at the given time
+func activeGranted(granted []Granted, now time.Time) []Granted {
+	active := make([]Granted, 0, len(granted))
+	for _, g := range granted {
+		if g.ActiveAt(now) {
+			active = append(active, g)
+		}
+	}
+
+	return active
+}
+
+// unixNano stores a time as nanoseconds since the epoch, the zero time is stored as NULL
+func unixNano(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t.UnixNano()
+}
+
+// fromUnixNano is the inverse of unixNano
+func fromUnixNano(value sql.NullInt64) time.Time {
+	if !value.Valid {
+		return time.Time{}
+	}
+
+	return time.Unix(0, value.Int64).UTC()
+}
+
+// requestedColumns are the columns read by scanRequested, in scan order
+const requestedColumns = `description, host, expose_path, source_organization,
+		source_repository, umbrella_organization, umbrella_repository,
+		container_name, target, request_scheme, request_action, request_source_organization,
+		request_source_repository, request_umbrella_organization, request_umbrella_repository,
+		request_container_name, request_target`
+
+// grantedColumns are the columns read by scanGranted, in scan order
+const grantedColumns = `description, host, expose_path, source_organization,
 		source_repository, umbrella_organization, umbrella_repository,
 		container_name, target, grand_scheme, grand_action, grand_source_organization,
 		grand_source_repository, grand_umbrella_organization, grand_umbrella_repository,
-		grand_container_name, grand_target FROM granted WHERE %s`,
-		strings.Join(conditions, " OR "))
+		grand_container_name, grand_target, not_before, expires_at`
 
-	rows, err := dm.db.Query(query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query granted records: %w", err)
+// scanRequested reads all rows selected with requestedColumns and closes them
+func scanRequested(rows *sql.Rows) ([]Requested, error) {
+	defer rows.Close()
+
+	var requested []Requested
+	for rows.Next() {
+		var r Requested
+		err := rows.Scan(
+			&r.Description,
+			&r.Host,
+			&r.DestinationPath,
+			&r.SourceOrganization,
+			&r.SourceRepository,
+			&r.UmbrellaOrganization,
+			&r.UmbrellaRepository,
+			&r.ContainerName,
+			&r.Target,
+			&r.RequestScheme,
+			&r.RequestAction,
+			&r.RequestSourceOrganization,
+			&r.RequestSourceRepository,
+			&r.RequestUmbrellaOrganization,
+			&r.RequestUmbrellaRepository,
+			&r.RequestContainerName,
+			&r.RequestTarget,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan requested record: %w", err)
+		}
+		requested = append(requested, r)
 	}
+
+	return requested, rows.Err()
+}
+
+// scanGranted reads all rows selected with grantedColumns and closes them
+func scanGranted(rows *sql.Rows) ([]Granted, error) {
 	defer rows.Close()
 
 	var granted []Granted
 	for rows.Next() {
 		var g Granted
+		var notBefore, expiresAt sql.NullInt64
 		err := rows.Scan(
 			&g.Description,
+			&g.Host,
 			&g.ExposePath,
 			&g.SourceOrganization,
 			&g.SourceRepository,
@@ -526,10 +1010,14 @@ func (dm *DbManager) FindGranted(requested []Requested) ([]Granted, error) {
 			&g.GrandUmbrellaRepository,
 			&g.GrandContainerName,
 			&g.GrandTarget,
+			&notBefore,
+			&expiresAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan granted record: %w", err)
 		}
+		g.NotBefore = fromUnixNano(notBefore)
+		g.ExpiresAt = fromUnixNano(expiresAt)
 		granted = append(granted, g)
 	}
 
@@ -539,10 +1027,10 @@ func (dm *DbManager) FindGranted(requested []Requested) ([]Granted, error) {
 // FillRequestedByLocator parses a locator string and fills a Requested struct with the extracted values
 func FillRequestedByLocator(locator string, requested Requested) (Requested, error) {
 
-	// Parse the URL
-	u, err := url.Parse(locator)
+	// Parse and normalize the URL
+	u, err := parseLocator(locator)
 	if err != nil {
-		return requested, fmt.Errorf("invalid locator format: %w", err)
+		return requested, err
 	}
 
 	// Extract host (without leading //)
@@ -599,10 +1087,10 @@ func FillRequestedByLocator(locator string, requested Requested) (Requested, err
 // FillGrantedByLocator parses a locator string and fills a Granted struct with the extracted values
 func FillGrantedByLocator(locator string, granted Granted) (Granted, error) {
 
-	// Parse the URL
-	u, err := url.Parse(locator)
+	// Parse and normalize the URL
+	u, err := parseLocator(locator)
 	if err != nil {
-		return granted, fmt.Errorf("invalid locator format: %w", err)
+		return granted, err
 	}
 
 	// Extract host (without leading //)
@@ -655,3 +1143,99 @@ func FillGrantedByLocator(locator string, granted Granted) (Granted, error) {
 
 	return granted, nil
 }
+
+// locatorOwnerParameters are the query parameters that hold organization and
+// repository names. These are compared case-insensitively, so they are lowercased.
+var locatorOwnerParameters = []string{
+	"umbrella_organization",
+	"umbrella_repository",
+	"source_organization",
+	"source_repository",
+}
+
+// parseLocator parses a locator string and brings it into canonical form, so
+// that equivalent locators result in the same host, container name and query
+func parseLocator(locator string) (*url.URL, error) {
+	u, err := url.Parse(locator)
+	if err != nil {
+		return nil, fmt.Errorf("invalid locator format: %w", err)
+	}
+	// A locator is //host/container?parameters, other URL parts would be lost
+	// by the normalization, so two different locators could be equal
+	switch {
+	case u.Scheme != "":
+		return nil, fmt.Errorf("invalid locator format: unexpected scheme %q", u.Scheme)
+	case u.User != nil:
+		return nil, fmt.Errorf("invalid locator format: unexpected user info")
+	case u.Fragment != "" || strings.HasSuffix(locator, "#"):
+		return nil, fmt.Errorf("invalid locator format: unexpected fragment")
+	}
+
+	// Hosts are case-insensitive
+	host := strings.ToLower(u.Host)
+
+	// u.Path is already percent-decoded, so only collapse duplicate slashes
+	// and drop the leading and trailing slash
+	containerName := normalizeContainerName(u.Path)
+
+	query := u.Query()
+	for _, key := range locatorOwnerParameters {
+		if values, ok := query[key]; ok {
+			for i, value := range values {
+				values[i] = strings.ToLower(value)
+			}
+		}
+	}
+
+	normalized := &url.URL{
+		Host:     host,
+		Path:     "/" + containerName,
+		RawQuery: query.Encode(),
+	}
+	if containerName == "" {
+		normalized.Path = ""
+	}
+
+	return normalized, nil
+}
+
+// normalizeContainerName collapses duplicate slashes and removes the leading
+// and trailing slash from a (decoded) container path
+func normalizeContainerName(path string) string {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// Normalize returns the canonical form of a locator. Percent-encoded segments
+// are decoded, duplicate and trailing slashes are removed, the host and the
+// organization and repository parameters are lowercased and the query
+// parameters are sorted.
+func Normalize(locator string) (string, error) {
+	u, err := parseLocator(locator)
+	if err != nil {
+		return "", err
+	}
+
+	return u.String(), nil
+}
+
+// Equal reports whether two locators refer to the same container. Locators
+// that cannot be parsed are never equal.
+func Equal(a, b string) bool {
+	normalizedA, err := Normalize(a)
+	if err != nil {
+		return false
+	}
+	normalizedB, err := Normalize(b)
+	if err != nil {
+		return false
+	}
+
+	return normalizedA == normalizedB
+}
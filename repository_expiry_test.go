@@ -0,0 +1,183 @@
+package syncer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// fakeClock is a clock for WithClock that only moves when told to
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func setupTestDBWithClock(t *testing.T) (*is.I, *DbManager, *fakeClock) {
+	is := is.New(t)
+	clock := &fakeClock{now: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}
+
+	dbManager, err := NewDbManager(WithClock(clock.Now))
+	if err != nil {
+		t.Fatalf("Failed to create DbManager: %v", err)
+	}
+	t.Cleanup(func() {
+		dbManager.Close()
+	})
+
+	return is, dbManager, clock
+}
+
+func TestRepositoryExpiry_FindGranted_respects_time_window(t *testing.T) {
+	// Given
+	is, dbManager, clock := setupTestDBWithClock(t)
+	mockGranted(dbManager, Granted{
+		GrandScheme: "image",
+		NotBefore:   clock.Now().Add(time.Hour),
+		ExpiresAt:   clock.Now().Add(3 * time.Hour),
+	})
+	requested := []Requested{{RequestScheme: "image"}}
+
+	// When / Then
+	result, err := dbManager.FindGranted(requested)
+	is.NoErr(err)
+	is.Equal(len(result), 0) // Not active yet
+
+	clock.Advance(time.Hour)
+	result, err = dbManager.FindGranted(requested)
+	is.NoErr(err)
+	is.Equal(len(result), 1) // NotBefore is inclusive
+	is.Equal(result[0].NotBefore, clock.Now())
+	is.Equal(result[0].ExpiresAt, clock.Now().Add(2*time.Hour))
+
+	clock.Advance(2 * time.Hour)
+	result, err = dbManager.FindGranted(requested)
+	is.NoErr(err)
+	is.Equal(len(result), 0) // ExpiresAt is exclusive
+}
+
+func TestRepositoryExpiry_FindGranted_without_limits_never_expires(t *testing.T) {
+	// Given
+	is, dbManager, clock := setupTestDBWithClock(t)
+	mockGranted(dbManager, Granted{GrandScheme: "image"})
+
+	// When
+	clock.Advance(100 * 365 * 24 * time.Hour)
+	result, err := dbManager.FindGranted([]Requested{{RequestScheme: "image"}})
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(result), 1)
+	is.True(result[0].NotBefore.IsZero())
+	is.True(result[0].ExpiresAt.IsZero())
+}
+
+func TestRepositoryExpiry_FindRequested_ignores_inactive_grants(t *testing.T) {
+	// Given
+	is, dbManager, clock := setupTestDBWithClock(t)
+	mockRequested(dbManager, Requested{RequestScheme: "image"})
+	mockRequested(dbManager, Requested{RequestScheme: "json"})
+	granted := []Granted{
+		{GrandScheme: "image", ExpiresAt: clock.Now()},
+		{GrandScheme: "json", NotBefore: clock.Now().Add(time.Minute)},
+	}
+
+	// When
+	result, err := dbManager.FindRequested(granted)
+	is.NoErr(err)
+	is.Equal(len(result), 0)
+
+	clock.Advance(time.Minute)
+	result, err = dbManager.FindRequested(granted)
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(result), 1)
+	is.Equal(result[0].RequestScheme, "json")
+}
+
+func TestRepositoryExpiry_SaveGranted_updates_time_window(t *testing.T) {
+	// Given
+	is, dbManager, clock := setupTestDBWithClock(t)
+	granted := Granted{GrandScheme: "image", ExpiresAt: clock.Now().Add(time.Hour)}
+	mockGranted(dbManager, granted)
+
+	// When
+	granted.ExpiresAt = clock.Now().Add(2 * time.Hour)
+	is.NoErr(dbManager.SaveGranted(granted))
+
+	// Then
+	result, err := dbManager.ListGranted()
+	is.NoErr(err)
+	is.Equal(len(result), 1) // The time window is not part of the locator
+	is.Equal(result[0].ExpiresAt, granted.ExpiresAt)
+}
+
+func TestRepositoryExpiry_PurgeExpired(t *testing.T) {
+	// Given
+	is, dbManager, clock := setupTestDBWithClock(t)
+	mockGranted(dbManager, Granted{GrandScheme: "expired", ExpiresAt: clock.Now().Add(-time.Minute)})
+	mockGranted(dbManager, Granted{GrandScheme: "expires-now", ExpiresAt: clock.Now()})
+	mockGranted(dbManager, Granted{GrandScheme: "active", ExpiresAt: clock.Now().Add(time.Minute)})
+	mockGranted(dbManager, Granted{GrandScheme: "unlimited"})
+	events, unsubscribe := dbManager.Subscribe(ChangeFilter{})
+	defer unsubscribe()
+
+	// When
+	count, err := dbManager.PurgeExpired()
+
+	// Then
+	is.NoErr(err)
+	is.Equal(count, 2)
+	result, err := dbManager.ListGranted()
+	is.NoErr(err)
+	is.Equal(len(result), 2)
+	schemes := map[string]bool{}
+	for _, g := range result {
+		schemes[g.GrandScheme] = true
+	}
+	is.True(schemes["active"])
+	is.True(schemes["unlimited"])
+	for i := 0; i < 2; i++ {
+		event := receiveEvent(t, events)
+		is.Equal(event.Operation, ChangeDeleted)
+	}
+}
+
+func TestRepositoryExpiry_ActiveAt(t *testing.T) {
+	is := is.New(t)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	is.True(Granted{}.ActiveAt(now))
+	is.True(Granted{NotBefore: now}.ActiveAt(now))
+	is.True(!Granted{NotBefore: now.Add(time.Second)}.ActiveAt(now))
+	is.True(!Granted{ExpiresAt: now}.ActiveAt(now))
+	is.True(Granted{ExpiresAt: now.Add(time.Second)}.ActiveAt(now))
+}
+
+func TestRepositoryExpiry_json_omits_unlimited_time_window(t *testing.T) {
+	// Given
+	is := is.New(t)
+	limited := Granted{ExpiresAt: time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	// When
+	unlimitedJSON, err := json.Marshal(Granted{})
+	is.NoErr(err)
+	limitedJSON, err := json.Marshal(limited)
+	is.NoErr(err)
+
+	// Then
+	is.True(!strings.Contains(string(unlimitedJSON), "not_before"))
+	is.True(!strings.Contains(string(unlimitedJSON), "expires_at"))
+	is.True(strings.Contains(string(limitedJSON), `"expires_at":"2100-01-01T00:00:00Z"`))
+	is.True(!strings.Contains(string(limitedJSON), "not_before"))
+}
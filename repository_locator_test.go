@@ -499,3 +499,128 @@ func TestRepositoryLocator_fill_granted_with_filled_granted(t *testing.T) {
 	is.Equal(result.GrandContainerName, "my-container")
 	is.Equal(result.GrandTarget, "all_up")
 }
+
+func TestRepositoryLocator_fill_requested_with_equivalent_locators(t *testing.T) {
+	tests := []struct {
+		name    string
+		locator string
+	}{
+		{name: "leading slash", locator: "//host/image/container?target=cmd"},
+		{name: "without leading slash", locator: "image/container?target=cmd"},
+		{name: "trailing slash", locator: "//host/image/container/?target=cmd"},
+		{name: "duplicate slashes", locator: "//host//image///container?target=cmd"},
+		{name: "percent-encoded segments", locator: "//host/%69mage%2Fcontainer?target=cmd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			result, err := FillRequestedByLocator(tt.locator, Requested{})
+
+			// Then
+			is := is.New(t)
+			is.NoErr(err)
+			is.Equal(result.ContainerName, "image/container")
+			is.Equal(result.RequestContainerName, "image/container")
+			is.Equal(result.Target, "cmd")
+		})
+	}
+}
+
+func TestRepositoryLocator_fill_granted_lowercases_organizations_and_repositories(t *testing.T) {
+	// Given
+	locator := "//Host/image/container/?umbrella_organization=Confetti-Sites&umbrella_repository=Confetti-CMS&source_organization=Different-Org&source_repository=Different-Repo&target=Cmd"
+
+	// When
+	result, err := FillGrantedByLocator(locator, Granted{})
+
+	// Then
+	is := is.New(t)
+	is.NoErr(err)
+	is.Equal(result.Host, "host")
+	is.Equal(result.ContainerName, "image/container")
+	is.Equal(result.UmbrellaOrganization, "confetti-sites")
+	is.Equal(result.UmbrellaRepository, "confetti-cms")
+	is.Equal(result.SourceOrganization, "different-org")
+	is.Equal(result.SourceRepository, "different-repo")
+	is.Equal(result.Target, "Cmd") // Targets keep their case
+}
+
+func TestRepositoryLocator_fill_with_invalid_locator(t *testing.T) {
+	is := is.New(t)
+
+	_, err := FillRequestedByLocator("//host/%zz", Requested{})
+	is.True(err != nil)
+
+	_, err = FillGrantedByLocator("//host/%zz", Granted{})
+	is.True(err != nil)
+}
+
+func TestRepositoryLocator_normalize(t *testing.T) {
+	tests := []struct {
+		locator  string
+		expected string
+	}{
+		{locator: "//host/image/container", expected: "//host/image/container"},
+		{locator: "//HOST/image/container/", expected: "//host/image/container"},
+		{locator: "/image/container", expected: "/image/container"},
+		{locator: "image/container/", expected: "/image/container"},
+		{locator: "//host//image//container//", expected: "//host/image/container"},
+		{locator: "//host/image%2Fcontainer", expected: "//host/image/container"},
+		{locator: "//host/image/my%20container", expected: "//host/image/my%20container"},
+		{locator: "//host/image/container?target=cmd&source_organization=Org", expected: "//host/image/container?source_organization=org&target=cmd"},
+		{locator: "//host/image/container?umbrella_repository=%43ms", expected: "//host/image/container?umbrella_repository=cms"},
+		{locator: "//host", expected: "//host"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locator, func(t *testing.T) {
+			// When
+			result, err := Normalize(tt.locator)
+
+			// Then
+			is := is.New(t)
+			is.NoErr(err)
+			is.Equal(result, tt.expected)
+		})
+	}
+}
+
+func TestRepositoryLocator_normalize_invalid_locator(t *testing.T) {
+	is := is.New(t)
+
+	_, err := Normalize("//host/%zz")
+
+	is.True(err != nil)
+}
+
+func TestRepositoryLocator_equal(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected bool
+	}{
+		{name: "identical", a: "//host/image/container", b: "//host/image/container", expected: true},
+		{name: "leading and trailing slash", a: "/image/container", b: "image/container/", expected: true},
+		{name: "percent-encoded", a: "//host/image/container", b: "//host/%69mage/container", expected: true},
+		{name: "organization case", a: "/image?source_organization=Org", b: "/image?source_organization=org", expected: true},
+		{name: "parameter order", a: "/image?target=cmd&source_organization=org", b: "/image?source_organization=org&target=cmd", expected: true},
+		{name: "different container", a: "/image/container", b: "/image/other", expected: false},
+		{name: "container case", a: "/image/Container", b: "/image/container", expected: false},
+		{name: "different target", a: "/image?target=cmd", b: "/image?target=web", expected: false},
+		{name: "invalid locator", a: "/image/%zz", b: "/image/%zz", expected: false},
+		{name: "scheme", a: "https://host/image", b: "ftp://host/image", expected: false},
+		{name: "same scheme", a: "https://host/image", b: "https://host/image", expected: false},
+		{name: "user info", a: "//user@host/image", b: "//host/image", expected: false},
+		{name: "fragment", a: "//host/image#a", b: "//host/image#b", expected: false},
+		{name: "empty fragment", a: "//host/image#", b: "//host/image", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(Equal(tt.a, tt.b), tt.expected)
+		})
+	}
+}
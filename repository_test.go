@@ -743,3 +743,88 @@ func mockRequested(db *DbManager, requested Requested) []Requested {
 	}
 	return []Requested{requested}
 }
+
+func TestRepository_ListGranted(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	mockGranted(dbManager, Granted{Host: "host-b", ContainerName: "b", GrandScheme: "image"})
+	mockGranted(dbManager, Granted{Host: "host-a", ContainerName: "a", GrandScheme: "json"})
+
+	// When
+	result, err := dbManager.ListGranted()
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(result), 2)
+	is.Equal(result[0].ContainerName, "a")
+	is.Equal(result[0].Host, "host-a") // Host is persisted
+	is.Equal(result[1].ContainerName, "b")
+}
+
+func TestRepository_ListRequested(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	mockRequested(dbManager, Requested{Host: "host-b", ContainerName: "b", RequestScheme: "image"})
+	mockRequested(dbManager, Requested{Host: "host-a", ContainerName: "a", RequestScheme: "json"})
+
+	// When
+	result, err := dbManager.ListRequested()
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(result), 2)
+	is.Equal(result[0].ContainerName, "a")
+	is.Equal(result[0].Host, "host-a") // Host is persisted
+	is.Equal(result[1].ContainerName, "b")
+}
+
+func TestRepository_List_empty(t *testing.T) {
+	is, dbManager := setupTestDB(t)
+
+	granted, err := dbManager.ListGranted()
+	is.NoErr(err)
+	is.Equal(len(granted), 0)
+
+	requested, err := dbManager.ListRequested()
+	is.NoErr(err)
+	is.Equal(len(requested), 0)
+}
+
+func TestRepository_DeleteGranted(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	mockGranted(dbManager, Granted{ContainerName: "a", GrandScheme: "image"})
+	mockGranted(dbManager, Granted{ContainerName: "b", GrandScheme: "image"})
+
+	// When
+	err := dbManager.DeleteGranted(Granted{ContainerName: "a", GrandScheme: "image"})
+
+	// Then
+	is.NoErr(err)
+	result, err := dbManager.ListGranted()
+	is.NoErr(err)
+	is.Equal(len(result), 1)
+	is.Equal(result[0].ContainerName, "b")
+}
+
+func TestRepository_DeleteRequested(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	mockRequested(dbManager, Requested{ContainerName: "a", RequestScheme: "image"})
+	mockRequested(dbManager, Requested{ContainerName: "b", RequestScheme: "image"})
+	mockRequested(dbManager, Requested{ContainerName: "c", RequestScheme: "image"})
+
+	// When
+	err := dbManager.DeleteRequested([]Requested{
+		{ContainerName: "a", RequestScheme: "image"},
+		{ContainerName: "c", RequestScheme: "image"},
+		{ContainerName: "missing", RequestScheme: "image"},
+	})
+
+	// Then
+	is.NoErr(err)
+	result, err := dbManager.ListRequested()
+	is.NoErr(err)
+	is.Equal(len(result), 1)
+	is.Equal(result[0].ContainerName, "b")
+}
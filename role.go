@@ -0,0 +1,429 @@
+package syncer
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrRoleNotFound is returned for a role that is not stored
+	ErrRoleNotFound = errors.New("role not found")
+	// ErrRoleInUse is returned when a role that is assigned is deleted
+	ErrRoleInUse = errors.New("role is assigned")
+	// ErrAlreadyAssigned is returned when a role is assigned twice to the same subject
+	ErrAlreadyAssigned = errors.New("role is already assigned to the subject")
+	// ErrAssignmentNotFound is returned when a role that is not assigned to a subject is revoked
+	ErrAssignmentNotFound = errors.New("role is not assigned to the subject")
+)
+
+// placeholder is a value of the subject in a template field, e.g. {container}
+var placeholder = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
+// Role is a named bundle of grant templates. Every text field of a template
+// may contain placeholders such as {container} that are replaced by the
+// values of the subject the role is assigned to.
+type Role struct {
+	Name   string    `json:"name"`
+	Grants []Granted `json:"grants"`
+}
+
+// Subject holds the values of the placeholders of a role
+type Subject map[string]string
+
+// Assignment is a role assigned to a subject with the grants it expands to
+type Assignment struct {
+	Role    string    `json:"role"`
+	Subject Subject   `json:"subject"`
+	Granted []Granted `json:"granted"`
+}
+
+// SaveRole stores a role, or replaces the templates of the role with the same
+// name. Grants of existing assignments are not changed.
+func (dm *DbManager) SaveRole(role Role) error {
+	if role.Name == "" {
+		return errors.New("failed to save role: name is required")
+	}
+	for i := range role.Grants {
+		for _, value := range templateFields(&role.Grants[i]) {
+			if rest := placeholder.ReplaceAllString(*value, ""); strings.ContainsAny(rest, "{}") {
+				return fmt.Errorf("failed to save role %s: grants[%d]: invalid placeholder in %q", role.Name, i, *value)
+			}
+		}
+	}
+
+	grants, err := json.Marshal(role.Grants)
+	if err != nil {
+		return fmt.Errorf("failed to encode role: %w", err)
+	}
+	_, err = dm.db.Exec(`INSERT INTO roles (name, grants) VALUES (?, ?)
+		ON CONFLICT(name) DO UPDATE SET grants=excluded.grants`, role.Name, string(grants))
+	if err != nil {
+		return fmt.Errorf("failed to save role: %w", err)
+	}
+
+	return nil
+}
+
+// ListRoles returns all stored roles ordered by name
+func (dm *DbManager) ListRoles() ([]Role, error) {
+	rows, err := dm.db.Query(`SELECT name, grants FROM roles ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query roles: %w", err)
+	}
+	defer rows.Close()
+
+	roles := []Role{}
+	for rows.Next() {
+		var name, grants string
+		if err := rows.Scan(&name, &grants); err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		role := Role{Name: name}
+		if err := json.Unmarshal([]byte(grants), &role.Grants); err != nil {
+			return nil, fmt.Errorf("failed to decode role %s: %w", name, err)
+		}
+		roles = append(roles, role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// DeleteRole removes a role that is not assigned to any subject
+func (dm *DbManager) DeleteRole(name string) error {
+	tx, err := dm.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var assignments int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM role_assignments WHERE role = ?`, name).Scan(&assignments); err != nil {
+		return fmt.Errorf("failed to query role assignments: %w", err)
+	}
+	if assignments > 0 {
+		return fmt.Errorf("failed to delete role %s: %w", name, ErrRoleInUse)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM roles WHERE name = ?`, name); err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// AssignRole expands the templates of a role with the values of the subject
+// and saves the grants. Grants that already exist are left as they are and
+// are not removed when the role is revoked.
+func (dm *DbManager) AssignRole(role string, subject Subject) (Assignment, error) {
+	tx, err := dm.db.Begin()
+	if err != nil {
+		return Assignment{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	templates, err := getRole(tx, role)
+	if err != nil {
+		return Assignment{}, err
+	}
+	key, err := subjectKey(subject)
+	if err != nil {
+		return Assignment{}, err
+	}
+	if _, err := getAssignment(tx, role, key); !errors.Is(err, ErrAssignmentNotFound) {
+		if err == nil {
+			err = fmt.Errorf("failed to assign role %s: %w", role, ErrAlreadyAssigned)
+		}
+		return Assignment{}, err
+	}
+
+	assignment := Assignment{Role: role, Subject: subject, Granted: []Granted{}}
+	seen := map[string]bool{}
+	for i, template := range templates {
+		g, err := expandTemplate(template, subject)
+		if err != nil {
+			return Assignment{}, fmt.Errorf("failed to assign role %s: grants[%d]: %w", role, i, err)
+		}
+		if err := ValidateGranted(g); err != nil {
+			return Assignment{}, fmt.Errorf("failed to assign role %s: grants[%d]: %w", role, i, err)
+		}
+		if locator := grantedLocator(g); !seen[locator] {
+			seen[locator] = true
+			assignment.Granted = append(assignment.Granted, g)
+		}
+	}
+
+	result, err := tx.Exec(`INSERT INTO role_assignments (role, subject) VALUES (?, ?)`, role, key)
+	if err != nil {
+		return Assignment{}, fmt.Errorf("failed to save role assignment: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return Assignment{}, fmt.Errorf("failed to save role assignment: %w", err)
+	}
+
+	var created []Granted
+	for _, g := range assignment.Granted {
+		locator := grantedLocator(g)
+		existing, err := getGranted(tx, locator)
+		if err != nil {
+			return Assignment{}, err
+		}
+		if existing == nil {
+			if err := dm.saveGranted(tx, g); err != nil {
+				return Assignment{}, err
+			}
+			created = append(created, g)
+		}
+		_, err = tx.Exec(`INSERT INTO role_grants (assignment_id, locator, created) VALUES (?, ?, ?)`, id, locator, existing == nil)
+		if err != nil {
+			return Assignment{}, fmt.Errorf("failed to save role grant: %w", err)
+		}
+	}
+
+	events, err := dm.grantedChanges(tx, ChangeSaved, created)
+	if err != nil {
+		return Assignment{}, err
+	}
+
+	if err := dm.commit(tx, events); err != nil {
+		return Assignment{}, err
+	}
+
+	return assignment, nil
+}
+
+// RevokeRole removes the assignment of a role to a subject and exactly the
+// grants the assignment created and still owns. A created grant that another
+// assignment also holds stays until that assignment is revoked. A grant that
+// was deleted is no longer owned, also when it is saved again later.
+func (dm *DbManager) RevokeRole(role string, subject Subject) error {
+	tx, err := dm.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	key, err := subjectKey(subject)
+	if err != nil {
+		return err
+	}
+	id, err := getAssignment(tx, role, key)
+	if err != nil {
+		return fmt.Errorf("failed to revoke role %s: %w", role, err)
+	}
+
+	rows, err := tx.Query(`SELECT locator FROM role_grants
+		WHERE assignment_id = ? AND created AND locator IN (SELECT locator FROM granted)`, id)
+	if err != nil {
+		return fmt.Errorf("failed to query role grants: %w", err)
+	}
+	var locators []string
+	for rows.Next() {
+		var locator string
+		if err := rows.Scan(&locator); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan role grant: %w", err)
+		}
+		locators = append(locators, locator)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read role grants: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM role_grants WHERE assignment_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete role grants: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM role_assignments WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete role assignment: %w", err)
+	}
+
+	var deleted []Granted
+	for _, locator := range locators {
+		// Hand the grant over to another assignment that holds it
+		result, err := tx.Exec(`UPDATE role_grants SET created = 1
+			WHERE rowid = (SELECT MIN(rowid) FROM role_grants WHERE locator = ?)`, locator)
+		if err != nil {
+			return fmt.Errorf("failed to update role grants: %w", err)
+		}
+		held, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to update role grants: %w", err)
+		}
+		if held > 0 {
+			continue
+		}
+
+		before, err := dm.deleteGranted(tx, locator)
+		if err != nil {
+			return err
+		}
+		if before != nil {
+			deleted = append(deleted, *before)
+		}
+	}
+
+	events, err := dm.grantedChanges(tx, ChangeDeleted, deleted)
+	if err != nil {
+		return err
+	}
+
+	if err := dm.commit(tx, events); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListAssignments returns all role assignments with the stored grants they hold
+func (dm *DbManager) ListAssignments() ([]Assignment, error) {
+	rows, err := dm.db.Query(`SELECT id, role, subject FROM role_assignments ORDER BY role, subject`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query role assignments: %w", err)
+	}
+	var ids []int64
+	assignments := []Assignment{}
+	for rows.Next() {
+		var id int64
+		var role, subject string
+		if err := rows.Scan(&id, &role, &subject); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan role assignment: %w", err)
+		}
+		assignment := Assignment{Role: role}
+		if err := json.Unmarshal([]byte(subject), &assignment.Subject); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to decode role subject: %w", err)
+		}
+		ids = append(ids, id)
+		assignments = append(assignments, assignment)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read role assignments: %w", err)
+	}
+
+	for i, id := range ids {
+		rows, err := dm.db.Query(fmt.Sprintf(`SELECT %s FROM granted
+			WHERE locator IN (SELECT locator FROM role_grants WHERE assignment_id = ?)
+			ORDER BY container_name, target, locator`, grantedColumns), id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query granted records: %w", err)
+		}
+		if assignments[i].Granted, err = scanGranted(rows); err != nil {
+			return nil, err
+		}
+	}
+
+	return assignments, nil
+}
+
+// forgetRoleGrants removes a deleted grant from the assignments that hold it,
+// so a grant saved again with the same values is not owned by a role
+func forgetRoleGrants(q querier, locator string) error {
+	if _, err := q.Exec(`DELETE FROM role_grants WHERE locator = ?`, locator); err != nil {
+		return fmt.Errorf("failed to delete role grants: %w", err)
+	}
+
+	return nil
+}
+
+func getRole(q querier, name string) ([]Granted, error) {
+	var grants string
+	err := q.QueryRow(`SELECT grants FROM roles WHERE name = ?`, name).Scan(&grants)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get role %s: %w", name, ErrRoleNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query role: %w", err)
+	}
+
+	var templates []Granted
+	if err := json.Unmarshal([]byte(grants), &templates); err != nil {
+		return nil, fmt.Errorf("failed to decode role %s: %w", name, err)
+	}
+
+	return templates, nil
+}
+
+// getAssignment returns the ID of the assignment of a role to a subject
+func getAssignment(q querier, role, key string) (int64, error) {
+	var id int64
+	err := q.QueryRow(`SELECT id FROM role_assignments WHERE role = ? AND subject = ?`, role, key).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrAssignmentNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query role assignment: %w", err)
+	}
+
+	return id, nil
+}
+
+// subjectKey is the stored form of a subject, the keys of a JSON object are sorted
+func subjectKey(subject Subject) (string, error) {
+	if subject == nil {
+		subject = Subject{}
+	}
+	data, err := json.Marshal(subject)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode role subject: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// expandTemplate replaces the placeholders in the text fields of a grant template
+func expandTemplate(template Granted, subject Subject) (Granted, error) {
+	g := template
+	var missing []string
+	for _, value := range templateFields(&g) {
+		*value = placeholder.ReplaceAllStringFunc(*value, func(match string) string {
+			name := match[1 : len(match)-1]
+			replacement, ok := subject[name]
+			if !ok {
+				missing = append(missing, name)
+			}
+			return replacement
+		})
+	}
+	if len(missing) > 0 {
+		return Granted{}, fmt.Errorf("subject has no value for %s", strings.Join(missing, ", "))
+	}
+
+	return g, nil
+}
+
+// templateFields returns the text fields of a grant that may contain placeholders
+func templateFields(g *Granted) []*string {
+	return []*string{
+		&g.Description,
+		&g.Host,
+		&g.ExposePath,
+		&g.SourceOrganization,
+		&g.SourceRepository,
+		&g.UmbrellaOrganization,
+		&g.UmbrellaRepository,
+		&g.ContainerName,
+		&g.Target,
+		&g.GrandScheme,
+		&g.GrandAction,
+		&g.GrandSourceOrganization,
+		&g.GrandSourceRepository,
+		&g.GrandUmbrellaOrganization,
+		&g.GrandUmbrellaRepository,
+		&g.GrandContainerName,
+		&g.GrandTarget,
+	}
+}
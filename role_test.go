@@ -0,0 +1,161 @@
+package syncer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var imagePullRole = Role{
+	Name: "image-pull",
+	Grants: []Granted{
+		{ContainerName: "{container}", Target: "cmd", UmbrellaOrganization: "{umbrella}", GrandScheme: "image", GrandAction: "pull", Description: "image pull for {container}"},
+		{ContainerName: "{container}", Target: "web", UmbrellaOrganization: "{umbrella}", GrandScheme: "image", GrandAction: "pull"},
+	},
+}
+
+func TestSaveRole(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+
+	// When
+	err := dbManager.SaveRole(imagePullRole)
+	invalidErr := dbManager.SaveRole(Role{Name: "invalid", Grants: []Granted{{ContainerName: "{container"}}})
+	unnamedErr := dbManager.SaveRole(Role{})
+
+	// Then
+	is.NoErr(err)
+	is.True(invalidErr != nil)
+	is.True(unnamedErr != nil)
+	roles, err := dbManager.ListRoles()
+	is.NoErr(err)
+	is.Equal(roles, []Role{imagePullRole})
+}
+
+func TestAssignRole(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	is.NoErr(dbManager.SaveRole(imagePullRole))
+	subject := Subject{"container": "cms/uploads", "umbrella": "confetti-sites"}
+
+	// When
+	assignment, err := dbManager.AssignRole("image-pull", subject)
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(assignment.Granted), 2)
+	is.Equal(assignment.Granted[0].ContainerName, "cms/uploads")
+	is.Equal(assignment.Granted[0].UmbrellaOrganization, "confetti-sites")
+	is.Equal(assignment.Granted[0].Description, "image pull for cms/uploads")
+	granted, err := dbManager.ListGranted()
+	is.NoErr(err)
+	is.Equal(len(granted), 2)
+	assignments, err := dbManager.ListAssignments()
+	is.NoErr(err)
+	is.Equal(len(assignments), 1)
+	is.Equal(assignments[0].Subject, subject)
+	is.Equal(len(assignments[0].Granted), 2)
+}
+
+func TestAssignRole_errors(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	is.NoErr(dbManager.SaveRole(imagePullRole))
+	_, err := dbManager.AssignRole("image-pull", Subject{"container": "cms/uploads", "umbrella": "confetti-sites"})
+	is.NoErr(err)
+
+	// When
+	_, twiceErr := dbManager.AssignRole("image-pull", Subject{"umbrella": "confetti-sites", "container": "cms/uploads"})
+	_, unknownErr := dbManager.AssignRole("hive-write", Subject{})
+	_, missingErr := dbManager.AssignRole("image-pull", Subject{"container": "cms/images"})
+	_, invalidErr := dbManager.AssignRole("image-pull", Subject{"container": "/cms/images/", "umbrella": "confetti-sites"})
+	deleteErr := dbManager.DeleteRole("image-pull")
+	revokeErr := dbManager.RevokeRole("image-pull", Subject{"container": "cms/images"})
+
+	// Then
+	is.True(errors.Is(twiceErr, ErrAlreadyAssigned))
+	is.True(errors.Is(unknownErr, ErrRoleNotFound))
+	is.True(missingErr != nil)
+	is.True(invalidErr != nil) // The container name is not normalized
+	is.True(errors.Is(deleteErr, ErrRoleInUse))
+	is.True(errors.Is(revokeErr, ErrAssignmentNotFound))
+	granted, err := dbManager.ListGranted()
+	is.NoErr(err)
+	is.Equal(len(granted), 2) // Only the grants of the first assignment
+}
+
+func TestRevokeRole(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	is.NoErr(dbManager.SaveRole(imagePullRole))
+	subject := Subject{"container": "cms/uploads", "umbrella": "confetti-sites"}
+	existing := Granted{ContainerName: "cms/uploads", Target: "web", UmbrellaOrganization: "confetti-sites", GrandScheme: "image", GrandAction: "pull"}
+	mockGranted(dbManager, existing)
+	_, err := dbManager.AssignRole("image-pull", subject)
+	is.NoErr(err)
+
+	// When
+	err = dbManager.RevokeRole("image-pull", subject)
+
+	// Then
+	is.NoErr(err)
+	granted, err := dbManager.ListGranted()
+	is.NoErr(err)
+	is.Equal(granted, []Granted{existing}) // The grant that existed before the assignment stays
+	assignments, err := dbManager.ListAssignments()
+	is.NoErr(err)
+	is.Equal(len(assignments), 0)
+	is.NoErr(dbManager.DeleteRole("image-pull"))
+}
+
+func TestRevokeRole_shared_grant(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	is.NoErr(dbManager.SaveRole(Role{Name: "cms", Grants: []Granted{{ContainerName: "cms/uploads", GrandScheme: "image", GrandAction: "{action}"}}}))
+	is.NoErr(dbManager.SaveRole(Role{Name: "uploads", Grants: []Granted{{ContainerName: "cms/uploads", GrandScheme: "image", GrandAction: "pull"}}}))
+	_, err := dbManager.AssignRole("cms", Subject{"action": "pull"})
+	is.NoErr(err)
+	_, err = dbManager.AssignRole("uploads", nil)
+	is.NoErr(err)
+
+	// When
+	is.NoErr(dbManager.RevokeRole("cms", Subject{"action": "pull"}))
+	afterFirst, err := dbManager.ListGranted()
+	is.NoErr(err)
+	is.NoErr(dbManager.RevokeRole("uploads", Subject{}))
+	afterSecond, err := dbManager.ListGranted()
+	is.NoErr(err)
+
+	// Then
+	is.Equal(len(afterFirst), 1) // The other assignment still holds the grant
+	is.Equal(len(afterSecond), 0)
+}
+
+func TestRevokeRole_keeps_grants_saved_again_by_hand(t *testing.T) {
+	// Given
+	is, dbManager, clock := setupTestDBWithClock(t)
+	is.NoErr(dbManager.SaveRole(imagePullRole))
+	subject := Subject{"container": "cms/uploads", "umbrella": "confetti-sites"}
+	assignment, err := dbManager.AssignRole("image-pull", subject)
+	is.NoErr(err)
+	recreated, expiring := assignment.Granted[0], assignment.Granted[1]
+	is.NoErr(dbManager.DeleteGranted(recreated))
+	mockGranted(dbManager, recreated)
+	expiring.ExpiresAt = clock.Now().Add(time.Hour)
+	mockGranted(dbManager, expiring)
+	clock.Advance(2 * time.Hour)
+	purged, err := dbManager.PurgeExpired()
+	is.NoErr(err)
+	is.Equal(purged, 1)
+	expiring.ExpiresAt = time.Time{}
+	mockGranted(dbManager, expiring)
+
+	// When
+	err = dbManager.RevokeRole("image-pull", subject)
+
+	// Then
+	is.NoErr(err)
+	granted, err := dbManager.ListGranted()
+	is.NoErr(err)
+	is.Equal(len(granted), 2) // Both grants were deleted and saved again outside the role
+}
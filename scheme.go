@@ -0,0 +1,250 @@
+package syncer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Scheme declares the actions and fields that the requested and granted
+// permissions of a scheme need. Fields are named by their Go field name,
+// e.g. Target or DestinationPath.
+type Scheme struct {
+	Name string `json:"name"`
+	// Actions are the allowed actions, any action is allowed when there are
+	// none. The wildcard is always allowed.
+	Actions []string `json:"actions,omitempty"`
+	// RequiredRequested and RequiredGranted are the fields that requested and
+	// granted permissions of the scheme cannot leave empty
+	RequiredRequested []string `json:"required_requested,omitempty"`
+	RequiredGranted   []string `json:"required_granted,omitempty"`
+	// Formats are the patterns that the values of fields have to match, in
+	// requested and granted permissions. Empty values and wildcards are not
+	// checked.
+	Formats map[string]*regexp.Regexp `json:"formats,omitempty"`
+}
+
+// FieldError is a field of a permission that its scheme does not accept
+type FieldError struct {
+	Field   string `json:"field"`
+	Value   string `json:"value"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError is returned when a requested or granted permission is
+// saved that does not meet its registered scheme
+type ValidationError struct {
+	// Record is requested or granted
+	Record string       `json:"record"`
+	Scheme string       `json:"scheme"`
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		fields = append(fields, f.Error())
+	}
+
+	return fmt.Sprintf("invalid %s permission of scheme %s: %s", e.Record, e.Scheme, strings.Join(fields, "; "))
+}
+
+// DefaultSchemes returns the schemes of Confetti: images are pulled for a
+// target and hive data is synced to a path. Register them with RegisterScheme.
+func DefaultSchemes() []Scheme {
+	return []Scheme{
+		{Name: "image", RequiredRequested: []string{"Target"}, RequiredGranted: []string{"Target"}},
+		{Name: "hive", RequiredRequested: []string{"DestinationPath"}, RequiredGranted: []string{"ExposePath"}},
+		{Name: "json"},
+	}
+}
+
+// RegisterScheme validates the requested and granted permissions of the
+// scheme on every later save, it replaces a scheme with the same name.
+// Permissions of schemes that are not registered are saved as they are.
+func (dm *DbManager) RegisterScheme(scheme Scheme) error {
+	if scheme.Name == "" || scheme.Name == "*" {
+		return fmt.Errorf("failed to register scheme: a name other than the wildcard is required")
+	}
+	requested, granted := requestedFields(Requested{}), grantedFields(Granted{})
+	for _, field := range scheme.RequiredRequested {
+		if _, ok := requested[field]; !ok {
+			return fmt.Errorf("failed to register scheme %s: requested permissions have no field %s", scheme.Name, field)
+		}
+	}
+	for _, field := range scheme.RequiredGranted {
+		if _, ok := granted[field]; !ok {
+			return fmt.Errorf("failed to register scheme %s: granted permissions have no field %s", scheme.Name, field)
+		}
+	}
+	for field, format := range scheme.Formats {
+		_, inRequested := requested[field]
+		_, inGranted := granted[field]
+		if !inRequested && !inGranted {
+			return fmt.Errorf("failed to register scheme %s: permissions have no field %s", scheme.Name, field)
+		}
+		if format == nil {
+			return fmt.Errorf("failed to register scheme %s: format of %s is missing", scheme.Name, field)
+		}
+	}
+
+	dm.schemes.mu.Lock()
+	defer dm.schemes.mu.Unlock()
+	dm.schemes.byName[scheme.Name] = scheme
+
+	return nil
+}
+
+// UnregisterScheme stops validating the permissions of a scheme
+func (dm *DbManager) UnregisterScheme(name string) {
+	dm.schemes.mu.Lock()
+	defer dm.schemes.mu.Unlock()
+	delete(dm.schemes.byName, name)
+}
+
+// Schemes returns the registered schemes ordered by name
+func (dm *DbManager) Schemes() []Scheme {
+	dm.schemes.mu.RLock()
+	defer dm.schemes.mu.RUnlock()
+
+	schemes := make([]Scheme, 0, len(dm.schemes.byName))
+	for _, scheme := range dm.schemes.byName {
+		schemes = append(schemes, scheme)
+	}
+	sort.Slice(schemes, func(i, j int) bool {
+		return schemes[i].Name < schemes[j].Name
+	})
+
+	return schemes
+}
+
+// schemeRegistry holds the registered schemes, they can change while the DbManager is in use
+type schemeRegistry struct {
+	mu     sync.RWMutex
+	byName map[string]Scheme
+}
+
+func newSchemeRegistry() *schemeRegistry {
+	return &schemeRegistry{byName: map[string]Scheme{}}
+}
+
+func (r *schemeRegistry) get(name string) (Scheme, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	scheme, ok := r.byName[name]
+
+	return scheme, ok
+}
+
+// validateRequested checks a requested permission against its registered scheme
+func (r *schemeRegistry) validateRequested(req Requested) error {
+	scheme, ok := r.get(req.RequestScheme)
+	if !ok {
+		return nil
+	}
+
+	return scheme.validate("requested", "RequestAction", requestedFields(req), scheme.RequiredRequested)
+}
+
+// validateGranted checks a granted permission against its registered scheme
+func (r *schemeRegistry) validateGranted(g Granted) error {
+	scheme, ok := r.get(g.GrandScheme)
+	if !ok {
+		return nil
+	}
+
+	return scheme.validate("granted", "GrandAction", grantedFields(g), scheme.RequiredGranted)
+}
+
+func (s Scheme) validate(record, actionField string, fields map[string]string, required []string) error {
+	var errs []FieldError
+	if action := fields[actionField]; len(s.Actions) > 0 && action != "*" && !contains(s.Actions, action) {
+		errs = append(errs, FieldError{Field: actionField, Value: action, Message: "must be one of " + strings.Join(s.Actions, ", ")})
+	}
+	for _, field := range required {
+		if fields[field] == "" {
+			errs = append(errs, FieldError{Field: field, Message: "is required"})
+		}
+	}
+
+	names := make([]string, 0, len(s.Formats))
+	for field := range s.Formats {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+	for _, field := range names {
+		value, ok := fields[field]
+		if !ok || value == "" || value == "*" || s.Formats[field].MatchString(value) {
+			continue
+		}
+		errs = append(errs, FieldError{Field: field, Value: value, Message: "does not match " + s.Formats[field].String()})
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Record: record, Scheme: s.Name, Fields: errs}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// requestedFields returns the text fields of a requested permission by Go field name
+func requestedFields(r Requested) map[string]string {
+	return map[string]string{
+		"Description":                 r.Description,
+		"Host":                        r.Host,
+		"DestinationPath":             r.DestinationPath,
+		"SourceOrganization":          r.SourceOrganization,
+		"SourceRepository":            r.SourceRepository,
+		"UmbrellaOrganization":        r.UmbrellaOrganization,
+		"UmbrellaRepository":          r.UmbrellaRepository,
+		"ContainerName":               r.ContainerName,
+		"Target":                      r.Target,
+		"RequestScheme":               r.RequestScheme,
+		"RequestAction":               r.RequestAction,
+		"RequestSourceOrganization":   r.RequestSourceOrganization,
+		"RequestSourceRepository":     r.RequestSourceRepository,
+		"RequestUmbrellaOrganization": r.RequestUmbrellaOrganization,
+		"RequestUmbrellaRepository":   r.RequestUmbrellaRepository,
+		"RequestContainerName":        r.RequestContainerName,
+		"RequestTarget":               r.RequestTarget,
+	}
+}
+
+// grantedFields returns the text fields of a granted permission by Go field name
+func grantedFields(g Granted) map[string]string {
+	return map[string]string{
+		"Description":               g.Description,
+		"Host":                      g.Host,
+		"ExposePath":                g.ExposePath,
+		"SourceOrganization":        g.SourceOrganization,
+		"SourceRepository":          g.SourceRepository,
+		"UmbrellaOrganization":      g.UmbrellaOrganization,
+		"UmbrellaRepository":        g.UmbrellaRepository,
+		"ContainerName":             g.ContainerName,
+		"Target":                    g.Target,
+		"GrandScheme":               g.GrandScheme,
+		"GrandAction":               g.GrandAction,
+		"GrandSourceOrganization":   g.GrandSourceOrganization,
+		"GrandSourceRepository":     g.GrandSourceRepository,
+		"GrandUmbrellaOrganization": g.GrandUmbrellaOrganization,
+		"GrandUmbrellaRepository":   g.GrandUmbrellaRepository,
+		"GrandContainerName":        g.GrandContainerName,
+		"GrandTarget":               g.GrandTarget,
+	}
+}
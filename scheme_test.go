@@ -0,0 +1,132 @@
+package syncer
+
+import (
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+func TestRegisterScheme_invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		scheme Scheme
+	}{
+		{name: "without name", scheme: Scheme{}},
+		{name: "wildcard name", scheme: Scheme{Name: "*"}},
+		{name: "unknown requested field", scheme: Scheme{Name: "hive", RequiredRequested: []string{"ExposePath"}}},
+		{name: "unknown granted field", scheme: Scheme{Name: "hive", RequiredGranted: []string{"DestinationPath"}}},
+		{name: "unknown format field", scheme: Scheme{Name: "hive", Formats: map[string]*regexp.Regexp{"Path": regexp.MustCompile(`^/`)}}},
+		{name: "missing format", scheme: Scheme{Name: "hive", Formats: map[string]*regexp.Regexp{"Target": nil}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			is, dbManager := setupTestDB(t)
+
+			// When
+			err := dbManager.RegisterScheme(tt.scheme)
+
+			// Then
+			is.True(err != nil)
+			is.Equal(len(dbManager.Schemes()), 0)
+		})
+	}
+}
+
+func TestSaveRequested_validated_by_scheme(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	for _, scheme := range DefaultSchemes() {
+		is.NoErr(dbManager.RegisterScheme(scheme))
+	}
+	valid := Requested{ContainerName: "hive/container", DestinationPath: "/data", RequestScheme: "hive", RequestAction: "sync"}
+	invalid := Requested{ContainerName: "hive/container", RequestScheme: "hive", RequestAction: "sync"}
+
+	// When
+	err := dbManager.SaveRequested([]Requested{valid, invalid})
+
+	// Then
+	var validationErr *ValidationError
+	is.True(errors.As(err, &validationErr))
+	is.Equal(validationErr, &ValidationError{Record: "requested", Scheme: "hive", Fields: []FieldError{{Field: "DestinationPath", Message: "is required"}}})
+	is.Equal(err.Error(), "invalid requested permission of scheme hive: DestinationPath: is required")
+	requested, err := dbManager.ListRequested()
+	is.NoErr(err)
+	is.Equal(len(requested), 0) // Nothing of the batch is saved
+}
+
+func TestSaveGranted_validated_by_scheme(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	is.NoErr(dbManager.RegisterScheme(Scheme{
+		Name:            "image",
+		Actions:         []string{"pull", "push"},
+		RequiredGranted: []string{"Target"},
+		Formats:         map[string]*regexp.Regexp{"Target": regexp.MustCompile(`^[a-z]+$`)},
+	}))
+
+	// When
+	invalidErr := dbManager.SaveGranted(Granted{ContainerName: "image/container", Target: "Cmd1", GrandScheme: "image", GrandAction: "delete"})
+	missingErr := dbManager.SaveGranted(Granted{ContainerName: "image/container", GrandScheme: "image", GrandAction: "pull"})
+	wildcardErr := dbManager.SaveGranted(Granted{ContainerName: "image/container", Target: "*", GrandScheme: "image", GrandAction: "*"})
+	otherErr := dbManager.SaveGranted(Granted{ContainerName: "image/container", GrandScheme: "json", GrandAction: "delete"})
+
+	// Then
+	var validationErr *ValidationError
+	is.True(errors.As(invalidErr, &validationErr))
+	is.Equal(validationErr.Fields, []FieldError{
+		{Field: "GrandAction", Value: "delete", Message: "must be one of pull, push"},
+		{Field: "Target", Value: "Cmd1", Message: "does not match ^[a-z]+$"},
+	})
+	is.True(errors.As(missingErr, &validationErr))
+	is.Equal(validationErr.Fields, []FieldError{{Field: "Target", Message: "is required"}})
+	is.NoErr(wildcardErr) // Wildcards are not checked against actions and formats
+	is.NoErr(otherErr)    // Schemes that are not registered are not validated
+}
+
+func TestSaveGrantedBatch_saves_all_or_nothing(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	is.NoErr(dbManager.RegisterScheme(Scheme{Name: "hive", RequiredGranted: []string{"ExposePath"}}))
+	valid := Granted{ContainerName: "hive/container", GrandScheme: "hive", GrandAction: "sync", ExposePath: "/public"}
+	invalid := Granted{ContainerName: "hive/other", GrandScheme: "hive", GrandAction: "sync"}
+
+	// When
+	invalidErr := dbManager.SaveGrantedBatch([]Granted{valid, invalid})
+	afterInvalid, err := dbManager.ListGranted()
+	is.NoErr(err)
+	validErr := dbManager.SaveGrantedBatch([]Granted{valid})
+
+	// Then
+	var validation *ValidationError
+	is.True(errors.As(invalidErr, &validation))
+	is.Equal(len(afterInvalid), 0) // The valid grant is not saved either
+	is.NoErr(validErr)
+}
+
+func TestRegisterScheme_at_runtime(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	granted := Granted{ContainerName: "image/container", GrandScheme: "image", GrandAction: "pull"}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dbManager.RegisterScheme(Scheme{Name: "image", RequiredGranted: []string{"Target"}})
+		}()
+	}
+	wg.Wait()
+
+	// When
+	registeredErr := dbManager.SaveGranted(granted)
+	dbManager.UnregisterScheme("image")
+	unregisteredErr := dbManager.SaveGranted(granted)
+
+	// Then
+	is.True(registeredErr != nil)
+	is.NoErr(unregisteredErr)
+	is.Equal(len(dbManager.Schemes()), 0)
+}
@@ -0,0 +1,151 @@
+package syncer
+
+// Subsumes reports whether grant a satisfies every requested permission that
+// grant b satisfies, at every moment b is active. The matching dimensions
+// need an equal resource and a value of a that is a wildcard or equal to the
+// value of b, and the expose path of a has to cover the one of b. Like
+// Matches it does not apply stored rules, see DbManager.RedundantGrants.
+// Deny grants are not considered: grants only allow, so none can shadow another.
+func Subsumes(a, b Granted) bool {
+	return subsumes(a, b, matchRules{})
+}
+
+// subsumes is Subsumes with the owner parents and implied actions of rules:
+// a grant on an owner covers the same grant on a descendant, and a grant of
+// an action covers the same grant of an action it implies within the scheme
+func subsumes(a, b Granted, rules matchRules) bool {
+	for _, v := range rules.owners.lowerGranted(a) {
+		if subsumesValues(v, b, rules.actions) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func subsumesValues(a, b Granted, actions actionLattice) bool {
+	aValues, bValues := pairValues(Requested{}, a), pairValues(Requested{}, b)
+	for i := range aValues {
+		if aValues[i].dimension == DimensionPath {
+			if !pathSubsumes(a.ExposePath, b.ExposePath) {
+				return false
+			}
+			continue
+		}
+		if aValues[i].dimension == DimensionAction && impliesAction(a, b, actions) {
+			continue
+		}
+		if aValues[i].grantedResource != bValues[i].grantedResource {
+			return false
+		}
+		if aValues[i].granted != "*" && aValues[i].granted != bValues[i].granted {
+			return false
+		}
+	}
+
+	if !a.NotBefore.IsZero() && (b.NotBefore.IsZero() || b.NotBefore.Before(a.NotBefore)) {
+		return false
+	}
+
+	return a.ExpiresAt.IsZero() || (!b.ExpiresAt.IsZero() && !b.ExpiresAt.After(a.ExpiresAt))
+}
+
+// impliesAction reports whether the action of a implies the one of b. Only
+// grants of the same scheme are compared, a request with a wildcard scheme
+// uses the hierarchy of the scheme of the grant.
+func impliesAction(a, b Granted, actions actionLattice) bool {
+	return a.GrandScheme == b.GrandScheme && a.GrandScheme != "*" &&
+		actions.implies(a.GrandScheme, a.GrandAction, b.GrandAction)
+}
+
+// Redundancy is a grant that other grants subsume, so removing it denies no request
+type Redundancy struct {
+	Granted   Granted    `json:"granted"`
+	CoveredBy []Covering `json:"covered_by"`
+}
+
+// Covering is a grant that subsumes a redundant grant
+type Covering struct {
+	Granted Granted `json:"granted"`
+	// Broader are the dimensions in which the covering grant has a wildcard,
+	// an action that implies the one of the redundant grant or an ancestor
+	// owner, none for grants that are equivalent
+	Broader []Dimension `json:"broader"`
+}
+
+// AnalyzeRedundancy reports the grants that another grant subsumes, in the
+// order of granted. Of grants that subsume each other only the ones with
+// the greater locator are reported, so the remaining grants keep the same
+// access. Like Subsumes it does not apply stored rules and does not
+// consider deny grants.
+func AnalyzeRedundancy(granted []Granted) []Redundancy {
+	return analyzeRedundancy(granted, matchRules{})
+}
+
+func analyzeRedundancy(granted []Granted, rules matchRules) []Redundancy {
+	redundancies := []Redundancy{}
+	for _, g := range granted {
+		if covering := coveringGrants(g, granted, rules); len(covering) > 0 {
+			redundancies = append(redundancies, Redundancy{Granted: g, CoveredBy: covering})
+		}
+	}
+
+	return redundancies
+}
+
+// RedundantGrants analyzes the redundancy of all stored grants with the
+// stored owner parents and implied actions, the same rules as FindGranted
+func (dm *DbManager) RedundantGrants() ([]Redundancy, error) {
+	granted, err := dm.ListGranted()
+	if err != nil {
+		return nil, err
+	}
+	rules, err := loadMatchRules(dm.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return analyzeRedundancy(granted, rules), nil
+}
+
+// coveringGrants returns the grants that make g redundant
+func coveringGrants(g Granted, granted []Granted, rules matchRules) []Covering {
+	locator := grantedLocator(g)
+	var covering []Covering
+	for _, other := range granted {
+		otherLocator := grantedLocator(other)
+		if otherLocator == locator || !subsumes(other, g, rules) {
+			continue
+		}
+		if subsumes(g, other, rules) && locator < otherLocator {
+			continue
+		}
+		covering = append(covering, Covering{Granted: other, Broader: broaderDimensions(other, g, rules.actions)})
+	}
+
+	return covering
+}
+
+// broaderDimensions returns the dimensions in which a has a wildcard and b
+// has not, an implying action or an ancestor owner, and the path when the
+// expose path of a covers more than the one of b
+func broaderDimensions(a, b Granted, actions actionLattice) []Dimension {
+	aValues, bValues := pairValues(Requested{}, a), pairValues(Requested{}, b)
+	broader := []Dimension{}
+	for i := range aValues {
+		switch {
+		case aValues[i].dimension == DimensionPath:
+			if !pathSubsumes(b.ExposePath, a.ExposePath) {
+				broader = append(broader, DimensionPath)
+			}
+		case aValues[i].dimension == DimensionAction && a.GrandAction != b.GrandAction && impliesAction(a, b, actions):
+			broader = append(broader, DimensionAction)
+		case aValues[i].grantedResource != bValues[i].grantedResource:
+			broader = append(broader, aValues[i].dimension)
+		case aValues[i].granted == "*" && bValues[i].granted != "*":
+			broader = append(broader, aValues[i].dimension)
+		}
+	}
+
+	return broader
+}
@@ -0,0 +1,167 @@
+package syncer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestSubsumes(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		a        Granted
+		b        Granted
+		expected bool
+	}{
+		{
+			name:     "wildcard target covers exact target",
+			a:        Granted{ContainerName: "c", Target: "cmd", GrandScheme: "image", GrandTarget: "*"},
+			b:        Granted{ContainerName: "c", Target: "cmd", GrandScheme: "image", GrandTarget: "cmd"},
+			expected: true,
+		},
+		{
+			name:     "exact target does not cover wildcard target",
+			a:        Granted{ContainerName: "c", Target: "cmd", GrandScheme: "image", GrandTarget: "cmd"},
+			b:        Granted{ContainerName: "c", Target: "cmd", GrandScheme: "image", GrandTarget: "*"},
+			expected: false,
+		},
+		{
+			name:     "different resource",
+			a:        Granted{ContainerName: "c", GrandScheme: "*", GrandAction: "*"},
+			b:        Granted{ContainerName: "d", GrandScheme: "image"},
+			expected: false,
+		},
+		{
+			name:     "longer time window",
+			a:        Granted{ContainerName: "c", GrandScheme: "image", ExpiresAt: now.Add(time.Hour)},
+			b:        Granted{ContainerName: "c", GrandScheme: "image", NotBefore: now, ExpiresAt: now.Add(time.Minute)},
+			expected: true,
+		},
+		{
+			name:     "expiring grant does not cover permanent grant",
+			a:        Granted{ContainerName: "c", GrandScheme: "image", ExpiresAt: now.Add(time.Hour)},
+			b:        Granted{ContainerName: "c", GrandScheme: "image"},
+			expected: false,
+		},
+		{
+			name:     "parent expose path covers nested expose path",
+			a:        Granted{ContainerName: "c", GrandScheme: "image", ExposePath: "/public"},
+			b:        Granted{ContainerName: "c", GrandScheme: "image", ExposePath: "/public/images/**"},
+			expected: true,
+		},
+		{
+			name:     "expose path does not cover unrestricted path",
+			a:        Granted{ContainerName: "c", GrandScheme: "image", ExposePath: "/public"},
+			b:        Granted{ContainerName: "c", GrandScheme: "image"},
+			expected: false,
+		},
+		{
+			name:     "single segment glob does not cover any segments",
+			a:        Granted{ContainerName: "c", GrandScheme: "image", ExposePath: "/sites/*/public"},
+			b:        Granted{ContainerName: "c", GrandScheme: "image", ExposePath: "/sites/**/public"},
+			expected: false,
+		},
+		{
+			name:     "later grant does not cover earlier grant",
+			a:        Granted{ContainerName: "c", GrandScheme: "image", NotBefore: now},
+			b:        Granted{ContainerName: "c", GrandScheme: "image", NotBefore: now.Add(-time.Hour)},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			is.Equal(Subsumes(tt.a, tt.b), tt.expected)
+		})
+	}
+}
+
+func TestAnalyzeRedundancy(t *testing.T) {
+	// Given
+	is := is.New(t)
+	anyTarget := Granted{ContainerName: "image/container", Target: "cmd", GrandScheme: "image", GrandAction: "*", GrandTarget: "*"}
+	cmdTarget := Granted{ContainerName: "image/container", Target: "cmd", GrandScheme: "image", GrandAction: "pull", GrandTarget: "cmd"}
+	other := Granted{ContainerName: "image/other", Target: "cmd", GrandScheme: "image", GrandAction: "pull", GrandTarget: "cmd"}
+
+	// When
+	redundancies := AnalyzeRedundancy([]Granted{anyTarget, cmdTarget, other})
+
+	// Then
+	is.Equal(redundancies, []Redundancy{{
+		Granted: cmdTarget,
+		CoveredBy: []Covering{{
+			Granted: anyTarget,
+			Broader: []Dimension{DimensionAction, DimensionTarget},
+		}},
+	}})
+}
+
+func TestAnalyzeRedundancy_equivalent_grants(t *testing.T) {
+	// Given
+	is := is.New(t)
+	first := Granted{ContainerName: "image/container", GrandScheme: "image", Description: "first"}
+	second := Granted{ContainerName: "image/container", GrandScheme: "image", Description: "second"}
+
+	// When
+	redundancies := AnalyzeRedundancy([]Granted{first, second})
+
+	// Then
+	is.Equal(len(redundancies), 1) // One of both stays
+	is.Equal(redundancies[0].CoveredBy[0].Broader, []Dimension{})
+}
+
+func TestRedundantGrants(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	mockGranted(dbManager, Granted{ContainerName: "image/container", GrandScheme: "*", GrandAction: "pull"})
+	mockGranted(dbManager, Granted{ContainerName: "image/container", GrandScheme: "image", GrandAction: "pull"})
+
+	// When
+	redundancies, err := dbManager.RedundantGrants()
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(redundancies), 1)
+	is.Equal(redundancies[0].Granted.GrandScheme, "image")
+	is.Equal(redundancies[0].CoveredBy[0].Broader, []Dimension{DimensionScheme})
+}
+
+func TestRedundantGrants_applies_implied_actions_and_owner_parents(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	push := Granted{ContainerName: "image/container", GrandScheme: "image", GrandAction: "push"}
+	pull := Granted{ContainerName: "image/container", GrandScheme: "image", GrandAction: "pull"}
+	parent, child := ownedGrant(confettiCms), ownedGrant(imageSource)
+	parent.GrandAction, child.GrandAction = "sync", "sync"
+	for _, g := range []Granted{push, pull, parent, child} {
+		mockGranted(dbManager, g)
+	}
+	before, err := dbManager.RedundantGrants()
+	is.NoErr(err)
+	is.NoErr(imageHierarchy(dbManager))
+	is.NoErr(dbManager.SetParent(imageSource, confettiCms))
+
+	// When
+	redundancies, err := dbManager.RedundantGrants()
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(before), 0)
+	is.Equal(len(AnalyzeRedundancy([]Granted{push, pull, parent, child})), 0) // Without stored rules
+	is.Equal(len(redundancies), 2)
+	for _, redundancy := range redundancies {
+		switch redundancy.Granted.GrandAction {
+		case "pull":
+			is.Equal(redundancy.CoveredBy[0].Broader, []Dimension{DimensionAction})
+		case "sync":
+			is.Equal(redundancy.Granted.SourceRepository, "image")
+			is.Equal(redundancy.CoveredBy[0].Broader, []Dimension{DimensionSourceRepository})
+		default:
+			t.Fatalf("unexpected redundancy %+v", redundancy)
+		}
+	}
+}
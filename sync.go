@@ -0,0 +1,253 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// SyncOperation copies the data at SourcePath of the source container to
+// DestinationPath, for a requested permission that a grant satisfies
+type SyncOperation struct {
+	SourceHost      string `json:"source_host"`
+	SourceContainer string `json:"source_container"`
+	SourcePath      string `json:"source_path"`
+	DestinationPath string `json:"destination_path"`
+	Scheme          string `json:"scheme"`
+	Action          string `json:"action"`
+	// RequestedLocator and GrantedLocator identify the pair the operation is planned for
+	RequestedLocator string `json:"requested_locator"`
+	GrantedLocator   string `json:"granted_locator"`
+}
+
+// SyncRejection is a requested permission, or a pair of a requested and a
+// granted permission, that results in no operation
+type SyncRejection struct {
+	Requested Requested `json:"requested"`
+	// Granted is nil when no grant satisfies the request
+	Granted *Granted `json:"granted,omitempty"`
+	Reason  string   `json:"reason"`
+}
+
+// Reasons used in SyncRejection, next to the reasons of SkippedMount
+const (
+	RejectNotGranted      = "no active grant satisfies the request"
+	RejectWildcardScheme  = "neither the request nor the grant names a scheme"
+	RejectWildcardAction  = "neither the request nor the grant names an action"
+	RejectConflictingPath = "another source syncs to the same destination path"
+)
+
+// SyncPlan is an ordered list of sync operations, parent destination paths
+// before the paths within them, together with the rejected entries. The
+// same permissions always result in the same plan.
+type SyncPlan struct {
+	Operations []SyncOperation `json:"operations"`
+	Rejected   []SyncRejection `json:"rejected"`
+}
+
+// SyncExecutor carries out sync operations over a transport, e.g. rsync or an object store
+type SyncExecutor interface {
+	Execute(ctx context.Context, operation SyncOperation) error
+}
+
+// SyncExecutorFunc adapts a function to a SyncExecutor
+type SyncExecutorFunc func(ctx context.Context, operation SyncOperation) error
+
+func (f SyncExecutorFunc) Execute(ctx context.Context, operation SyncOperation) error {
+	return f(ctx, operation)
+}
+
+// PlanContainerSync plans the sync of the stored requested permissions of
+// a container name and target, see PlanSync
+func (dm *DbManager) PlanContainerSync(containerName, target string, opts ...QueryOption) (SyncPlan, error) {
+	_, from, err := dm.resolveQuery("requested", opts)
+	if err != nil {
+		return SyncPlan{}, err
+	}
+
+	args := append(append([]any{}, from.args...), normalizeContainerName(containerName), target)
+	rows, err := dm.db.Query(fmt.Sprintf(`SELECT %s FROM %s WHERE container_name = ? AND target = ? ORDER BY locator`,
+		requestedColumns, from.from), args...)
+	if err != nil {
+		return SyncPlan{}, fmt.Errorf("failed to query requested records: %w", err)
+	}
+	requested, err := scanRequested(rows)
+	if err != nil {
+		return SyncPlan{}, err
+	}
+
+	return dm.PlanSync(requested, opts...)
+}
+
+// PlanSync finds the grants of every requested permission with the same
+// rules as FindGranted and plans an operation for every source, like the
+// mounts of ResolveMounts. Scheme and action are the requested ones, or the
+// granted ones when the request has a wildcard. Destinations of a container
+// name and target with more than one source are rejected as a whole.
+func (dm *DbManager) PlanSync(requested []Requested, opts ...QueryOption) (SyncPlan, error) {
+	now, from, err := dm.resolveQuery("granted", opts)
+	if err != nil {
+		return SyncPlan{}, err
+	}
+
+	plan := SyncPlan{Operations: []SyncOperation{}, Rejected: []SyncRejection{}}
+	var planned []plannedOperation
+	seen := map[SyncOperation]bool{}
+	for _, r := range requested {
+		granted, err := findGrantedIn(dm.db, from, now, []Requested{r})
+		if err != nil {
+			return SyncPlan{}, err
+		}
+		if len(granted) == 0 {
+			plan.Rejected = append(plan.Rejected, SyncRejection{Requested: r, Reason: RejectNotGranted})
+			continue
+		}
+
+		// Keep the grant with the lowest locator of grants that expose the same data
+		sort.Slice(granted, func(i, j int) bool {
+			return grantedLocator(granted[i]) < grantedLocator(granted[j])
+		})
+		for _, g := range granted {
+			operation, reason := planOperation(r, g)
+			if reason != "" {
+				plan.Rejected = append(plan.Rejected, SyncRejection{Requested: r, Granted: &g, Reason: reason})
+				continue
+			}
+			key := operation
+			key.GrantedLocator = ""
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			planned = append(planned, plannedOperation{operation: operation, requested: r, granted: g})
+		}
+	}
+
+	sources := map[[3]string]map[[3]string]bool{}
+	for _, p := range planned {
+		destination := p.destination()
+		if sources[destination] == nil {
+			sources[destination] = map[[3]string]bool{}
+		}
+		sources[destination][[3]string{p.operation.SourceHost, p.operation.SourceContainer, p.operation.SourcePath}] = true
+	}
+	for _, p := range planned {
+		if len(sources[p.destination()]) > 1 {
+			plan.Rejected = append(plan.Rejected, SyncRejection{Requested: p.requested, Granted: &p.granted, Reason: RejectConflictingPath})
+			continue
+		}
+		plan.Operations = append(plan.Operations, p.operation)
+	}
+
+	sort.SliceStable(plan.Operations, func(i, j int) bool {
+		return lessOperation(plan.Operations[i], plan.Operations[j])
+	})
+	sort.SliceStable(plan.Rejected, func(i, j int) bool {
+		a, b := plan.Rejected[i], plan.Rejected[j]
+		if la, lb := requestedLocator(a.Requested), requestedLocator(b.Requested); la != lb {
+			return la < lb
+		}
+		return rejectedGrantLocator(a) < rejectedGrantLocator(b)
+	})
+
+	return plan, nil
+}
+
+// ExecuteSyncPlan carries out the operations of the plan in order and stops
+// at the first operation that fails or when the context is done
+func ExecuteSyncPlan(ctx context.Context, plan SyncPlan, executor SyncExecutor) error {
+	for i, operation := range plan.Operations {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := executor.Execute(ctx, operation); err != nil {
+			return fmt.Errorf("failed to execute sync operation %d (%s:%s to %s): %w",
+				i+1, operation.SourceHost, operation.SourcePath, operation.DestinationPath, err)
+		}
+	}
+
+	return nil
+}
+
+// plannedOperation is an operation with the pair it is planned for
+type plannedOperation struct {
+	operation SyncOperation
+	requested Requested
+	granted   Granted
+}
+
+// destination identifies where the operation writes to
+func (p plannedOperation) destination() [3]string {
+	return [3]string{p.requested.ContainerName, p.requested.Target, p.operation.DestinationPath}
+}
+
+// planOperation returns the operation of a grant for a request, or the reason there is none
+func planOperation(r Requested, g Granted) (SyncOperation, string) {
+	mount, reason := resolveMount(r, g)
+	if reason != "" {
+		return SyncOperation{}, reason
+	}
+	scheme := concrete(r.RequestScheme, g.GrandScheme)
+	if scheme == "" {
+		return SyncOperation{}, RejectWildcardScheme
+	}
+	action := concrete(r.RequestAction, g.GrandAction)
+	if action == "" {
+		return SyncOperation{}, RejectWildcardAction
+	}
+
+	return SyncOperation{
+		SourceHost:       mount.SourceHost,
+		SourceContainer:  g.ContainerName,
+		SourcePath:       mount.SourcePath,
+		DestinationPath:  mount.DestinationPath,
+		Scheme:           scheme,
+		Action:           action,
+		RequestedLocator: requestedLocator(r),
+		GrantedLocator:   grantedLocator(g),
+	}, ""
+}
+
+// concrete returns the requested value, or the granted value when the
+// request has a wildcard. It is empty when neither names a value.
+func concrete(requested, granted string) string {
+	for _, value := range []string{requested, granted} {
+		if value != "" && value != "*" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// lessOperation orders operations by destination path segment by segment,
+// so a parent path comes before the paths within it
+func lessOperation(a, b SyncOperation) bool {
+	sa, sb := pathSegments(a.DestinationPath), pathSegments(b.DestinationPath)
+	for i := 0; i < len(sa) && i < len(sb); i++ {
+		if sa[i] != sb[i] {
+			return sa[i] < sb[i]
+		}
+	}
+	if len(sa) != len(sb) {
+		return len(sa) < len(sb)
+	}
+
+	keysA := []string{a.DestinationPath, a.SourceHost, a.SourceContainer, a.SourcePath, a.Scheme, a.Action, a.RequestedLocator, a.GrantedLocator}
+	keysB := []string{b.DestinationPath, b.SourceHost, b.SourceContainer, b.SourcePath, b.Scheme, b.Action, b.RequestedLocator, b.GrantedLocator}
+	for i := range keysA {
+		if keysA[i] != keysB[i] {
+			return keysA[i] < keysB[i]
+		}
+	}
+
+	return false
+}
+
+func rejectedGrantLocator(r SyncRejection) string {
+	if r.Granted == nil {
+		return ""
+	}
+
+	return grantedLocator(*r.Granted)
+}
@@ -0,0 +1,115 @@
+package syncer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func mockSync(dbManager *DbManager) (public, images, anyAction Granted) {
+	public = Granted{Host: "assets", ContainerName: "cms/uploads", Target: "web", GrandScheme: "hive", GrandAction: "sync", ExposePath: "/public"}
+	images = Granted{Host: "mirror", ContainerName: "cms/uploads", Target: "web", GrandScheme: "hive", GrandAction: "sync", ExposePath: "/public/images"}
+	anyAction = Granted{Host: "assets", ContainerName: "cms/uploads", Target: "web", GrandScheme: "image", GrandAction: "*", ExposePath: "/images/**"}
+	for _, g := range []Granted{public, images, anyAction} {
+		mockGranted(dbManager, g)
+	}
+
+	return public, images, anyAction
+}
+
+func TestPlanSync(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	public, _, anyAction := mockSync(dbManager)
+	css := Requested{ContainerName: "cms/uploads", Target: "web", RequestScheme: "hive", RequestAction: "sync", DestinationPath: "/public/css"}
+	root := Requested{ContainerName: "cms/uploads", Target: "web", RequestScheme: "hive", RequestAction: "*", DestinationPath: "/public"}
+	logo := Requested{ContainerName: "cms/uploads", Target: "web", RequestScheme: "hive", RequestAction: "sync", DestinationPath: "/public/images/logo.png"}
+	anyImage := Requested{ContainerName: "cms/uploads", Target: "web", RequestScheme: "image", RequestAction: "*"}
+	denied := Requested{ContainerName: "cms/uploads", Target: "web", RequestScheme: "json", RequestAction: "read"}
+
+	// When
+	plan, err := dbManager.PlanSync([]Requested{css, root, logo, anyImage, denied})
+	is.NoErr(err)
+	reversed, err := dbManager.PlanSync([]Requested{denied, anyImage, logo, root, css})
+	is.NoErr(err)
+
+	// Then
+	is.Equal(plan.Operations, []SyncOperation{
+		{SourceHost: "assets", SourceContainer: "cms/uploads", SourcePath: "/public", DestinationPath: "/public", Scheme: "hive", Action: "sync",
+			RequestedLocator: requestedLocator(root), GrantedLocator: grantedLocator(public)},
+		{SourceHost: "assets", SourceContainer: "cms/uploads", SourcePath: "/public/css", DestinationPath: "/public/css", Scheme: "hive", Action: "sync",
+			RequestedLocator: requestedLocator(css), GrantedLocator: grantedLocator(public)},
+	})
+	is.Equal(len(plan.Rejected), 4)
+	rejected := map[string][]string{}
+	for _, rejection := range plan.Rejected {
+		var host string
+		if rejection.Granted != nil {
+			host = rejection.Granted.Host
+		}
+		rejected[rejection.Reason] = append(rejected[rejection.Reason], requestedLocator(rejection.Requested)+" "+host)
+	}
+	is.Equal(len(rejected[RejectNotGranted]), 1)
+	is.Equal(rejected[SkipPathPattern], []string{requestedLocator(anyImage) + " " + anyAction.Host})
+	is.Equal(len(rejected[RejectConflictingPath]), 2) // The logo from assets and from mirror
+	is.True(rejected[RejectConflictingPath][0] != rejected[RejectConflictingPath][1])
+	is.Equal(plan, reversed) // The order of the requests does not matter
+	data, err := json.Marshal(plan)
+	is.NoErr(err)
+	var decoded SyncPlan
+	is.NoErr(json.Unmarshal(data, &decoded))
+	is.Equal(decoded, plan)
+}
+
+func TestPlanContainerSync(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	public, _, _ := mockSync(dbManager)
+	css := Requested{ContainerName: "cms/uploads", Target: "web", RequestScheme: "hive", RequestAction: "sync", DestinationPath: "/public/css"}
+	other := Requested{ContainerName: "cms/uploads", Target: "cmd", RequestScheme: "hive", RequestAction: "sync", DestinationPath: "/public/css"}
+	mockRequested(dbManager, css)
+	mockRequested(dbManager, other)
+
+	// When
+	plan, err := dbManager.PlanContainerSync("/cms//uploads/", "web")
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(plan.Operations), 1)
+	is.Equal(plan.Operations[0].GrantedLocator, grantedLocator(public))
+	is.Equal(plan.Rejected, []SyncRejection{})
+}
+
+func TestExecuteSyncPlan(t *testing.T) {
+	// Given
+	is := is.New(t)
+	plan := SyncPlan{Operations: []SyncOperation{
+		{SourceHost: "assets", SourcePath: "/public", DestinationPath: "/public"},
+		{SourceHost: "assets", SourcePath: "/public/css", DestinationPath: "/public/css"},
+		{SourceHost: "assets", SourcePath: "/public/js", DestinationPath: "/public/js"},
+	}}
+	failure := errors.New("connection refused")
+	var executed []string
+	executor := SyncExecutorFunc(func(ctx context.Context, operation SyncOperation) error {
+		executed = append(executed, operation.DestinationPath)
+		if operation.DestinationPath == "/public/css" {
+			return failure
+		}
+		return nil
+	})
+
+	// When
+	err := ExecuteSyncPlan(context.Background(), plan, executor)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	canceledErr := ExecuteSyncPlan(ctx, plan, executor)
+
+	// Then
+	is.True(errors.Is(err, failure))
+	is.Equal(err.Error(), "failed to execute sync operation 2 (assets:/public/css to /public/css): connection refused")
+	is.Equal(executed, []string{"/public", "/public/css"}) // Stops at the first failure
+	is.True(errors.Is(canceledErr, context.Canceled))
+}
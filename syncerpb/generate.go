@@ -0,0 +1,5 @@
+// Package syncerpb contains the protobuf messages and the gRPC service of the
+// permission store. The server implementation is syncer.GRPCServer.
+package syncerpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative syncer.proto
@@ -0,0 +1,1124 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: syncer.proto
+
+package syncerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Requested mirrors syncer.Requested
+type Requested struct {
+	state                       protoimpl.MessageState `protogen:"open.v1"`
+	Description                 string                 `protobuf:"bytes,1,opt,name=description,proto3" json:"description,omitempty"`
+	Host                        string                 `protobuf:"bytes,2,opt,name=host,proto3" json:"host,omitempty"`
+	DestinationPath             string                 `protobuf:"bytes,3,opt,name=destination_path,json=destinationPath,proto3" json:"destination_path,omitempty"`
+	SourceOrganization          string                 `protobuf:"bytes,4,opt,name=source_organization,json=sourceOrganization,proto3" json:"source_organization,omitempty"`
+	SourceRepository            string                 `protobuf:"bytes,5,opt,name=source_repository,json=sourceRepository,proto3" json:"source_repository,omitempty"`
+	UmbrellaOrganization        string                 `protobuf:"bytes,6,opt,name=umbrella_organization,json=umbrellaOrganization,proto3" json:"umbrella_organization,omitempty"`
+	UmbrellaRepository          string                 `protobuf:"bytes,7,opt,name=umbrella_repository,json=umbrellaRepository,proto3" json:"umbrella_repository,omitempty"`
+	ContainerName               string                 `protobuf:"bytes,8,opt,name=container_name,json=containerName,proto3" json:"container_name,omitempty"`
+	Target                      string                 `protobuf:"bytes,9,opt,name=target,proto3" json:"target,omitempty"`
+	RequestScheme               string                 `protobuf:"bytes,10,opt,name=request_scheme,json=requestScheme,proto3" json:"request_scheme,omitempty"`
+	RequestAction               string                 `protobuf:"bytes,11,opt,name=request_action,json=requestAction,proto3" json:"request_action,omitempty"`
+	RequestSourceOrganization   string                 `protobuf:"bytes,12,opt,name=request_source_organization,json=requestSourceOrganization,proto3" json:"request_source_organization,omitempty"`
+	RequestSourceRepository     string                 `protobuf:"bytes,13,opt,name=request_source_repository,json=requestSourceRepository,proto3" json:"request_source_repository,omitempty"`
+	RequestUmbrellaOrganization string                 `protobuf:"bytes,14,opt,name=request_umbrella_organization,json=requestUmbrellaOrganization,proto3" json:"request_umbrella_organization,omitempty"`
+	RequestUmbrellaRepository   string                 `protobuf:"bytes,15,opt,name=request_umbrella_repository,json=requestUmbrellaRepository,proto3" json:"request_umbrella_repository,omitempty"`
+	RequestContainerName        string                 `protobuf:"bytes,16,opt,name=request_container_name,json=requestContainerName,proto3" json:"request_container_name,omitempty"`
+	RequestTarget               string                 `protobuf:"bytes,17,opt,name=request_target,json=requestTarget,proto3" json:"request_target,omitempty"`
+	unknownFields               protoimpl.UnknownFields
+	sizeCache                   protoimpl.SizeCache
+}
+
+func (x *Requested) Reset() {
+	*x = Requested{}
+	mi := &file_syncer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Requested) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Requested) ProtoMessage() {}
+
+func (x *Requested) ProtoReflect() protoreflect.Message {
+	mi := &file_syncer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Requested.ProtoReflect.Descriptor instead.
+func (*Requested) Descriptor() ([]byte, []int) {
+	return file_syncer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Requested) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Requested) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *Requested) GetDestinationPath() string {
+	if x != nil {
+		return x.DestinationPath
+	}
+	return ""
+}
+
+func (x *Requested) GetSourceOrganization() string {
+	if x != nil {
+		return x.SourceOrganization
+	}
+	return ""
+}
+
+func (x *Requested) GetSourceRepository() string {
+	if x != nil {
+		return x.SourceRepository
+	}
+	return ""
+}
+
+func (x *Requested) GetUmbrellaOrganization() string {
+	if x != nil {
+		return x.UmbrellaOrganization
+	}
+	return ""
+}
+
+func (x *Requested) GetUmbrellaRepository() string {
+	if x != nil {
+		return x.UmbrellaRepository
+	}
+	return ""
+}
+
+func (x *Requested) GetContainerName() string {
+	if x != nil {
+		return x.ContainerName
+	}
+	return ""
+}
+
+func (x *Requested) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *Requested) GetRequestScheme() string {
+	if x != nil {
+		return x.RequestScheme
+	}
+	return ""
+}
+
+func (x *Requested) GetRequestAction() string {
+	if x != nil {
+		return x.RequestAction
+	}
+	return ""
+}
+
+func (x *Requested) GetRequestSourceOrganization() string {
+	if x != nil {
+		return x.RequestSourceOrganization
+	}
+	return ""
+}
+
+func (x *Requested) GetRequestSourceRepository() string {
+	if x != nil {
+		return x.RequestSourceRepository
+	}
+	return ""
+}
+
+func (x *Requested) GetRequestUmbrellaOrganization() string {
+	if x != nil {
+		return x.RequestUmbrellaOrganization
+	}
+	return ""
+}
+
+func (x *Requested) GetRequestUmbrellaRepository() string {
+	if x != nil {
+		return x.RequestUmbrellaRepository
+	}
+	return ""
+}
+
+func (x *Requested) GetRequestContainerName() string {
+	if x != nil {
+		return x.RequestContainerName
+	}
+	return ""
+}
+
+func (x *Requested) GetRequestTarget() string {
+	if x != nil {
+		return x.RequestTarget
+	}
+	return ""
+}
+
+// Granted mirrors syncer.Granted
+type Granted struct {
+	state                     protoimpl.MessageState `protogen:"open.v1"`
+	Description               string                 `protobuf:"bytes,1,opt,name=description,proto3" json:"description,omitempty"`
+	Host                      string                 `protobuf:"bytes,2,opt,name=host,proto3" json:"host,omitempty"`
+	ExposePath                string                 `protobuf:"bytes,3,opt,name=expose_path,json=exposePath,proto3" json:"expose_path,omitempty"`
+	SourceOrganization        string                 `protobuf:"bytes,4,opt,name=source_organization,json=sourceOrganization,proto3" json:"source_organization,omitempty"`
+	SourceRepository          string                 `protobuf:"bytes,5,opt,name=source_repository,json=sourceRepository,proto3" json:"source_repository,omitempty"`
+	UmbrellaOrganization      string                 `protobuf:"bytes,6,opt,name=umbrella_organization,json=umbrellaOrganization,proto3" json:"umbrella_organization,omitempty"`
+	UmbrellaRepository        string                 `protobuf:"bytes,7,opt,name=umbrella_repository,json=umbrellaRepository,proto3" json:"umbrella_repository,omitempty"`
+	ContainerName             string                 `protobuf:"bytes,8,opt,name=container_name,json=containerName,proto3" json:"container_name,omitempty"`
+	Target                    string                 `protobuf:"bytes,9,opt,name=target,proto3" json:"target,omitempty"`
+	GrandScheme               string                 `protobuf:"bytes,10,opt,name=grand_scheme,json=grandScheme,proto3" json:"grand_scheme,omitempty"`
+	GrandAction               string                 `protobuf:"bytes,11,opt,name=grand_action,json=grandAction,proto3" json:"grand_action,omitempty"`
+	GrandSourceOrganization   string                 `protobuf:"bytes,12,opt,name=grand_source_organization,json=grandSourceOrganization,proto3" json:"grand_source_organization,omitempty"`
+	GrandSourceRepository     string                 `protobuf:"bytes,13,opt,name=grand_source_repository,json=grandSourceRepository,proto3" json:"grand_source_repository,omitempty"`
+	GrandUmbrellaOrganization string                 `protobuf:"bytes,14,opt,name=grand_umbrella_organization,json=grandUmbrellaOrganization,proto3" json:"grand_umbrella_organization,omitempty"`
+	GrandUmbrellaRepository   string                 `protobuf:"bytes,15,opt,name=grand_umbrella_repository,json=grandUmbrellaRepository,proto3" json:"grand_umbrella_repository,omitempty"`
+	GrandContainerName        string                 `protobuf:"bytes,16,opt,name=grand_container_name,json=grandContainerName,proto3" json:"grand_container_name,omitempty"`
+	GrandTarget               string                 `protobuf:"bytes,17,opt,name=grand_target,json=grandTarget,proto3" json:"grand_target,omitempty"`
+	// not_before and expires_at are unset when the grant has no time limit
+	NotBefore     *timestamppb.Timestamp `protobuf:"bytes,18,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,19,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Granted) Reset() {
+	*x = Granted{}
+	mi := &file_syncer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Granted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Granted) ProtoMessage() {}
+
+func (x *Granted) ProtoReflect() protoreflect.Message {
+	mi := &file_syncer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Granted.ProtoReflect.Descriptor instead.
+func (*Granted) Descriptor() ([]byte, []int) {
+	return file_syncer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Granted) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Granted) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *Granted) GetExposePath() string {
+	if x != nil {
+		return x.ExposePath
+	}
+	return ""
+}
+
+func (x *Granted) GetSourceOrganization() string {
+	if x != nil {
+		return x.SourceOrganization
+	}
+	return ""
+}
+
+func (x *Granted) GetSourceRepository() string {
+	if x != nil {
+		return x.SourceRepository
+	}
+	return ""
+}
+
+func (x *Granted) GetUmbrellaOrganization() string {
+	if x != nil {
+		return x.UmbrellaOrganization
+	}
+	return ""
+}
+
+func (x *Granted) GetUmbrellaRepository() string {
+	if x != nil {
+		return x.UmbrellaRepository
+	}
+	return ""
+}
+
+func (x *Granted) GetContainerName() string {
+	if x != nil {
+		return x.ContainerName
+	}
+	return ""
+}
+
+func (x *Granted) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *Granted) GetGrandScheme() string {
+	if x != nil {
+		return x.GrandScheme
+	}
+	return ""
+}
+
+func (x *Granted) GetGrandAction() string {
+	if x != nil {
+		return x.GrandAction
+	}
+	return ""
+}
+
+func (x *Granted) GetGrandSourceOrganization() string {
+	if x != nil {
+		return x.GrandSourceOrganization
+	}
+	return ""
+}
+
+func (x *Granted) GetGrandSourceRepository() string {
+	if x != nil {
+		return x.GrandSourceRepository
+	}
+	return ""
+}
+
+func (x *Granted) GetGrandUmbrellaOrganization() string {
+	if x != nil {
+		return x.GrandUmbrellaOrganization
+	}
+	return ""
+}
+
+func (x *Granted) GetGrandUmbrellaRepository() string {
+	if x != nil {
+		return x.GrandUmbrellaRepository
+	}
+	return ""
+}
+
+func (x *Granted) GetGrandContainerName() string {
+	if x != nil {
+		return x.GrandContainerName
+	}
+	return ""
+}
+
+func (x *Granted) GetGrandTarget() string {
+	if x != nil {
+		return x.GrandTarget
+	}
+	return ""
+}
+
+func (x *Granted) GetNotBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotBefore
+	}
+	return nil
+}
+
+func (x *Granted) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type SaveRequestedRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Requested     []*Requested           `protobuf:"bytes,1,rep,name=requested,proto3" json:"requested,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveRequestedRequest) Reset() {
+	*x = SaveRequestedRequest{}
+	mi := &file_syncer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveRequestedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveRequestedRequest) ProtoMessage() {}
+
+func (x *SaveRequestedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_syncer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveRequestedRequest.ProtoReflect.Descriptor instead.
+func (*SaveRequestedRequest) Descriptor() ([]byte, []int) {
+	return file_syncer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SaveRequestedRequest) GetRequested() []*Requested {
+	if x != nil {
+		return x.Requested
+	}
+	return nil
+}
+
+type SaveRequestedResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveRequestedResponse) Reset() {
+	*x = SaveRequestedResponse{}
+	mi := &file_syncer_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveRequestedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveRequestedResponse) ProtoMessage() {}
+
+func (x *SaveRequestedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_syncer_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveRequestedResponse.ProtoReflect.Descriptor instead.
+func (*SaveRequestedResponse) Descriptor() ([]byte, []int) {
+	return file_syncer_proto_rawDescGZIP(), []int{3}
+}
+
+type SaveGrantedRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Granted       []*Granted             `protobuf:"bytes,1,rep,name=granted,proto3" json:"granted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveGrantedRequest) Reset() {
+	*x = SaveGrantedRequest{}
+	mi := &file_syncer_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveGrantedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveGrantedRequest) ProtoMessage() {}
+
+func (x *SaveGrantedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_syncer_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveGrantedRequest.ProtoReflect.Descriptor instead.
+func (*SaveGrantedRequest) Descriptor() ([]byte, []int) {
+	return file_syncer_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SaveGrantedRequest) GetGranted() []*Granted {
+	if x != nil {
+		return x.Granted
+	}
+	return nil
+}
+
+type SaveGrantedResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveGrantedResponse) Reset() {
+	*x = SaveGrantedResponse{}
+	mi := &file_syncer_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveGrantedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveGrantedResponse) ProtoMessage() {}
+
+func (x *SaveGrantedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_syncer_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveGrantedResponse.ProtoReflect.Descriptor instead.
+func (*SaveGrantedResponse) Descriptor() ([]byte, []int) {
+	return file_syncer_proto_rawDescGZIP(), []int{5}
+}
+
+type FindGrantedRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Requested     []*Requested           `protobuf:"bytes,1,rep,name=requested,proto3" json:"requested,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindGrantedRequest) Reset() {
+	*x = FindGrantedRequest{}
+	mi := &file_syncer_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindGrantedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindGrantedRequest) ProtoMessage() {}
+
+func (x *FindGrantedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_syncer_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindGrantedRequest.ProtoReflect.Descriptor instead.
+func (*FindGrantedRequest) Descriptor() ([]byte, []int) {
+	return file_syncer_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *FindGrantedRequest) GetRequested() []*Requested {
+	if x != nil {
+		return x.Requested
+	}
+	return nil
+}
+
+type FindGrantedResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Granted       []*Granted             `protobuf:"bytes,1,rep,name=granted,proto3" json:"granted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindGrantedResponse) Reset() {
+	*x = FindGrantedResponse{}
+	mi := &file_syncer_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindGrantedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindGrantedResponse) ProtoMessage() {}
+
+func (x *FindGrantedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_syncer_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindGrantedResponse.ProtoReflect.Descriptor instead.
+func (*FindGrantedResponse) Descriptor() ([]byte, []int) {
+	return file_syncer_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *FindGrantedResponse) GetGranted() []*Granted {
+	if x != nil {
+		return x.Granted
+	}
+	return nil
+}
+
+type FindRequestedRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Granted       []*Granted             `protobuf:"bytes,1,rep,name=granted,proto3" json:"granted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindRequestedRequest) Reset() {
+	*x = FindRequestedRequest{}
+	mi := &file_syncer_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindRequestedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindRequestedRequest) ProtoMessage() {}
+
+func (x *FindRequestedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_syncer_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindRequestedRequest.ProtoReflect.Descriptor instead.
+func (*FindRequestedRequest) Descriptor() ([]byte, []int) {
+	return file_syncer_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *FindRequestedRequest) GetGranted() []*Granted {
+	if x != nil {
+		return x.Granted
+	}
+	return nil
+}
+
+type FindRequestedResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Requested     []*Requested           `protobuf:"bytes,1,rep,name=requested,proto3" json:"requested,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindRequestedResponse) Reset() {
+	*x = FindRequestedResponse{}
+	mi := &file_syncer_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindRequestedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindRequestedResponse) ProtoMessage() {}
+
+func (x *FindRequestedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_syncer_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindRequestedResponse.ProtoReflect.Descriptor instead.
+func (*FindRequestedResponse) Descriptor() ([]byte, []int) {
+	return file_syncer_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *FindRequestedResponse) GetRequested() []*Requested {
+	if x != nil {
+		return x.Requested
+	}
+	return nil
+}
+
+type DeleteRequestedRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Requested     []*Requested           `protobuf:"bytes,1,rep,name=requested,proto3" json:"requested,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRequestedRequest) Reset() {
+	*x = DeleteRequestedRequest{}
+	mi := &file_syncer_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequestedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequestedRequest) ProtoMessage() {}
+
+func (x *DeleteRequestedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_syncer_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequestedRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequestedRequest) Descriptor() ([]byte, []int) {
+	return file_syncer_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeleteRequestedRequest) GetRequested() []*Requested {
+	if x != nil {
+		return x.Requested
+	}
+	return nil
+}
+
+type DeleteRequestedResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRequestedResponse) Reset() {
+	*x = DeleteRequestedResponse{}
+	mi := &file_syncer_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequestedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequestedResponse) ProtoMessage() {}
+
+func (x *DeleteRequestedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_syncer_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequestedResponse.ProtoReflect.Descriptor instead.
+func (*DeleteRequestedResponse) Descriptor() ([]byte, []int) {
+	return file_syncer_proto_rawDescGZIP(), []int{11}
+}
+
+type DeleteGrantedRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Granted       []*Granted             `protobuf:"bytes,1,rep,name=granted,proto3" json:"granted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteGrantedRequest) Reset() {
+	*x = DeleteGrantedRequest{}
+	mi := &file_syncer_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteGrantedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteGrantedRequest) ProtoMessage() {}
+
+func (x *DeleteGrantedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_syncer_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteGrantedRequest.ProtoReflect.Descriptor instead.
+func (*DeleteGrantedRequest) Descriptor() ([]byte, []int) {
+	return file_syncer_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DeleteGrantedRequest) GetGranted() []*Granted {
+	if x != nil {
+		return x.Granted
+	}
+	return nil
+}
+
+type DeleteGrantedResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteGrantedResponse) Reset() {
+	*x = DeleteGrantedResponse{}
+	mi := &file_syncer_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteGrantedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteGrantedResponse) ProtoMessage() {}
+
+func (x *DeleteGrantedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_syncer_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteGrantedResponse.ProtoReflect.Descriptor instead.
+func (*DeleteGrantedResponse) Descriptor() ([]byte, []int) {
+	return file_syncer_proto_rawDescGZIP(), []int{13}
+}
+
+type StreamRequestedRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamRequestedRequest) Reset() {
+	*x = StreamRequestedRequest{}
+	mi := &file_syncer_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamRequestedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamRequestedRequest) ProtoMessage() {}
+
+func (x *StreamRequestedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_syncer_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamRequestedRequest.ProtoReflect.Descriptor instead.
+func (*StreamRequestedRequest) Descriptor() ([]byte, []int) {
+	return file_syncer_proto_rawDescGZIP(), []int{14}
+}
+
+type StreamGrantedRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamGrantedRequest) Reset() {
+	*x = StreamGrantedRequest{}
+	mi := &file_syncer_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamGrantedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamGrantedRequest) ProtoMessage() {}
+
+func (x *StreamGrantedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_syncer_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamGrantedRequest.ProtoReflect.Descriptor instead.
+func (*StreamGrantedRequest) Descriptor() ([]byte, []int) {
+	return file_syncer_proto_rawDescGZIP(), []int{15}
+}
+
+var File_syncer_proto protoreflect.FileDescriptor
+
+const file_syncer_proto_rawDesc = "" +
+	"\n" +
+	"\fsyncer.proto\x12\x12confetti.syncer.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x9a\x06\n" +
+	"\tRequested\x12 \n" +
+	"\vdescription\x18\x01 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04host\x18\x02 \x01(\tR\x04host\x12)\n" +
+	"\x10destination_path\x18\x03 \x01(\tR\x0fdestinationPath\x12/\n" +
+	"\x13source_organization\x18\x04 \x01(\tR\x12sourceOrganization\x12+\n" +
+	"\x11source_repository\x18\x05 \x01(\tR\x10sourceRepository\x123\n" +
+	"\x15umbrella_organization\x18\x06 \x01(\tR\x14umbrellaOrganization\x12/\n" +
+	"\x13umbrella_repository\x18\a \x01(\tR\x12umbrellaRepository\x12%\n" +
+	"\x0econtainer_name\x18\b \x01(\tR\rcontainerName\x12\x16\n" +
+	"\x06target\x18\t \x01(\tR\x06target\x12%\n" +
+	"\x0erequest_scheme\x18\n" +
+	" \x01(\tR\rrequestScheme\x12%\n" +
+	"\x0erequest_action\x18\v \x01(\tR\rrequestAction\x12>\n" +
+	"\x1brequest_source_organization\x18\f \x01(\tR\x19requestSourceOrganization\x12:\n" +
+	"\x19request_source_repository\x18\r \x01(\tR\x17requestSourceRepository\x12B\n" +
+	"\x1drequest_umbrella_organization\x18\x0e \x01(\tR\x1brequestUmbrellaOrganization\x12>\n" +
+	"\x1brequest_umbrella_repository\x18\x0f \x01(\tR\x19requestUmbrellaRepository\x124\n" +
+	"\x16request_container_name\x18\x10 \x01(\tR\x14requestContainerName\x12%\n" +
+	"\x0erequest_target\x18\x11 \x01(\tR\rrequestTarget\"\xe4\x06\n" +
+	"\aGranted\x12 \n" +
+	"\vdescription\x18\x01 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04host\x18\x02 \x01(\tR\x04host\x12\x1f\n" +
+	"\vexpose_path\x18\x03 \x01(\tR\n" +
+	"exposePath\x12/\n" +
+	"\x13source_organization\x18\x04 \x01(\tR\x12sourceOrganization\x12+\n" +
+	"\x11source_repository\x18\x05 \x01(\tR\x10sourceRepository\x123\n" +
+	"\x15umbrella_organization\x18\x06 \x01(\tR\x14umbrellaOrganization\x12/\n" +
+	"\x13umbrella_repository\x18\a \x01(\tR\x12umbrellaRepository\x12%\n" +
+	"\x0econtainer_name\x18\b \x01(\tR\rcontainerName\x12\x16\n" +
+	"\x06target\x18\t \x01(\tR\x06target\x12!\n" +
+	"\fgrand_scheme\x18\n" +
+	" \x01(\tR\vgrandScheme\x12!\n" +
+	"\fgrand_action\x18\v \x01(\tR\vgrandAction\x12:\n" +
+	"\x19grand_source_organization\x18\f \x01(\tR\x17grandSourceOrganization\x126\n" +
+	"\x17grand_source_repository\x18\r \x01(\tR\x15grandSourceRepository\x12>\n" +
+	"\x1bgrand_umbrella_organization\x18\x0e \x01(\tR\x19grandUmbrellaOrganization\x12:\n" +
+	"\x19grand_umbrella_repository\x18\x0f \x01(\tR\x17grandUmbrellaRepository\x120\n" +
+	"\x14grand_container_name\x18\x10 \x01(\tR\x12grandContainerName\x12!\n" +
+	"\fgrand_target\x18\x11 \x01(\tR\vgrandTarget\x129\n" +
+	"\n" +
+	"not_before\x18\x12 \x01(\v2\x1a.google.protobuf.TimestampR\tnotBefore\x129\n" +
+	"\n" +
+	"expires_at\x18\x13 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"S\n" +
+	"\x14SaveRequestedRequest\x12;\n" +
+	"\trequested\x18\x01 \x03(\v2\x1d.confetti.syncer.v1.RequestedR\trequested\"\x17\n" +
+	"\x15SaveRequestedResponse\"K\n" +
+	"\x12SaveGrantedRequest\x125\n" +
+	"\agranted\x18\x01 \x03(\v2\x1b.confetti.syncer.v1.GrantedR\agranted\"\x15\n" +
+	"\x13SaveGrantedResponse\"Q\n" +
+	"\x12FindGrantedRequest\x12;\n" +
+	"\trequested\x18\x01 \x03(\v2\x1d.confetti.syncer.v1.RequestedR\trequested\"L\n" +
+	"\x13FindGrantedResponse\x125\n" +
+	"\agranted\x18\x01 \x03(\v2\x1b.confetti.syncer.v1.GrantedR\agranted\"M\n" +
+	"\x14FindRequestedRequest\x125\n" +
+	"\agranted\x18\x01 \x03(\v2\x1b.confetti.syncer.v1.GrantedR\agranted\"T\n" +
+	"\x15FindRequestedResponse\x12;\n" +
+	"\trequested\x18\x01 \x03(\v2\x1d.confetti.syncer.v1.RequestedR\trequested\"U\n" +
+	"\x16DeleteRequestedRequest\x12;\n" +
+	"\trequested\x18\x01 \x03(\v2\x1d.confetti.syncer.v1.RequestedR\trequested\"\x19\n" +
+	"\x17DeleteRequestedResponse\"M\n" +
+	"\x14DeleteGrantedRequest\x125\n" +
+	"\agranted\x18\x01 \x03(\v2\x1b.confetti.syncer.v1.GrantedR\agranted\"\x17\n" +
+	"\x15DeleteGrantedResponse\"\x18\n" +
+	"\x16StreamRequestedRequest\"\x16\n" +
+	"\x14StreamGrantedRequest2\xa0\x06\n" +
+	"\x06Syncer\x12d\n" +
+	"\rSaveRequested\x12(.confetti.syncer.v1.SaveRequestedRequest\x1a).confetti.syncer.v1.SaveRequestedResponse\x12^\n" +
+	"\vSaveGranted\x12&.confetti.syncer.v1.SaveGrantedRequest\x1a'.confetti.syncer.v1.SaveGrantedResponse\x12^\n" +
+	"\vFindGranted\x12&.confetti.syncer.v1.FindGrantedRequest\x1a'.confetti.syncer.v1.FindGrantedResponse\x12d\n" +
+	"\rFindRequested\x12(.confetti.syncer.v1.FindRequestedRequest\x1a).confetti.syncer.v1.FindRequestedResponse\x12j\n" +
+	"\x0fDeleteRequested\x12*.confetti.syncer.v1.DeleteRequestedRequest\x1a+.confetti.syncer.v1.DeleteRequestedResponse\x12d\n" +
+	"\rDeleteGranted\x12(.confetti.syncer.v1.DeleteGrantedRequest\x1a).confetti.syncer.v1.DeleteGrantedResponse\x12^\n" +
+	"\x0fStreamRequested\x12*.confetti.syncer.v1.StreamRequestedRequest\x1a\x1d.confetti.syncer.v1.Requested0\x01\x12X\n" +
+	"\rStreamGranted\x12(.confetti.syncer.v1.StreamGrantedRequest\x1a\x1b.confetti.syncer.v1.Granted0\x01B)Z'github.com/confetti-cms/syncer/syncerpbb\x06proto3"
+
+var (
+	file_syncer_proto_rawDescOnce sync.Once
+	file_syncer_proto_rawDescData []byte
+)
+
+func file_syncer_proto_rawDescGZIP() []byte {
+	file_syncer_proto_rawDescOnce.Do(func() {
+		file_syncer_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_syncer_proto_rawDesc), len(file_syncer_proto_rawDesc)))
+	})
+	return file_syncer_proto_rawDescData
+}
+
+var file_syncer_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_syncer_proto_goTypes = []any{
+	(*Requested)(nil),               // 0: confetti.syncer.v1.Requested
+	(*Granted)(nil),                 // 1: confetti.syncer.v1.Granted
+	(*SaveRequestedRequest)(nil),    // 2: confetti.syncer.v1.SaveRequestedRequest
+	(*SaveRequestedResponse)(nil),   // 3: confetti.syncer.v1.SaveRequestedResponse
+	(*SaveGrantedRequest)(nil),      // 4: confetti.syncer.v1.SaveGrantedRequest
+	(*SaveGrantedResponse)(nil),     // 5: confetti.syncer.v1.SaveGrantedResponse
+	(*FindGrantedRequest)(nil),      // 6: confetti.syncer.v1.FindGrantedRequest
+	(*FindGrantedResponse)(nil),     // 7: confetti.syncer.v1.FindGrantedResponse
+	(*FindRequestedRequest)(nil),    // 8: confetti.syncer.v1.FindRequestedRequest
+	(*FindRequestedResponse)(nil),   // 9: confetti.syncer.v1.FindRequestedResponse
+	(*DeleteRequestedRequest)(nil),  // 10: confetti.syncer.v1.DeleteRequestedRequest
+	(*DeleteRequestedResponse)(nil), // 11: confetti.syncer.v1.DeleteRequestedResponse
+	(*DeleteGrantedRequest)(nil),    // 12: confetti.syncer.v1.DeleteGrantedRequest
+	(*DeleteGrantedResponse)(nil),   // 13: confetti.syncer.v1.DeleteGrantedResponse
+	(*StreamRequestedRequest)(nil),  // 14: confetti.syncer.v1.StreamRequestedRequest
+	(*StreamGrantedRequest)(nil),    // 15: confetti.syncer.v1.StreamGrantedRequest
+	(*timestamppb.Timestamp)(nil),   // 16: google.protobuf.Timestamp
+}
+var file_syncer_proto_depIdxs = []int32{
+	16, // 0: confetti.syncer.v1.Granted.not_before:type_name -> google.protobuf.Timestamp
+	16, // 1: confetti.syncer.v1.Granted.expires_at:type_name -> google.protobuf.Timestamp
+	0,  // 2: confetti.syncer.v1.SaveRequestedRequest.requested:type_name -> confetti.syncer.v1.Requested
+	1,  // 3: confetti.syncer.v1.SaveGrantedRequest.granted:type_name -> confetti.syncer.v1.Granted
+	0,  // 4: confetti.syncer.v1.FindGrantedRequest.requested:type_name -> confetti.syncer.v1.Requested
+	1,  // 5: confetti.syncer.v1.FindGrantedResponse.granted:type_name -> confetti.syncer.v1.Granted
+	1,  // 6: confetti.syncer.v1.FindRequestedRequest.granted:type_name -> confetti.syncer.v1.Granted
+	0,  // 7: confetti.syncer.v1.FindRequestedResponse.requested:type_name -> confetti.syncer.v1.Requested
+	0,  // 8: confetti.syncer.v1.DeleteRequestedRequest.requested:type_name -> confetti.syncer.v1.Requested
+	1,  // 9: confetti.syncer.v1.DeleteGrantedRequest.granted:type_name -> confetti.syncer.v1.Granted
+	2,  // 10: confetti.syncer.v1.Syncer.SaveRequested:input_type -> confetti.syncer.v1.SaveRequestedRequest
+	4,  // 11: confetti.syncer.v1.Syncer.SaveGranted:input_type -> confetti.syncer.v1.SaveGrantedRequest
+	6,  // 12: confetti.syncer.v1.Syncer.FindGranted:input_type -> confetti.syncer.v1.FindGrantedRequest
+	8,  // 13: confetti.syncer.v1.Syncer.FindRequested:input_type -> confetti.syncer.v1.FindRequestedRequest
+	10, // 14: confetti.syncer.v1.Syncer.DeleteRequested:input_type -> confetti.syncer.v1.DeleteRequestedRequest
+	12, // 15: confetti.syncer.v1.Syncer.DeleteGranted:input_type -> confetti.syncer.v1.DeleteGrantedRequest
+	14, // 16: confetti.syncer.v1.Syncer.StreamRequested:input_type -> confetti.syncer.v1.StreamRequestedRequest
+	15, // 17: confetti.syncer.v1.Syncer.StreamGranted:input_type -> confetti.syncer.v1.StreamGrantedRequest
+	3,  // 18: confetti.syncer.v1.Syncer.SaveRequested:output_type -> confetti.syncer.v1.SaveRequestedResponse
+	5,  // 19: confetti.syncer.v1.Syncer.SaveGranted:output_type -> confetti.syncer.v1.SaveGrantedResponse
+	7,  // 20: confetti.syncer.v1.Syncer.FindGranted:output_type -> confetti.syncer.v1.FindGrantedResponse
+	9,  // 21: confetti.syncer.v1.Syncer.FindRequested:output_type -> confetti.syncer.v1.FindRequestedResponse
+	11, // 22: confetti.syncer.v1.Syncer.DeleteRequested:output_type -> confetti.syncer.v1.DeleteRequestedResponse
+	13, // 23: confetti.syncer.v1.Syncer.DeleteGranted:output_type -> confetti.syncer.v1.DeleteGrantedResponse
+	0,  // 24: confetti.syncer.v1.Syncer.StreamRequested:output_type -> confetti.syncer.v1.Requested
+	1,  // 25: confetti.syncer.v1.Syncer.StreamGranted:output_type -> confetti.syncer.v1.Granted
+	18, // [18:26] is the sub-list for method output_type
+	10, // [10:18] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_syncer_proto_init() }
+func file_syncer_proto_init() {
+	if File_syncer_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_syncer_proto_rawDesc), len(file_syncer_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_syncer_proto_goTypes,
+		DependencyIndexes: file_syncer_proto_depIdxs,
+		MessageInfos:      file_syncer_proto_msgTypes,
+	}.Build()
+	File_syncer_proto = out.File
+	file_syncer_proto_goTypes = nil
+	file_syncer_proto_depIdxs = nil
+}
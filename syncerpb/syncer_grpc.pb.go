@@ -0,0 +1,402 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: syncer.proto
+
+package syncerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Syncer_SaveRequested_FullMethodName   = "/confetti.syncer.v1.Syncer/SaveRequested"
+	Syncer_SaveGranted_FullMethodName     = "/confetti.syncer.v1.Syncer/SaveGranted"
+	Syncer_FindGranted_FullMethodName     = "/confetti.syncer.v1.Syncer/FindGranted"
+	Syncer_FindRequested_FullMethodName   = "/confetti.syncer.v1.Syncer/FindRequested"
+	Syncer_DeleteRequested_FullMethodName = "/confetti.syncer.v1.Syncer/DeleteRequested"
+	Syncer_DeleteGranted_FullMethodName   = "/confetti.syncer.v1.Syncer/DeleteGranted"
+	Syncer_StreamRequested_FullMethodName = "/confetti.syncer.v1.Syncer/StreamRequested"
+	Syncer_StreamGranted_FullMethodName   = "/confetti.syncer.v1.Syncer/StreamGranted"
+)
+
+// SyncerClient is the client API for Syncer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Syncer exposes the permission store of syncer.DbManager
+type SyncerClient interface {
+	SaveRequested(ctx context.Context, in *SaveRequestedRequest, opts ...grpc.CallOption) (*SaveRequestedResponse, error)
+	SaveGranted(ctx context.Context, in *SaveGrantedRequest, opts ...grpc.CallOption) (*SaveGrantedResponse, error)
+	FindGranted(ctx context.Context, in *FindGrantedRequest, opts ...grpc.CallOption) (*FindGrantedResponse, error)
+	FindRequested(ctx context.Context, in *FindRequestedRequest, opts ...grpc.CallOption) (*FindRequestedResponse, error)
+	DeleteRequested(ctx context.Context, in *DeleteRequestedRequest, opts ...grpc.CallOption) (*DeleteRequestedResponse, error)
+	DeleteGranted(ctx context.Context, in *DeleteGrantedRequest, opts ...grpc.CallOption) (*DeleteGrantedResponse, error)
+	// StreamRequested sends every stored requested permission
+	StreamRequested(ctx context.Context, in *StreamRequestedRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Requested], error)
+	// StreamGranted sends every stored granted permission
+	StreamGranted(ctx context.Context, in *StreamGrantedRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Granted], error)
+}
+
+type syncerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSyncerClient(cc grpc.ClientConnInterface) SyncerClient {
+	return &syncerClient{cc}
+}
+
+func (c *syncerClient) SaveRequested(ctx context.Context, in *SaveRequestedRequest, opts ...grpc.CallOption) (*SaveRequestedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SaveRequestedResponse)
+	err := c.cc.Invoke(ctx, Syncer_SaveRequested_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *syncerClient) SaveGranted(ctx context.Context, in *SaveGrantedRequest, opts ...grpc.CallOption) (*SaveGrantedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SaveGrantedResponse)
+	err := c.cc.Invoke(ctx, Syncer_SaveGranted_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *syncerClient) FindGranted(ctx context.Context, in *FindGrantedRequest, opts ...grpc.CallOption) (*FindGrantedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FindGrantedResponse)
+	err := c.cc.Invoke(ctx, Syncer_FindGranted_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *syncerClient) FindRequested(ctx context.Context, in *FindRequestedRequest, opts ...grpc.CallOption) (*FindRequestedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FindRequestedResponse)
+	err := c.cc.Invoke(ctx, Syncer_FindRequested_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *syncerClient) DeleteRequested(ctx context.Context, in *DeleteRequestedRequest, opts ...grpc.CallOption) (*DeleteRequestedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteRequestedResponse)
+	err := c.cc.Invoke(ctx, Syncer_DeleteRequested_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *syncerClient) DeleteGranted(ctx context.Context, in *DeleteGrantedRequest, opts ...grpc.CallOption) (*DeleteGrantedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteGrantedResponse)
+	err := c.cc.Invoke(ctx, Syncer_DeleteGranted_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *syncerClient) StreamRequested(ctx context.Context, in *StreamRequestedRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Requested], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Syncer_ServiceDesc.Streams[0], Syncer_StreamRequested_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamRequestedRequest, Requested]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Syncer_StreamRequestedClient = grpc.ServerStreamingClient[Requested]
+
+func (c *syncerClient) StreamGranted(ctx context.Context, in *StreamGrantedRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Granted], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Syncer_ServiceDesc.Streams[1], Syncer_StreamGranted_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamGrantedRequest, Granted]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Syncer_StreamGrantedClient = grpc.ServerStreamingClient[Granted]
+
+// SyncerServer is the server API for Syncer service.
+// All implementations must embed UnimplementedSyncerServer
+// for forward compatibility.
+//
+// Syncer exposes the permission store of syncer.DbManager
+type SyncerServer interface {
+	SaveRequested(context.Context, *SaveRequestedRequest) (*SaveRequestedResponse, error)
+	SaveGranted(context.Context, *SaveGrantedRequest) (*SaveGrantedResponse, error)
+	FindGranted(context.Context, *FindGrantedRequest) (*FindGrantedResponse, error)
+	FindRequested(context.Context, *FindRequestedRequest) (*FindRequestedResponse, error)
+	DeleteRequested(context.Context, *DeleteRequestedRequest) (*DeleteRequestedResponse, error)
+	DeleteGranted(context.Context, *DeleteGrantedRequest) (*DeleteGrantedResponse, error)
+	// StreamRequested sends every stored requested permission
+	StreamRequested(*StreamRequestedRequest, grpc.ServerStreamingServer[Requested]) error
+	// StreamGranted sends every stored granted permission
+	StreamGranted(*StreamGrantedRequest, grpc.ServerStreamingServer[Granted]) error
+	mustEmbedUnimplementedSyncerServer()
+}
+
+// UnimplementedSyncerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSyncerServer struct{}
+
+func (UnimplementedSyncerServer) SaveRequested(context.Context, *SaveRequestedRequest) (*SaveRequestedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SaveRequested not implemented")
+}
+func (UnimplementedSyncerServer) SaveGranted(context.Context, *SaveGrantedRequest) (*SaveGrantedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SaveGranted not implemented")
+}
+func (UnimplementedSyncerServer) FindGranted(context.Context, *FindGrantedRequest) (*FindGrantedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindGranted not implemented")
+}
+func (UnimplementedSyncerServer) FindRequested(context.Context, *FindRequestedRequest) (*FindRequestedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindRequested not implemented")
+}
+func (UnimplementedSyncerServer) DeleteRequested(context.Context, *DeleteRequestedRequest) (*DeleteRequestedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRequested not implemented")
+}
+func (UnimplementedSyncerServer) DeleteGranted(context.Context, *DeleteGrantedRequest) (*DeleteGrantedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteGranted not implemented")
+}
+func (UnimplementedSyncerServer) StreamRequested(*StreamRequestedRequest, grpc.ServerStreamingServer[Requested]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamRequested not implemented")
+}
+func (UnimplementedSyncerServer) StreamGranted(*StreamGrantedRequest, grpc.ServerStreamingServer[Granted]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamGranted not implemented")
+}
+func (UnimplementedSyncerServer) mustEmbedUnimplementedSyncerServer() {}
+func (UnimplementedSyncerServer) testEmbeddedByValue()                {}
+
+// UnsafeSyncerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SyncerServer will
+// result in compilation errors.
+type UnsafeSyncerServer interface {
+	mustEmbedUnimplementedSyncerServer()
+}
+
+func RegisterSyncerServer(s grpc.ServiceRegistrar, srv SyncerServer) {
+	// If the following call pancis, it indicates UnimplementedSyncerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Syncer_ServiceDesc, srv)
+}
+
+func _Syncer_SaveRequested_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SaveRequestedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SyncerServer).SaveRequested(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Syncer_SaveRequested_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SyncerServer).SaveRequested(ctx, req.(*SaveRequestedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Syncer_SaveGranted_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SaveGrantedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SyncerServer).SaveGranted(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Syncer_SaveGranted_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SyncerServer).SaveGranted(ctx, req.(*SaveGrantedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Syncer_FindGranted_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindGrantedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SyncerServer).FindGranted(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Syncer_FindGranted_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SyncerServer).FindGranted(ctx, req.(*FindGrantedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Syncer_FindRequested_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindRequestedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SyncerServer).FindRequested(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Syncer_FindRequested_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SyncerServer).FindRequested(ctx, req.(*FindRequestedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Syncer_DeleteRequested_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequestedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SyncerServer).DeleteRequested(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Syncer_DeleteRequested_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SyncerServer).DeleteRequested(ctx, req.(*DeleteRequestedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Syncer_DeleteGranted_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteGrantedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SyncerServer).DeleteGranted(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Syncer_DeleteGranted_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SyncerServer).DeleteGranted(ctx, req.(*DeleteGrantedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Syncer_StreamRequested_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamRequestedRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SyncerServer).StreamRequested(m, &grpc.GenericServerStream[StreamRequestedRequest, Requested]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Syncer_StreamRequestedServer = grpc.ServerStreamingServer[Requested]
+
+func _Syncer_StreamGranted_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamGrantedRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SyncerServer).StreamGranted(m, &grpc.GenericServerStream[StreamGrantedRequest, Granted]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Syncer_StreamGrantedServer = grpc.ServerStreamingServer[Granted]
+
+// Syncer_ServiceDesc is the grpc.ServiceDesc for Syncer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Syncer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "confetti.syncer.v1.Syncer",
+	HandlerType: (*SyncerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SaveRequested",
+			Handler:    _Syncer_SaveRequested_Handler,
+		},
+		{
+			MethodName: "SaveGranted",
+			Handler:    _Syncer_SaveGranted_Handler,
+		},
+		{
+			MethodName: "FindGranted",
+			Handler:    _Syncer_FindGranted_Handler,
+		},
+		{
+			MethodName: "FindRequested",
+			Handler:    _Syncer_FindRequested_Handler,
+		},
+		{
+			MethodName: "DeleteRequested",
+			Handler:    _Syncer_DeleteRequested_Handler,
+		},
+		{
+			MethodName: "DeleteGranted",
+			Handler:    _Syncer_DeleteGranted_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamRequested",
+			Handler:       _Syncer_StreamRequested_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamGranted",
+			Handler:       _Syncer_StreamGranted_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "syncer.proto",
+}
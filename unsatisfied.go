@@ -0,0 +1,140 @@
+package syncer
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ClosestGrant is a stored grant that partially matches a requested permission
+type ClosestGrant struct {
+	Granted Granted `json:"granted"`
+	// Active is false when the grant is outside its time window
+	Active bool `json:"active"`
+	// Differences are the dimensions that did not match
+	Differences []DimensionMatch `json:"differences"`
+}
+
+// Unsatisfied is a stored requested permission that no active grant satisfies
+type Unsatisfied struct {
+	Requested Requested      `json:"requested"`
+	Closest   []ClosestGrant `json:"closest"`
+}
+
+// UnsatisfiedGroup holds the unsatisfied requested permissions of one umbrella repository
+type UnsatisfiedGroup struct {
+	UmbrellaOrganization string        `json:"umbrella_organization"`
+	UmbrellaRepository   string        `json:"umbrella_repository"`
+	Unsatisfied          []Unsatisfied `json:"unsatisfied"`
+}
+
+// UnsatisfiedRequests lists every stored requested permission for which
+// FindGranted returns nothing, grouped by umbrella organization and
+// repository. Each one comes with at most closest grants that differ in the
+// fewest dimensions; a grant that only differs in its time window has no
+// differences and is not active. A negative number of closest grants is an error.
+func (dm *DbManager) UnsatisfiedRequests(closest int) ([]UnsatisfiedGroup, error) {
+	if closest < 0 {
+		return nil, fmt.Errorf("failed to list unsatisfied requests: the number of closest grants cannot be negative, got %d", closest)
+	}
+	now := dm.now()
+
+	requested, err := dm.ListRequested()
+	if err != nil {
+		return nil, err
+	}
+	granted, err := dm.ListGranted()
+	if err != nil {
+		return nil, err
+	}
+	rules, err := loadMatchRules(dm.db)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []UnsatisfiedGroup{}
+	index := map[[2]string]int{}
+	for _, r := range requested {
+		matched, err := findGranted(dm.db, now, []Requested{r})
+		if err != nil {
+			return nil, err
+		}
+		if len(matched) > 0 {
+			continue
+		}
+
+		key := [2]string{r.UmbrellaOrganization, r.UmbrellaRepository}
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, UnsatisfiedGroup{UmbrellaOrganization: key[0], UmbrellaRepository: key[1]})
+		}
+		groups[i].Unsatisfied = append(groups[i].Unsatisfied, Unsatisfied{
+			Requested: r,
+			Closest:   closestGrants(r, granted, now, closest, rules),
+		})
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].UmbrellaOrganization != groups[j].UmbrellaOrganization {
+			return groups[i].UmbrellaOrganization < groups[j].UmbrellaOrganization
+		}
+		return groups[i].UmbrellaRepository < groups[j].UmbrellaRepository
+	})
+
+	return groups, nil
+}
+
+// closestGrants returns at most limit grants that match the requested
+// permission in at least one dimension, the fewest differences first and
+// active grants before inactive ones. The differences are the ones of the
+// closest pair of a lifted request and a lowered grant, so a grant on an
+// ancestor owner does not differ in the owner.
+func closestGrants(r Requested, granted []Granted, now time.Time, limit int, rules matchRules) []ClosestGrant {
+	candidates := []ClosestGrant{}
+	for _, g := range granted {
+		var closest []DimensionMatch
+		for _, v := range rules.owners.liftRequested(r) {
+			for _, lowered := range rules.owners.lowerGranted(g) {
+				differences, similar := explainDifferences(v.Requested, lowered, rules.actions)
+				if similar && (closest == nil || len(differences) < len(closest)) {
+					closest = differences
+				}
+			}
+		}
+		if closest == nil {
+			continue
+		}
+		candidates = append(candidates, ClosestGrant{Granted: g, Active: g.ActiveAt(now), Differences: closest})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if len(candidates[i].Differences) != len(candidates[j].Differences) {
+			return len(candidates[i].Differences) < len(candidates[j].Differences)
+		}
+		return candidates[i].Active && !candidates[j].Active
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	return candidates
+}
+
+// explainDifferences returns the dimensions in which the pair does not match
+// and whether it matches in any dimension other than the path
+func explainDifferences(r Requested, g Granted, actions actionLattice) ([]DimensionMatch, bool) {
+	differences := []DimensionMatch{}
+	similar := false
+	for _, match := range explain(r, g, actions) {
+		if !match.Matched {
+			differences = append(differences, match)
+		} else if match.Dimension != DimensionPath {
+			// Most permissions leave the path unrestricted, that makes no grant close
+			similar = true
+		}
+	}
+
+	return differences, similar
+}
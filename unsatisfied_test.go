@@ -0,0 +1,112 @@
+package syncer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnsatisfiedRequests(t *testing.T) {
+	// Given
+	is, dbManager, clock := setupTestDBWithClock(t)
+	pull := Granted{ContainerName: "image/container", Target: "cmd", GrandScheme: "image", GrandAction: "pull"}
+	expired := Granted{ContainerName: "cms/uploads", UmbrellaOrganization: "confetti-sites", UmbrellaRepository: "cms", GrandScheme: "hive", GrandAction: "*", ExpiresAt: clock.now.Add(-time.Hour)}
+	mockGranted(dbManager, pull)
+	mockGranted(dbManager, expired)
+	satisfied := Requested{ContainerName: "image/container", Target: "cmd", RequestScheme: "image", RequestAction: "pull"}
+	push := Requested{ContainerName: "image/container", Target: "cmd", RequestScheme: "image", RequestAction: "push"}
+	uploads := Requested{ContainerName: "cms/uploads", UmbrellaOrganization: "confetti-sites", UmbrellaRepository: "cms", RequestScheme: "hive", RequestAction: "pull"}
+	mockRequested(dbManager, satisfied)
+	mockRequested(dbManager, push)
+	mockRequested(dbManager, uploads)
+
+	// When
+	groups, err := dbManager.UnsatisfiedRequests(3)
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(groups), 2)
+
+	is.Equal(groups[0].UmbrellaOrganization, "")
+	is.Equal(len(groups[0].Unsatisfied), 1)
+	is.Equal(groups[0].Unsatisfied[0].Requested, push)
+	closest := groups[0].Unsatisfied[0].Closest
+	is.Equal(closest[0].Granted, pull) // Only the action differs
+	is.True(closest[0].Active)
+	is.Equal(len(closest[0].Differences), 1)
+	is.Equal(closest[0].Differences[0].Dimension, DimensionAction)
+	is.Equal(closest[0].Differences[0].Reason, ReasonValueMismatch)
+
+	is.Equal(groups[1].UmbrellaOrganization, "confetti-sites")
+	is.Equal(groups[1].UmbrellaRepository, "cms")
+	is.Equal(groups[1].Unsatisfied[0].Requested, uploads)
+	closest = groups[1].Unsatisfied[0].Closest
+	is.Equal(closest[0].Granted.GrandScheme, "hive") // Matches, but has expired
+	is.True(!closest[0].Active)
+	is.Equal(closest[0].Differences, []DimensionMatch{})
+}
+
+func TestUnsatisfiedRequests_closest_limit(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	for _, scheme := range []string{"hive", "video", "image"} {
+		mockGranted(dbManager, Granted{ContainerName: "image/container", GrandScheme: scheme, GrandAction: "pull"})
+	}
+	mockRequested(dbManager, Requested{ContainerName: "image/container", RequestScheme: "image", RequestAction: "push"})
+
+	// When
+	groups, err := dbManager.UnsatisfiedRequests(1)
+
+	// Then
+	is.NoErr(err)
+	closest := groups[0].Unsatisfied[0].Closest
+	is.Equal(len(closest), 1)
+	is.Equal(closest[0].Granted.GrandScheme, "image") // Differs in the action only
+}
+
+func TestUnsatisfiedRequests_closest_inherited_grant(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	is.NoErr(dbManager.SetParent(imageSource, confettiCms))
+	mockGranted(dbManager, ownedGrant(confettiCms))
+	push := ownedRequest()
+	push.RequestAction = "push"
+	mockRequested(dbManager, push)
+
+	// When
+	groups, err := dbManager.UnsatisfiedRequests(1)
+
+	// Then
+	is.NoErr(err)
+	closest := groups[0].Unsatisfied[0].Closest
+	is.Equal(closest[0].Granted.SourceRepository, "cms")
+	is.Equal(len(closest[0].Differences), 1) // The owner is inherited, only the action differs
+	is.Equal(closest[0].Differences[0].Dimension, DimensionAction)
+}
+
+func TestUnsatisfiedRequests_negative_closest(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	mockGranted(dbManager, Granted{ContainerName: "image/container", GrandScheme: "image", GrandAction: "pull"})
+	mockRequested(dbManager, Requested{ContainerName: "image/container", RequestScheme: "image", RequestAction: "push"})
+
+	// When
+	groups, err := dbManager.UnsatisfiedRequests(-1)
+
+	// Then
+	is.True(err != nil)
+	is.Equal(len(groups), 0)
+}
+
+func TestUnsatisfiedRequests_all_satisfied(t *testing.T) {
+	// Given
+	is, dbManager := setupTestDB(t)
+	mockGranted(dbManager, Granted{ContainerName: "image/container", GrandScheme: "*", GrandAction: "*"})
+	mockRequested(dbManager, Requested{ContainerName: "image/container", RequestScheme: "image", RequestAction: "pull"})
+
+	// When
+	groups, err := dbManager.UnsatisfiedRequests(3)
+
+	// Then
+	is.NoErr(err)
+	is.Equal(groups, []UnsatisfiedGroup{})
+}
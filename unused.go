@@ -0,0 +1,105 @@
+package syncer
+
+import (
+	"time"
+)
+
+// UnusedGrant is a grant that is a candidate for cleanup, with the evidence
+// that it is not needed
+type UnusedGrant struct {
+	Granted Granted `json:"granted"`
+	// Active is false for a grant that has expired
+	Active bool `json:"active"`
+	// CheckedAt is the time no stored requested permission matched the grant
+	CheckedAt time.Time `json:"checked_at"`
+	// DecisionsSince is the start of the window in which no decision matched
+	// the grant and DecisionsChecked the number of decisions in that window.
+	// Both are zero when no decisions were checked.
+	DecisionsSince   time.Time `json:"decisions_since,omitzero"`
+	DecisionsChecked int       `json:"decisions_checked"`
+	// LastMatched is the time of the last checked decision that matched the
+	// grant, before the window. It is zero when no decision matched it.
+	LastMatched time.Time `json:"last_matched,omitzero"`
+}
+
+// UnusedOption configures UnusedGrants
+type UnusedOption func(*unusedOptions)
+
+type unusedOptions struct {
+	checkDecisions bool
+	window         time.Duration
+	decisions      []Decision
+}
+
+// NoDecisionWithin only reports grants that matched none of the decisions
+// made within the window before now, e.g. the decisions read back from a
+// JSON lines decision log with ReadDecisions
+func NoDecisionWithin(window time.Duration, decisions []Decision) UnusedOption {
+	return func(o *unusedOptions) {
+		o.checkDecisions = true
+		o.window = window
+		o.decisions = decisions
+	}
+}
+
+// UnusedGrants finds the stored grants that match no stored requested
+// permission with FindRequested. Expired grants match nothing and are
+// reported as well, grants that are not active yet are left out.
+func (dm *DbManager) UnusedGrants(opts ...UnusedOption) ([]UnusedGrant, error) {
+	var o unusedOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	now := dm.now()
+
+	granted, err := dm.ListGranted()
+	if err != nil {
+		return nil, err
+	}
+
+	var since time.Time
+	checked := 0
+	lastMatched := map[string]time.Time{}
+	if o.checkDecisions {
+		since = now.Add(-o.window)
+		for _, d := range o.decisions {
+			if !d.Time.Before(since) {
+				checked++
+			}
+			for _, locator := range d.Matched {
+				if d.Time.After(lastMatched[locator]) {
+					lastMatched[locator] = d.Time
+				}
+			}
+		}
+	}
+
+	unused := []UnusedGrant{}
+	for _, g := range granted {
+		if !g.NotBefore.IsZero() && now.Before(g.NotBefore) {
+			continue
+		}
+		requested, err := findRequested(dm.db, now, []Granted{g})
+		if err != nil {
+			return nil, err
+		}
+		if len(requested) > 0 {
+			continue
+		}
+		last := lastMatched[grantedLocator(g)]
+		if o.checkDecisions && !last.Before(since) {
+			continue
+		}
+
+		unused = append(unused, UnusedGrant{
+			Granted:          g,
+			Active:           g.ActiveAt(now),
+			CheckedAt:        now,
+			DecisionsSince:   since,
+			DecisionsChecked: checked,
+			LastMatched:      last,
+		})
+	}
+
+	return unused, nil
+}
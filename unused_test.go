@@ -0,0 +1,69 @@
+package syncer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestUnusedGrants(t *testing.T) {
+	// Given
+	is, dbManager, clock := setupTestDBWithClock(t)
+	used := Granted{ContainerName: "image/container", GrandScheme: "image", GrandAction: "*"}
+	unused := Granted{ContainerName: "image/container", GrandScheme: "video", GrandAction: "*"}
+	expired := Granted{ContainerName: "image/container", GrandScheme: "image", GrandAction: "pull", ExpiresAt: clock.now.Add(-time.Hour)}
+	scheduled := Granted{ContainerName: "image/container", GrandScheme: "hive", GrandAction: "*", NotBefore: clock.now.Add(time.Hour)}
+	for _, g := range []Granted{used, unused, expired, scheduled} {
+		mockGranted(dbManager, g)
+	}
+	mockRequested(dbManager, Requested{ContainerName: "image/container", RequestScheme: "image", RequestAction: "pull"})
+
+	// When
+	candidates, err := dbManager.UnusedGrants()
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(candidates), 2) // The scheduled grant is left out
+	is.Equal(candidates[0].Granted.GrandScheme, "image")
+	is.True(!candidates[0].Active) // Expired
+	is.Equal(candidates[1].Granted.GrandScheme, "video")
+	is.True(candidates[1].Active)
+	is.Equal(candidates[1].CheckedAt, clock.now)
+	is.True(candidates[1].DecisionsSince.IsZero())
+}
+
+func TestUnusedGrants_no_decision_within(t *testing.T) {
+	// Given
+	is := is.New(t)
+	clock := &fakeClock{now: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}
+	recorder := &recordingLogger{}
+	dbManager, err := NewDbManager(WithClock(clock.Now), WithDecisionLogger(recorder))
+	is.NoErr(err)
+	t.Cleanup(func() { dbManager.Close() })
+
+	recent := Granted{ContainerName: "image/container", GrandScheme: "image", GrandAction: "*"}
+	old := Granted{ContainerName: "image/container", GrandScheme: "video", GrandAction: "*"}
+	never := Granted{ContainerName: "image/container", GrandScheme: "hive", GrandAction: "*"}
+	for _, g := range []Granted{recent, old, never} {
+		mockGranted(dbManager, g)
+	}
+	_, err = dbManager.FindGranted([]Requested{{ContainerName: "image/container", RequestScheme: "video", RequestAction: "pull"}})
+	is.NoErr(err)
+	clock.Advance(48 * time.Hour)
+	_, err = dbManager.FindGranted([]Requested{{ContainerName: "image/container", RequestScheme: "image", RequestAction: "pull"}})
+	is.NoErr(err)
+
+	// When
+	candidates, err := dbManager.UnusedGrants(NoDecisionWithin(24*time.Hour, recorder.decisions))
+
+	// Then
+	is.NoErr(err)
+	is.Equal(len(candidates), 2) // The recently used grant is left out
+	is.Equal(candidates[0].Granted.GrandScheme, "hive")
+	is.True(candidates[0].LastMatched.IsZero())
+	is.Equal(candidates[0].DecisionsSince, clock.now.Add(-24*time.Hour))
+	is.Equal(candidates[0].DecisionsChecked, 1)
+	is.Equal(candidates[1].Granted.GrandScheme, "video")
+	is.Equal(candidates[1].LastMatched, clock.now.Add(-48*time.Hour))
+}